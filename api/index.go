@@ -3,28 +3,46 @@ package handler
 import (
 	"context"
 	"net/http"
+	"strings"
 	"sync"
+	"time"
 
-	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 
+	"sct-backend-service/app/options/attachment"
 	"sct-backend-service/app/options/config"
 	"sct-backend-service/app/options/data"
+	"sct-backend-service/app/options/notify"
+	"sct-backend-service/app/options/ratelimit"
 	"sct-backend-service/app/options/service"
+	"sct-backend-service/app/options/slack"
+	"sct-backend-service/app/options/spam"
+	"sct-backend-service/app/outbox"
+	appratelimit "sct-backend-service/app/ratelimit"
 	"sct-backend-service/app/workflow"
 	"sct-backend-service/graph"
 	"sct-backend-service/graph/generated"
+	"sct-backend-service/internal/directives"
+	"sct-backend-service/internal/middleware"
+	"sct-backend-service/internal/server"
 )
 
 var (
-	graphqlHandler    *handler.Server
+	graphqlHandler    http.Handler
 	playgroundHandler http.Handler
+	slackHandlers     *slack.Handlers
+	attachments       *attachment.Attachments
 	initOnce          sync.Once
 	appInstance       *fx.App
+	outboxWorker      *outbox.Worker
+	handlerLogger     *zap.Logger
 )
 
+// outboxDrainTimeout bounds the notification delivery done at the end of an invocation
+const outboxDrainTimeout = 5 * time.Second
+
 func initializeHandlers() {
 	initOnce.Do(func() {
 		// Create resolver
@@ -33,18 +51,29 @@ func initializeHandlers() {
 		// Use fx to extract workflow service
 		var workflowService workflow.WorkflowGraphQLService
 		var logger *zap.Logger
+		var limiter *appratelimit.Limiter
 
 		// Create fx container with all dependencies (excluding HTTP server for serverless)
 		appInstance = fx.New(
 			config.ConfigFxOption(""),
 			config.LoggerFxOption(),
 			data.QueryFxOption(),
+			data.RepositoryFxOption(),
+			attachment.AttachmentFxOption(),
+			notify.NotifierFxOption(),
+			spam.SpamFxOption(),
+			ratelimit.RateLimitFxOption(),
 			service.ControllerFxOption(),
 			service.WorkflowFxOption(),
+			slack.SlackFxOption(),
 			// Note: We don't include http.HttpFxOption() for serverless
-			fx.Invoke(func(w workflow.WorkflowGraphQLService, l *zap.Logger) {
+			fx.Invoke(func(w workflow.WorkflowGraphQLService, l *zap.Logger, o *outbox.Worker, rl *appratelimit.Limiter, sh *slack.Handlers, a *attachment.Attachments) {
 				workflowService = w
 				logger = l
+				outboxWorker = o
+				limiter = rl
+				slackHandlers = sh
+				attachments = a
 			}),
 		)
 
@@ -65,22 +94,52 @@ func initializeHandlers() {
 		config := generated.Config{
 			Resolvers: resolver,
 		}
-		config.Directives = generated.DirectiveRoot{}
+		// The limiter is nil when fx failed to start, which leaves the calls unlimited
+		config.Directives = directives.NewDirectiveRoot(directives.Deps{Limiter: limiter})
 
 		executableSchema := generated.NewExecutableSchema(config)
-		graphqlHandler = handler.NewDefaultServer(executableSchema)
+		// Attachments are nil when fx failed to start, which keeps the default upload size
+		var maxUploadSize int64
+		if attachments != nil {
+			maxUploadSize = attachments.MaxUploadSize
+		}
+		graphqlHandler = middleware.RequestMetadataMiddleware(middleware.AuthMiddleware(server.NewGraphQLHandler(executableSchema, maxUploadSize)))
 		playgroundHandler = playground.Handler("GraphQL Playground", "/api/graphql")
 
-		_ = logger
+		handlerLogger = logger
 	})
 }
 
+// drainOutbox delivers due notifications before the invocation ends.
+// Serverless functions cannot run the background worker, so every invocation does a bit of its work.
+func drainOutbox(ctx context.Context) {
+	if outboxWorker == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), outboxDrainTimeout)
+	defer cancel()
+	if _, err := outboxWorker.RunOnce(ctx); err != nil && handlerLogger != nil {
+		handlerLogger.Error("Error draining outbox", zap.Error(err))
+	}
+}
+
 // Handler is the Vercel serverless function entry point
 func Handler(w http.ResponseWriter, r *http.Request) {
 	initializeHandlers()
 
 	path := r.URL.Path
 
+	// Download links carry the enquiry and attachment IDs in the path
+	if strings.HasPrefix(path, "/api/attachments/") {
+		if attachments != nil && attachments.Downloads != nil {
+			attachments.Downloads.ServeHTTP(w, r)
+		} else {
+			http.NotFound(w, r)
+		}
+		return
+	}
+
 	switch path {
 	case "/api/playground", "/api/":
 		if playgroundHandler != nil {
@@ -88,9 +147,29 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		} else {
 			http.Error(w, "Playground not available", http.StatusNotFound)
 		}
+	case "/api/slack/interactions":
+		if slackHandlers != nil && slackHandlers.Interactions != nil {
+			slackHandlers.Interactions.ServeHTTP(w, r)
+			// Slack waits at most 3 seconds for the acknowledgement, send it before applying the action.
+			// The function may be frozen once it returns, so it waits for the action and its follow-ups.
+			if flusher, ok := w.(http.Flusher); ok {
+				flusher.Flush()
+			}
+			slackHandlers.Wait()
+			drainOutbox(r.Context())
+		} else {
+			http.Error(w, "Slack interactions are not configured", http.StatusNotFound)
+		}
+	case "/api/slack/commands":
+		if slackHandlers != nil && slackHandlers.Commands != nil {
+			slackHandlers.Commands.ServeHTTP(w, r)
+		} else {
+			http.Error(w, "Slack commands are not configured", http.StatusNotFound)
+		}
 	case "/api/graphql", "/api/query":
 		if graphqlHandler != nil {
 			graphqlHandler.ServeHTTP(w, r)
+			drainOutbox(r.Context())
 		} else {
 			http.Error(w, "GraphQL handler not initialized", http.StatusInternalServerError)
 		}
@@ -98,6 +177,7 @@ func Handler(w http.ResponseWriter, r *http.Request) {
 		// Default to GraphQL endpoint
 		if graphqlHandler != nil {
 			graphqlHandler.ServeHTTP(w, r)
+			drainOutbox(r.Context())
 		} else {
 			http.Error(w, "GraphQL handler not initialized", http.StatusInternalServerError)
 		}
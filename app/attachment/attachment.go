@@ -0,0 +1,226 @@
+package attachment
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"sct-backend-service/app/blob"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/app/utils"
+	"sct-backend-service/app/validation"
+)
+
+// DefaultAllowedTypes are the accepted file types when none are configured: PDF, images, CAD drawings,
+// Office documents and plain text
+var DefaultAllowedTypes = []string{
+	"application/pdf",
+	"image/png",
+	"image/jpeg",
+	"image/webp",
+	"image/vnd.dwg",
+	"image/vnd.dxf",
+	"application/msword",
+	"application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	"application/vnd.ms-excel",
+	"application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	"text/plain",
+	"text/csv",
+}
+
+// extensionTypes fill in the type of files browsers send as application/octet-stream,
+// the MIME table of the host often does not know drawings and Office documents
+var extensionTypes = map[string]string{
+	".pdf":  "application/pdf",
+	".png":  "image/png",
+	".jpg":  "image/jpeg",
+	".jpeg": "image/jpeg",
+	".webp": "image/webp",
+	".dwg":  "image/vnd.dwg",
+	".dxf":  "image/vnd.dxf",
+	".doc":  "application/msword",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xls":  "application/vnd.ms-excel",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".txt":  "text/plain",
+	".csv":  "text/csv",
+}
+
+// sniffedTypes are recognized reliably by http.DetectContentType, a file declaring one of them
+// must contain it and a file containing one must declare it
+var sniffedTypes = map[string]bool{
+	"application/pdf": true,
+	"image/png":       true,
+	"image/jpeg":      true,
+	"image/gif":       true,
+	"image/webp":      true,
+	"text/html":       true,
+	"text/xml":        true,
+}
+
+// maxFileNameLength bounds the stored file name in characters
+const maxFileNameLength = 255
+
+// Limits bound the files one contact may attach
+type Limits struct {
+	// MaxFiles is the number of files per contact, zero turns attachments off
+	MaxFiles int
+	// MaxFileSize is the largest accepted file in bytes
+	MaxFileSize int64
+	// MaxTotalSize bounds the files of all contacts of one request together, in bytes
+	MaxTotalSize int64
+	// AllowedTypes lists the accepted MIME types, a type/* entry accepts every subtype
+	AllowedTypes []string
+}
+
+// CheckCount reports a contact with more files than allowed
+func (l Limits) CheckCount(count int) *validation.Violation {
+	switch {
+	case count <= l.MaxFiles:
+		return nil
+	case l.MaxFiles == 0:
+		return &validation.Violation{Code: keys.ErrCodeTooManyAttachments, Message: "are not accepted"}
+	default:
+		return &validation.Violation{Code: keys.ErrCodeTooManyAttachments, Message: "may contain at most %d files", Args: []interface{}{l.MaxFiles}}
+	}
+}
+
+// CheckTotal reports a request whose files together are larger than allowed
+func (l Limits) CheckTotal(size int64) *validation.Violation {
+	if size > l.MaxTotalSize {
+		return &validation.Violation{Code: keys.ErrCodeAttachmentTooLarge, Message: "may carry at most %s of files in total", Args: []interface{}{FormatSize(l.MaxTotalSize)}}
+	}
+	return nil
+}
+
+// CheckFile reports a file that is too large or of a type that is not accepted
+func (l Limits) CheckFile(upload *graphql.Upload) *validation.Violation {
+	if upload.Size > l.MaxFileSize {
+		return &validation.Violation{Code: keys.ErrCodeAttachmentTooLarge, Message: "must be at most %s", Args: []interface{}{FormatSize(l.MaxFileSize)}}
+	}
+	contentType, err := DetectType(upload)
+	if err != nil || !l.allows(contentType) {
+		return &validation.Violation{Code: keys.ErrCodeUnsupportedAttachment, Message: "is not an accepted file type"}
+	}
+	return nil
+}
+
+// allows reports whether the content type is one of AllowedTypes
+func (l Limits) allows(contentType string) bool {
+	for _, allowed := range l.AllowedTypes {
+		if allowed == contentType {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(allowed, "/*"); ok && strings.HasPrefix(contentType, prefix+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+// DetectType returns the MIME type of the upload: the declared type, or the type of its file extension
+// when the browser sent none or application/octet-stream. The first bytes must agree with it, so a page
+// of HTML cannot pass as a drawing. The file is read from the start again afterwards.
+func DetectType(upload *graphql.Upload) (string, error) {
+	contentType := ""
+	if mediaType, _, err := mime.ParseMediaType(upload.ContentType); err == nil {
+		contentType = strings.ToLower(mediaType)
+	}
+	if contentType == "" || contentType == "application/octet-stream" {
+		contentType = extensionTypes[strings.ToLower(path.Ext(upload.Filename))]
+	}
+	if contentType == "" {
+		return "", fmt.Errorf("unknown file type of %q", upload.Filename)
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(upload.File, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return "", fmt.Errorf("error reading upload: %w", err)
+	}
+	if _, err := upload.File.Seek(0, io.SeekStart); err != nil {
+		return "", fmt.Errorf("error rewinding upload: %w", err)
+	}
+
+	sniffed, _, _ := mime.ParseMediaType(http.DetectContentType(head[:n]))
+	if (sniffedTypes[contentType] || sniffedTypes[sniffed]) && sniffed != contentType {
+		return "", fmt.Errorf("content of %q is %s, not %s", upload.Filename, sniffed, contentType)
+	}
+	return contentType, nil
+}
+
+// Save stores the upload under a new key below the enquiry and returns its description
+func Save(ctx context.Context, store blob.Store, enquiryID string, upload *graphql.Upload, at time.Time) (entities.EnquiryAttachment, error) {
+	contentType, err := DetectType(upload)
+	if err != nil {
+		return entities.EnquiryAttachment{}, err
+	}
+
+	id := utils.GenerateID()
+	attachment := entities.EnquiryAttachment{
+		ID:          id,
+		FileName:    FileName(upload.Filename),
+		ContentType: contentType,
+		Size:        upload.Size,
+		Key:         "enquiries/" + enquiryID + "/" + id,
+		CreatedAt:   at,
+	}
+	if err := store.Put(ctx, attachment.Key, contentType, upload.File, upload.Size); err != nil {
+		return entities.EnquiryAttachment{}, fmt.Errorf("error storing attachment: %w", err)
+	}
+	return attachment, nil
+}
+
+// FileName cleans the name the browser sent: directories and control characters are dropped
+// and long names are shortened, keeping the extension
+func FileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, `\`, "/"))
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) || r == utf8.RuneError {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == "/" {
+		return "attachment"
+	}
+
+	if utf8.RuneCountInString(name) > maxFileNameLength {
+		ext := path.Ext(name)
+		if utf8.RuneCountInString(ext) > 16 {
+			ext = ""
+		}
+		name = string([]rune(strings.TrimSuffix(name, ext))[:maxFileNameLength-utf8.RuneCountInString(ext)]) + ext
+	}
+	return name
+}
+
+// FormatSize writes a byte count for people, e.g. 850 KB or 1.2 MB
+func FormatSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	value, suffix := float64(size)/unit, "KB"
+	for _, next := range []string{"MB", "GB"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	if value >= 10 || value == float64(int64(value)) {
+		return fmt.Sprintf("%.0f %s", value, suffix)
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
@@ -0,0 +1,128 @@
+package attachment
+
+import (
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/blob"
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/internal/middleware"
+)
+
+// Handler serves signed download links, GET <public URL>/attachments/<enquiry ID>/<attachment ID>?expires=…&signature=…
+type Handler struct {
+	enquiries data.EnquiryRepository
+	store     blob.Store
+	links     *Links
+	logger    *zap.Logger
+}
+
+// NewHandler creates the download endpoint
+func NewHandler(enquiries data.EnquiryRepository, store blob.Store, links *Links, logger *zap.Logger) *Handler {
+	return &Handler{
+		enquiries: enquiries,
+		store:     store,
+		links:     links,
+		logger:    logger,
+	}
+}
+
+// ServeHTTP streams the file as a download. Only the signature is checked, the link itself is the permission.
+// An expired link opened with the admin token redirects to a freshly signed link of the same file.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	// The handler may be mounted below a prefix such as /api on Vercel
+	index := strings.LastIndex(r.URL.Path, DownloadPath)
+	if index < 0 {
+		http.NotFound(w, r)
+		return
+	}
+	enquiryID, attachmentID, ok := strings.Cut(r.URL.Path[index+len(DownloadPath):], "/")
+	if !ok || enquiryID == "" || attachmentID == "" || strings.Contains(attachmentID, "/") {
+		http.NotFound(w, r)
+		return
+	}
+
+	query := r.URL.Query()
+	err := h.links.Verify(enquiryID, attachmentID, query.Get("expires"), query.Get("signature"))
+	expired := errors.Is(err, ErrLinkExpired)
+	switch {
+	case expired && middleware.RequireAuth(r.Context()) == nil:
+		// The admin may still download the file, through a new link
+	case expired:
+		http.Error(w, "this download link has expired", http.StatusGone)
+		return
+	case err != nil:
+		http.Error(w, "invalid download link", http.StatusForbidden)
+		return
+	}
+
+	enquiry, err := h.enquiries.GetByID(r.Context(), enquiryID)
+	if errors.Is(err, data.ErrEnquiryNotFound) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Error loading enquiry for download", zap.String("enquiry_id", enquiryID), zap.Error(err))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	attachment, ok := findAttachment(enquiry, attachmentID)
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	if expired {
+		http.Redirect(w, r, h.links.URL(enquiry.ID, attachment), http.StatusTemporaryRedirect)
+		return
+	}
+
+	content, err := h.store.Open(r.Context(), attachment.Key)
+	if errors.Is(err, blob.ErrNotFound) {
+		h.logger.Warn("Attachment content is missing", zap.String("enquiry_id", enquiryID), zap.String("attachment_id", attachmentID))
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		h.logger.Error("Error opening attachment", zap.String("enquiry_id", enquiryID), zap.String("attachment_id", attachmentID), zap.Error(err))
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	defer content.Close()
+
+	// The file is always downloaded, never rendered by the browser on this origin
+	w.Header().Set("Content-Type", attachment.ContentType)
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("Cache-Control", "private, no-store")
+	if r.Method == http.MethodHead {
+		return
+	}
+	if _, err := io.Copy(w, content); err != nil {
+		h.logger.Warn("Error sending attachment", zap.String("attachment_id", attachmentID), zap.Error(err))
+	}
+}
+
+// findAttachment returns the attachment of the enquiry with the ID
+func findAttachment(enquiry *entities.Enquiry, id string) (entities.EnquiryAttachment, bool) {
+	for _, attachment := range enquiry.Attachments {
+		if attachment.ID == id {
+			return attachment, true
+		}
+	}
+	return entities.EnquiryAttachment{}, false
+}
@@ -0,0 +1,104 @@
+package attachment
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/blob"
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/internal/middleware"
+)
+
+// newTestHandler serves one stored attachment of one enquiry, links are signed at signedAt
+func newTestHandler(t *testing.T, signedAt time.Time) (*Handler, *Links, string) {
+	t.Helper()
+	store, err := blob.NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskStore: %v", err)
+	}
+	content := "drawing"
+	if err := store.Put(context.Background(), "enquiries/enquiry-1/file-1", "application/pdf", strings.NewReader(content), int64(len(content))); err != nil {
+		t.Fatalf("Put: %v", err)
+	}
+	enquiries := data.NewMemoryEnquiryRepository(data.NewMemoryOutboxRepository())
+	enquiry := &entities.Enquiry{
+		ID:     "enquiry-1",
+		Status: entities.EnquiryStatusNew,
+		Attachments: []entities.EnquiryAttachment{
+			{ID: "file-1", FileName: "drawing.pdf", ContentType: "application/pdf", Size: int64(len(content)), Key: "enquiries/enquiry-1/file-1"},
+		},
+	}
+	if err := enquiries.Create(context.Background(), enquiry); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	links := NewLinks("https://api.example.com", "secret", time.Hour)
+	links.now = func() time.Time { return signedAt }
+	link := links.URL(enquiry.ID, enquiry.Attachments[0])
+	return NewHandler(enquiries, store, links, zap.NewNop()), links, link
+}
+
+func TestHandlerExpiredLinks(t *testing.T) {
+	signedAt := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		age      time.Duration
+		admin    bool
+		tamper   bool
+		wantCode int
+	}{
+		{"valid link", 30 * time.Minute, false, false, http.StatusOK},
+		{"expired link", 2 * time.Hour, false, false, http.StatusGone},
+		{"expired link opened by an admin", 2 * time.Hour, true, false, http.StatusTemporaryRedirect},
+		{"valid link opened by an admin", 30 * time.Minute, true, false, http.StatusOK},
+		{"forged link opened by an admin", 2 * time.Hour, true, true, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, links, link := newTestHandler(t, signedAt)
+			now := signedAt.Add(tt.age)
+			links.now = func() time.Time { return now }
+			if tt.tamper {
+				link = strings.Replace(link, "signature=", "signature=0", 1)
+			}
+
+			req := httptest.NewRequest(http.MethodGet, link, nil)
+			if tt.admin {
+				req = req.WithContext(middleware.WithUserID(req.Context(), keys.AdminUserID))
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, req)
+
+			if rec.Code != tt.wantCode {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantCode, rec.Body.String())
+			}
+			switch rec.Code {
+			case http.StatusOK:
+				if rec.Body.String() != "drawing" {
+					t.Errorf("body = %q, want the file", rec.Body.String())
+				}
+			case http.StatusTemporaryRedirect:
+				location, err := url.Parse(rec.Header().Get("Location"))
+				if err != nil {
+					t.Fatalf("Location: %v", err)
+				}
+				if !strings.HasSuffix(location.Path, "/attachments/enquiry-1/file-1") {
+					t.Errorf("redirected to %s, want the same file", location.Path)
+				}
+				query := location.Query()
+				if err := links.Verify("enquiry-1", "file-1", query.Get("expires"), query.Get("signature")); err != nil {
+					t.Errorf("redirect link does not verify: %v", err)
+				}
+			}
+		})
+	}
+}
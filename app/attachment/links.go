@@ -0,0 +1,81 @@
+package attachment
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"sct-backend-service/app/entities"
+)
+
+// DownloadPath is where the download handler is served below the public URL
+const DownloadPath = "/attachments/"
+
+var (
+	// ErrInvalidLink is returned for links that were not signed by this service
+	ErrInvalidLink = errors.New("invalid download link")
+	// ErrLinkExpired is returned for correctly signed links past their expiry
+	ErrLinkExpired = errors.New("download link expired")
+)
+
+// Links signs download links of attachments, so files can be linked from Slack and email
+// without an admin token. A link names the enquiry and the attachment and works until it expires.
+type Links struct {
+	publicURL string
+	secret    []byte
+	expiry    time.Duration
+	now       func() time.Time
+}
+
+// NewLinks creates the signer. publicURL is where this service is reachable, e.g. https://api.example.com,
+// or https://example.vercel.app/api on Vercel.
+func NewLinks(publicURL, secret string, expiry time.Duration) *Links {
+	return &Links{
+		publicURL: strings.TrimSuffix(publicURL, "/"),
+		secret:    []byte(secret),
+		expiry:    expiry,
+		now:       time.Now,
+	}
+}
+
+// URL returns a signed download link of the attachment, or an empty string when l is nil
+// because downloads are not configured
+func (l *Links) URL(enquiryID string, attachment entities.EnquiryAttachment) string {
+	if l == nil {
+		return ""
+	}
+	expires := strconv.FormatInt(l.now().Add(l.expiry).Unix(), 10)
+	query := url.Values{
+		"expires":   {expires},
+		"signature": {l.sign(enquiryID, attachment.ID, expires)},
+	}
+	return l.publicURL + DownloadPath + url.PathEscape(enquiryID) + "/" + url.PathEscape(attachment.ID) + "?" + query.Encode()
+}
+
+// Verify checks the signature and expiry of a link's parameters
+func (l *Links) Verify(enquiryID, attachmentID, expires, signature string) error {
+	expected := l.sign(enquiryID, attachmentID, expires)
+	if !hmac.Equal([]byte(signature), []byte(expected)) {
+		return ErrInvalidLink
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil {
+		return ErrInvalidLink
+	}
+	if l.now().After(time.Unix(unix, 0)) {
+		return ErrLinkExpired
+	}
+	return nil
+}
+
+// sign computes the HMAC-SHA256 of the link's parts
+func (l *Links) sign(enquiryID, attachmentID, expires string) string {
+	mac := hmac.New(sha256.New, l.secret)
+	mac.Write([]byte(enquiryID + "\n" + attachmentID + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,35 @@
+package blob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// ErrNotFound is returned when no object is stored under a key
+var ErrNotFound = errors.New("blob not found")
+
+// Store keeps file contents by key. Keys are slash separated paths such as enquiries/<id>/<id>.
+type Store interface {
+	// Put stores size bytes read from body under key, replacing an existing object
+	Put(ctx context.Context, key, contentType string, body io.Reader, size int64) error
+	// Open returns the content stored under key or ErrNotFound, the caller closes it
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the object, deleting a missing key is not an error
+	Delete(ctx context.Context, key string) error
+}
+
+// validateKey rejects keys that could escape the store, such as ../ segments
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") {
+		return fmt.Errorf("invalid blob key %q", key)
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return fmt.Errorf("invalid blob key %q", key)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,50 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrMissingToken is returned when a source requires a captcha and no token was sent
+	ErrMissingToken = errors.New("captcha token is required")
+	// ErrRejected is returned when the provider did not accept the token
+	ErrRejected = errors.New("captcha verification failed")
+	// ErrUnavailable is returned when the provider could not be asked
+	ErrUnavailable = errors.New("captcha verification is unavailable")
+)
+
+// Verifier checks a captcha token with its provider
+type Verifier interface {
+	// Name identifies the provider in logs
+	Name() string
+	// Verify returns nil when the token is valid, ErrRejected when it is not
+	// and ErrUnavailable when the provider could not be reached
+	Verify(ctx context.Context, token, remoteIP string) error
+}
+
+// Registry holds the verifier of every source that requires a captcha
+type Registry struct {
+	verifiers map[string]Verifier
+}
+
+// NewRegistry creates a registry from verifiers keyed by source
+func NewRegistry(verifiers map[string]Verifier) *Registry {
+	return &Registry{verifiers: verifiers}
+}
+
+// Verify checks the token for the source; sources without a verifier accept every request
+func (r *Registry) Verify(ctx context.Context, source, token, remoteIP string) error {
+	verifier, ok := r.verifiers[source]
+	if !ok {
+		return nil
+	}
+	if token == "" {
+		return ErrMissingToken
+	}
+	if err := verifier.Verify(ctx, token, remoteIP); err != nil {
+		return fmt.Errorf("%s: %w", verifier.Name(), err)
+	}
+	return nil
+}
@@ -0,0 +1,31 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestRegistryVerify(t *testing.T) {
+	registry := NewRegistry(map[string]Verifier{"SCTGULF": NewFakeVerifier("pass")})
+	tests := []struct {
+		name    string
+		source  string
+		token   string
+		wantErr error
+	}{
+		{"valid token", "SCTGULF", "pass", nil},
+		{"wrong token", "SCTGULF", "guess", ErrRejected},
+		{"token is case sensitive", "SCTGULF", "PASS", ErrRejected},
+		{"missing token", "SCTGULF", "", ErrMissingToken},
+		{"source without captcha", "SCTINDIA", "", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := registry.Verify(context.Background(), tt.source, tt.token, "203.0.113.7")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
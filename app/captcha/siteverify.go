@@ -0,0 +1,119 @@
+package captcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Default verification endpoints of the supported providers
+const (
+	TurnstileVerifyURL = "https://challenges.cloudflare.com/turnstile/v0/siteverify"
+	HCaptchaVerifyURL  = "https://api.hcaptcha.com/siteverify"
+	ReCaptchaVerifyURL = "https://www.google.com/recaptcha/api/siteverify"
+)
+
+// siteVerifyResponse is the response shared by Turnstile, hCaptcha and reCAPTCHA
+type siteVerifyResponse struct {
+	Success    bool     `json:"success"`
+	ErrorCodes []string `json:"error-codes"`
+	Hostname   string   `json:"hostname"`
+	// Score and Action are only sent by reCAPTCHA v3
+	Score  *float64 `json:"score"`
+	Action string   `json:"action"`
+}
+
+// SiteVerifier verifies tokens with the siteverify protocol: a form POST of secret,
+// response and remoteip answered with a JSON document
+type SiteVerifier struct {
+	name      string
+	verifyURL string
+	secret    string
+	client    *http.Client
+	// minScore and action are only checked when set, for score based providers
+	minScore float64
+	action   string
+}
+
+// NewTurnstileVerifier creates a Cloudflare Turnstile verifier
+func NewTurnstileVerifier(secret, verifyURL string, client *http.Client) *SiteVerifier {
+	return newSiteVerifier("turnstile", secret, verifyURL, TurnstileVerifyURL, client)
+}
+
+// NewHCaptchaVerifier creates an hCaptcha verifier
+func NewHCaptchaVerifier(secret, verifyURL string, client *http.Client) *SiteVerifier {
+	return newSiteVerifier("hcaptcha", secret, verifyURL, HCaptchaVerifyURL, client)
+}
+
+// NewReCaptchaV3Verifier creates a reCAPTCHA v3 verifier that rejects scores below minScore
+// and, when action is set, tokens issued for another action
+func NewReCaptchaV3Verifier(secret, verifyURL string, minScore float64, action string, client *http.Client) *SiteVerifier {
+	v := newSiteVerifier("recaptcha", secret, verifyURL, ReCaptchaVerifyURL, client)
+	v.minScore = minScore
+	v.action = action
+	return v
+}
+
+func newSiteVerifier(name, secret, verifyURL, defaultURL string, client *http.Client) *SiteVerifier {
+	if verifyURL == "" {
+		verifyURL = defaultURL
+	}
+	return &SiteVerifier{
+		name:      name,
+		verifyURL: verifyURL,
+		secret:    secret,
+		client:    client,
+	}
+}
+
+// Name identifies the provider
+func (v *SiteVerifier) Name() string {
+	return v.name
+}
+
+// Verify posts the token to the provider
+func (v *SiteVerifier) Verify(ctx context.Context, token, remoteIP string) error {
+	form := url.Values{
+		"secret":   {v.secret},
+		"response": {token},
+	}
+	if remoteIP != "" {
+		form.Set("remoteip", remoteIP)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.verifyURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return fmt.Errorf("%w: error creating request: %v", ErrUnavailable, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%w: status code %d", ErrUnavailable, resp.StatusCode)
+	}
+
+	var result siteVerifyResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 64<<10)).Decode(&result); err != nil {
+		return fmt.Errorf("%w: error decoding response: %v", ErrUnavailable, err)
+	}
+
+	if !result.Success {
+		return fmt.Errorf("%w: %s", ErrRejected, strings.Join(result.ErrorCodes, ", "))
+	}
+	if v.minScore > 0 && (result.Score == nil || *result.Score < v.minScore) {
+		return fmt.Errorf("%w: score below %.2f", ErrRejected, v.minScore)
+	}
+	if v.action != "" && result.Action != v.action {
+		return fmt.Errorf("%w: unexpected action %q", ErrRejected, result.Action)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package captcha
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSiteVerifier(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		body     string
+		verifier func(url string, client *http.Client) *SiteVerifier
+		wantErr  error
+	}{
+		{
+			name:   "accepted",
+			status: http.StatusOK,
+			body:   `{"success": true, "hostname": "sctgulf.com"}`,
+			verifier: func(url string, client *http.Client) *SiteVerifier {
+				return NewTurnstileVerifier("secret", url, client)
+			},
+		},
+		{
+			name:     "rejected",
+			status:   http.StatusOK,
+			body:     `{"success": false, "error-codes": ["invalid-input-response"]}`,
+			verifier: func(url string, client *http.Client) *SiteVerifier { return NewHCaptchaVerifier("secret", url, client) },
+			wantErr:  ErrRejected,
+		},
+		{
+			name:   "score high enough",
+			status: http.StatusOK,
+			body:   `{"success": true, "score": 0.9, "action": "contact"}`,
+			verifier: func(url string, client *http.Client) *SiteVerifier {
+				return NewReCaptchaV3Verifier("secret", url, 0.5, "contact", client)
+			},
+		},
+		{
+			name:   "score too low",
+			status: http.StatusOK,
+			body:   `{"success": true, "score": 0.3, "action": "contact"}`,
+			verifier: func(url string, client *http.Client) *SiteVerifier {
+				return NewReCaptchaV3Verifier("secret", url, 0.5, "contact", client)
+			},
+			wantErr: ErrRejected,
+		},
+		{
+			name:   "score missing",
+			status: http.StatusOK,
+			body:   `{"success": true, "action": "contact"}`,
+			verifier: func(url string, client *http.Client) *SiteVerifier {
+				return NewReCaptchaV3Verifier("secret", url, 0.5, "", client)
+			},
+			wantErr: ErrRejected,
+		},
+		{
+			name:   "other action",
+			status: http.StatusOK,
+			body:   `{"success": true, "score": 0.9, "action": "login"}`,
+			verifier: func(url string, client *http.Client) *SiteVerifier {
+				return NewReCaptchaV3Verifier("secret", url, 0.5, "contact", client)
+			},
+			wantErr: ErrRejected,
+		},
+		{
+			name:   "provider error",
+			status: http.StatusInternalServerError,
+			body:   `internal error`,
+			verifier: func(url string, client *http.Client) *SiteVerifier {
+				return NewTurnstileVerifier("secret", url, client)
+			},
+			wantErr: ErrUnavailable,
+		},
+		{
+			name:   "invalid response",
+			status: http.StatusOK,
+			body:   `<html>`,
+			verifier: func(url string, client *http.Client) *SiteVerifier {
+				return NewTurnstileVerifier("secret", url, client)
+			},
+			wantErr: ErrUnavailable,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.Method != http.MethodPost {
+					t.Errorf("method = %s, want POST", r.Method)
+				}
+				if err := r.ParseForm(); err != nil {
+					t.Errorf("ParseForm: %v", err)
+				}
+				if r.PostForm.Get("secret") != "secret" || r.PostForm.Get("response") != "token" || r.PostForm.Get("remoteip") != "203.0.113.7" {
+					t.Errorf("form = %v", r.PostForm)
+				}
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			err := tt.verifier(server.URL, server.Client()).Verify(context.Background(), "token", "203.0.113.7")
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Verify = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSiteVerifierUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	err := NewTurnstileVerifier("secret", url, http.DefaultClient).Verify(context.Background(), "token", "")
+	if !errors.Is(err, ErrUnavailable) {
+		t.Errorf("Verify = %v, want ErrUnavailable", err)
+	}
+}
+
+func TestSiteVerifierDefaultURL(t *testing.T) {
+	if v := NewHCaptchaVerifier("secret", "", http.DefaultClient); v.verifyURL != HCaptchaVerifyURL {
+		t.Errorf("verifyURL = %s, want %s", v.verifyURL, HCaptchaVerifyURL)
+	}
+}
@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"context"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/graph/model"
+)
+
+// storeAttachments saves the uploaded files of a contact and lists them on the enquiry before it is created.
+// It returns the failed result of the contact when a file cannot be stored; the files stored so far are removed.
+func (impl *GraphQLControllerImpl) storeAttachments(ctx context.Context, index int, enquiry *entities.Enquiry, uploads []*graphql.Upload) *model.ContactInfoResult {
+	files, result := impl.saveUploads(ctx, index, enquiry.ID, uploads, enquiry.CreatedAt, enquiry.Locale)
+	enquiry.Attachments = append(enquiry.Attachments, files...)
+	return result
+}
+
+// saveUploads stores the uploaded files below the enquiry with the ID. When a file cannot be stored
+// the files stored so far are removed and the failed result of the contact is returned, reported in locale.
+func (impl *GraphQLControllerImpl) saveUploads(ctx context.Context, index int, enquiryID string, uploads []*graphql.Upload, at time.Time, locale string) ([]entities.EnquiryAttachment, *model.ContactInfoResult) {
+	if len(uploads) == 0 {
+		return nil, nil
+	}
+	if impl.deps.Blobs == nil {
+		impl.deps.Logger.Warn("Attachments dropped, no storage is configured",
+			zap.Int("index", index),
+			zap.Int("attachments", len(uploads)),
+		)
+		return nil, nil
+	}
+
+	files := make([]entities.EnquiryAttachment, 0, len(uploads))
+	for _, upload := range uploads {
+		stored, err := attachment.Save(ctx, impl.deps.Blobs, enquiryID, upload, at)
+		if err != nil {
+			impl.deleteFiles(ctx, enquiryID, files)
+			if ctx.Err() != nil {
+				return nil, cancelledResult(index, locale)
+			}
+			impl.deps.Logger.Error("Error storing attachment", zap.Int("index", index), zap.Error(err))
+			return nil, internalErrorResult(index, locale)
+		}
+		files = append(files, stored)
+	}
+	return files, nil
+}
+
+// deleteAttachments removes the stored files of an enquiry that was not created
+func (impl *GraphQLControllerImpl) deleteAttachments(ctx context.Context, enquiry *entities.Enquiry) {
+	impl.deleteFiles(ctx, enquiry.ID, enquiry.Attachments)
+	enquiry.Attachments = nil
+}
+
+// deleteFiles removes stored files that no enquiry lists.
+// Failures are only logged, an orphaned file is not worth failing the submission for.
+func (impl *GraphQLControllerImpl) deleteFiles(ctx context.Context, enquiryID string, files []entities.EnquiryAttachment) {
+	// The files are removed even when the request was cancelled
+	ctx = context.WithoutCancel(ctx)
+	for _, stored := range files {
+		if err := impl.deps.Blobs.Delete(ctx, stored.Key); err != nil {
+			impl.deps.Logger.Warn("Error deleting attachment",
+				zap.String("enquiry_id", enquiryID),
+				zap.String("key", stored.Key),
+				zap.Error(err),
+			)
+		}
+	}
+}
+
+// enquiryModel converts the enquiry for the API, with a signed download link on every attachment
+func (impl *GraphQLControllerImpl) enquiryModel(enquiry *entities.Enquiry) *model.Enquiry {
+	result := enquiry.ToModel()
+	for i, stored := range enquiry.Attachments {
+		if url := impl.deps.AttachmentLinks.URL(enquiry.ID, stored); url != "" {
+			result.Attachments[i].DownloadURL = &url
+		}
+	}
+	return result
+}
@@ -0,0 +1,52 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/entities"
+	"sct-backend-service/graph/model"
+)
+
+// resubmitEnquiry links a duplicate submission to the original enquiry and queues a
+// "resubmitted" note to the original's destinations instead of a new notification.
+// The files sent with the duplicate are added to the original and linked in the note.
+func (impl *GraphQLControllerImpl) resubmitEnquiry(ctx context.Context, index int, original, duplicate *entities.Enquiry, uploads []*graphql.Upload) *model.ContactInfoResult {
+	files, result := impl.saveUploads(ctx, index, original.ID, uploads, duplicate.CreatedAt, duplicate.Locale)
+	if result != nil {
+		return result
+	}
+	resubmission := entities.EnquiryResubmission{
+		Source:      duplicate.Source,
+		Subject:     duplicate.Subject,
+		ReceivedAt:  duplicate.CreatedAt,
+		Attachments: files,
+	}
+	payload, err := json.Marshal(resubmission)
+	if err != nil {
+		impl.deleteFiles(ctx, original.ID, files)
+		impl.deps.Logger.Error("Error marshalling resubmission", zap.Int("index", index), zap.Error(err))
+		return internalErrorResult(index, duplicate.Locale)
+	}
+
+	outbox := newOutboxMessages(original.ID, entities.OutboxKindEnquiryResubmitted, payload, resubmission.ReceivedAt, impl.deps.Dispatcher.Destinations(original))
+	if _, err := impl.deps.EnquiryRepository.AddResubmission(ctx, original.ID, resubmission, outbox...); err != nil {
+		impl.deleteFiles(ctx, original.ID, files)
+		if ctx.Err() != nil {
+			return cancelledResult(index, duplicate.Locale)
+		}
+		impl.deps.Logger.Error("Error saving resubmission", zap.Int("index", index), zap.String("enquiry_id", original.ID), zap.Error(err))
+		return internalErrorResult(index, duplicate.Locale)
+	}
+	impl.deps.Logger.Info("Duplicate enquiry linked to original",
+		zap.String("enquiry_id", original.ID),
+		zap.String("reference_number", original.ReferenceNumber),
+		zap.String("source", duplicate.Source),
+		zap.Int("attachments", len(files)),
+	)
+
+	return acceptedResult(index, original, true)
+}
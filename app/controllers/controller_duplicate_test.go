@@ -0,0 +1,209 @@
+package controllers
+
+import (
+	"context"
+	"io"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/spam"
+	"sct-backend-service/graph/model"
+)
+
+func TestDuplicateWithAttachmentsAddsFilesToOriginal(t *testing.T) {
+	c := newTestController(t, nil)
+	ctx := context.Background()
+
+	first := c.submitContact(ctx, model.WebsiteSourceSctgulf, 0, testContact(), spam.Verdict{}, "en")
+	if first.Status != model.SubmissionStatusAccepted || first.Duplicate {
+		t.Fatalf("first submission = %+v, want a new enquiry", first)
+	}
+	c.deliver(t)
+	c.sales.reset()
+
+	contact := testContact()
+	contact.Attachments = []*graphql.Upload{testUpload("drawing.pdf")}
+	second := c.submitContact(ctx, model.WebsiteSourceSctgulf, 0, contact, spam.Verdict{}, "en")
+	if second.Status != model.SubmissionStatusAccepted || !second.Duplicate {
+		t.Fatalf("second submission = %+v, want a duplicate", second)
+	}
+	if *second.EnquiryID != *first.EnquiryID {
+		t.Fatalf("duplicate linked to %s, want the original %s", *second.EnquiryID, *first.EnquiryID)
+	}
+
+	// The file is stored and listed on the original
+	original, err := c.enquiries.GetByID(ctx, *first.EnquiryID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if len(original.Attachments) != 1 || original.Attachments[0].FileName != "drawing.pdf" {
+		t.Fatalf("original attachments = %+v, want drawing.pdf", original.Attachments)
+	}
+	stored := original.Attachments[0]
+	content, err := c.deps.Blobs.Open(ctx, stored.Key)
+	if err != nil {
+		t.Fatalf("Open(%s): %v", stored.Key, err)
+	}
+	defer content.Close()
+	if body, _ := io.ReadAll(content); string(body) != "%PDF-1.4\n% drawing\n" {
+		t.Errorf("stored content = %q", body)
+	}
+	if len(original.Resubmissions) != 1 || len(original.Resubmissions[0].Attachments) != 1 {
+		t.Errorf("resubmissions = %+v, want one with the file", original.Resubmissions)
+	}
+
+	// The resubmitted note names the file, so the notifiers can link it
+	c.deliver(t)
+	if kinds := c.sales.kinds(); !slices.Equal(kinds, []string{entities.OutboxKindEnquiryResubmitted}) {
+		t.Fatalf("sales team notified of %q, want only the resubmission", kinds)
+	}
+	notification := c.sales.last(t)
+	if notification.Resubmission == nil || len(notification.Resubmission.Attachments) != 1 ||
+		notification.Resubmission.Attachments[0].ID != stored.ID {
+		t.Errorf("resubmission = %+v, want the stored file %s", notification.Resubmission, stored.ID)
+	}
+	if url := c.deps.AttachmentLinks.URL(original.ID, notification.Resubmission.Attachments[0]); url == "" {
+		t.Error("no download link for the resubmitted file")
+	}
+}
+
+// storeOriginal stores the enquiry the test contact would create, made age ago with the given status
+func storeOriginal(t *testing.T, c *testController, age time.Duration, status string) *entities.Enquiry {
+	t.Helper()
+	contact := testContact()
+	number, err := c.deps.PhoneParser.Parse(model.WebsiteSourceSctgulf.String(), contact.PhoneNumber)
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	enquiry, err := newEnquiry(model.WebsiteSourceSctgulf, contact, number, spam.Verdict{}, "en")
+	if err != nil {
+		t.Fatalf("newEnquiry: %v", err)
+	}
+	enquiry.Status = status
+	enquiry.CreatedAt = enquiry.CreatedAt.Add(-age)
+	if err := c.enquiries.Create(context.Background(), enquiry); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+	return enquiry
+}
+
+func TestDuplicateWindow(t *testing.T) {
+	tests := []struct {
+		name          string
+		window        time.Duration
+		age           time.Duration
+		status        string
+		wantDuplicate bool
+	}{
+		{"within the window", 30 * time.Minute, 10 * time.Minute, entities.EnquiryStatusNew, true},
+		{"original moved on", 30 * time.Minute, 10 * time.Minute, entities.EnquiryStatusContacted, true},
+		{"outside the window", 30 * time.Minute, 2 * time.Hour, entities.EnquiryStatusNew, false},
+		{"original is spam", 30 * time.Minute, 10 * time.Minute, entities.EnquiryStatusSpam, false},
+		{"detection disabled", 0, time.Minute, entities.EnquiryStatusNew, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestController(t, func(deps *ControllerDeps) {
+				deps.DuplicateWindow = tt.window
+			})
+			original := storeOriginal(t, c, tt.age, tt.status)
+
+			result := c.submitContact(context.Background(), model.WebsiteSourceSctgulf, 0, testContact(), spam.Verdict{}, "en")
+			if result.Status != model.SubmissionStatusAccepted {
+				t.Fatalf("submission = %+v, want it accepted", result)
+			}
+			if result.Duplicate != tt.wantDuplicate {
+				t.Fatalf("Duplicate = %v, want %v", result.Duplicate, tt.wantDuplicate)
+			}
+			if linked := *result.EnquiryID == original.ID; linked != tt.wantDuplicate {
+				t.Errorf("linked to the original = %v, want %v", linked, tt.wantDuplicate)
+			}
+			if tt.wantDuplicate && *result.ReferenceNumber != original.ReferenceNumber {
+				t.Errorf("reference number = %s, want the original's %s", *result.ReferenceNumber, original.ReferenceNumber)
+			}
+		})
+	}
+}
+
+func TestDuplicateNotifiesResubmission(t *testing.T) {
+	c := newTestController(t, nil)
+	ctx := context.Background()
+	original := storeOriginal(t, c, 10*time.Minute, entities.EnquiryStatusNew)
+
+	contact := testContact()
+	contact.Subject = "RFQ pumps, second try"
+	c.submitContact(ctx, model.WebsiteSourceSctgulf, 0, contact, spam.Verdict{}, "en")
+	c.deliver(t)
+
+	if kinds := c.sales.kinds(); !slices.Equal(kinds, []string{entities.OutboxKindEnquiryResubmitted}) {
+		t.Fatalf("sales team notified of %q, want only the resubmission", kinds)
+	}
+	if kinds := c.customer.kinds(); len(kinds) != 0 {
+		t.Errorf("customer notified of %q, duplicates get no acknowledgement", kinds)
+	}
+	notification := c.sales.last(t)
+	if notification.Enquiry.ID != original.ID {
+		t.Errorf("notification is about %s, want the original %s", notification.Enquiry.ID, original.ID)
+	}
+	if notification.Resubmission == nil || notification.Resubmission.Subject != contact.Subject || notification.Resubmission.Source != "SCTGULF" {
+		t.Errorf("resubmission = %+v, want the subject and source of the duplicate", notification.Resubmission)
+	}
+
+	stored, err := c.enquiries.GetByID(ctx, original.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if len(stored.Resubmissions) != 1 || stored.Resubmissions[0].Subject != contact.Subject {
+		t.Errorf("resubmissions = %+v, want the duplicate recorded", stored.Resubmissions)
+	}
+}
+
+// slowDuplicateCheck widens the gap between the duplicate check and the write
+type slowDuplicateCheck struct {
+	*data.MemoryEnquiryRepository
+}
+
+func (r slowDuplicateCheck) FindDuplicate(ctx context.Context, fingerprint string, since time.Time) (*entities.Enquiry, error) {
+	original, err := r.MemoryEnquiryRepository.FindDuplicate(ctx, fingerprint, since)
+	time.Sleep(5 * time.Millisecond)
+	return original, err
+}
+
+func TestConcurrentDuplicatesStoredOnce(t *testing.T) {
+	c := newTestController(t, func(deps *ControllerDeps) {
+		deps.EnquiryRepository = slowDuplicateCheck{deps.EnquiryRepository.(*data.MemoryEnquiryRepository)}
+	})
+	ctx := context.Background()
+
+	const submissions = 8
+	results := make([]*model.ContactInfoResult, submissions)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = c.submitContact(ctx, model.WebsiteSourceSctgulf, i, testContact(), spam.Verdict{}, "en")
+		}()
+	}
+	wg.Wait()
+
+	ids, duplicates := map[string]bool{}, 0
+	for _, result := range results {
+		if result.Status != model.SubmissionStatusAccepted {
+			t.Fatalf("submission = %+v, want it accepted", result)
+		}
+		ids[*result.EnquiryID] = true
+		if result.Duplicate {
+			duplicates++
+		}
+	}
+	if len(ids) != 1 || duplicates != submissions-1 {
+		t.Errorf("%d enquiries stored and %d duplicates, want one enquiry and %d duplicates", len(ids), duplicates, submissions-1)
+	}
+}
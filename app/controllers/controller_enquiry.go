@@ -0,0 +1,271 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/graph/model"
+	"sct-backend-service/internal/middleware"
+)
+
+const (
+	// defaultEnquiryPageSize is used when the client does not ask for a page size
+	defaultEnquiryPageSize = 20
+	// maxEnquiryPageSize caps the number of enquiries returned in a single page
+	maxEnquiryPageSize = 100
+)
+
+func (impl *GraphQLControllerImpl) Enquiries(ctx context.Context, filter *model.EnquiryFilter, first *int, after *string) (*model.EnquiryConnection, error) {
+	limit := defaultEnquiryPageSize
+	if first != nil {
+		if *first < 0 {
+			return nil, fmt.Errorf("first must not be negative")
+		}
+		limit = min(*first, maxEnquiryPageSize)
+	}
+
+	var cursor *data.EnquiryCursor
+	if after != nil && *after != "" {
+		decoded, err := data.DecodeEnquiryCursor(*after)
+		if err != nil {
+			return nil, fmt.Errorf("after: %w", err)
+		}
+		cursor = decoded
+	}
+
+	repoFilter := toRepositoryFilter(filter)
+
+	// Fetch one extra row to find out whether there is a next page
+	enquiries, err := impl.deps.EnquiryRepository.List(ctx, repoFilter, cursor, limit+1)
+	if err != nil {
+		impl.deps.Logger.Error("Error listing enquiries", zap.Error(err))
+		return nil, fmt.Errorf("error listing enquiries: %w", err)
+	}
+
+	totalCount, err := impl.deps.EnquiryRepository.Count(ctx, repoFilter)
+	if err != nil {
+		impl.deps.Logger.Error("Error counting enquiries", zap.Error(err))
+		return nil, fmt.Errorf("error counting enquiries: %w", err)
+	}
+
+	hasNextPage := len(enquiries) > limit
+	if hasNextPage {
+		enquiries = enquiries[:limit]
+	}
+
+	connection := &model.EnquiryConnection{
+		Edges:      make([]*model.EnquiryEdge, 0, len(enquiries)),
+		PageInfo:   &model.PageInfo{HasNextPage: hasNextPage},
+		TotalCount: totalCount,
+	}
+	for _, enquiry := range enquiries {
+		connection.Edges = append(connection.Edges, &model.EnquiryEdge{
+			Cursor: data.CursorFor(enquiry).Encode(),
+			Node:   impl.enquiryModel(enquiry),
+		})
+	}
+	if len(connection.Edges) > 0 {
+		endCursor := connection.Edges[len(connection.Edges)-1].Cursor
+		connection.PageInfo.EndCursor = &endCursor
+	}
+
+	return connection, nil
+}
+
+func (impl *GraphQLControllerImpl) EnquiryStats(ctx context.Context, filter *model.EnquiryFilter) (*model.EnquiryStats, error) {
+	stats, err := impl.deps.EnquiryRepository.Stats(ctx, toRepositoryFilter(filter))
+	if err != nil {
+		impl.deps.Logger.Error("Error counting enquiries", zap.Error(err))
+		return nil, fmt.Errorf("error counting enquiries: %w", err)
+	}
+
+	result := &model.EnquiryStats{
+		Total:    stats.Total,
+		ByStatus: make([]*model.EnquiryStatusCount, 0, len(model.AllEnquiryStatus)),
+		BySource: make([]*model.EnquirySourceCount, 0, len(model.AllWebsiteSource)),
+	}
+	for _, status := range model.AllEnquiryStatus {
+		result.ByStatus = append(result.ByStatus, &model.EnquiryStatusCount{Status: status, Count: stats.ByStatus[status.String()]})
+	}
+	for _, source := range model.AllWebsiteSource {
+		result.BySource = append(result.BySource, &model.EnquirySourceCount{Source: source, Count: stats.BySource[source.String()]})
+	}
+	return result, nil
+}
+
+func (impl *GraphQLControllerImpl) Enquiry(ctx context.Context, id string) (*model.Enquiry, error) {
+	enquiry, err := impl.deps.EnquiryRepository.GetByID(ctx, id)
+	if errors.Is(err, data.ErrEnquiryNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		impl.deps.Logger.Error("Error loading enquiry", zap.String("enquiry_id", id), zap.Error(err))
+		return nil, fmt.Errorf("error loading enquiry: %w", err)
+	}
+	return impl.enquiryModel(enquiry), nil
+}
+
+func (impl *GraphQLControllerImpl) TransitionEnquiryStatus(ctx context.Context, id string, from, to model.EnquiryStatus, reason string) (*model.Enquiry, error) {
+	change := entities.EnquiryStatusChange{
+		From:      from.String(),
+		To:        to.String(),
+		Reason:    reason,
+		ChangedAt: time.Now().UTC(),
+	}
+	if userID, ok := middleware.GetUserID(ctx); ok {
+		change.ChangedBy = userID
+	}
+
+	enquiry, err := impl.deps.EnquiryRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error loading enquiry: %w", err)
+	}
+	outbox, err := impl.followUpMessages(enquiry, entities.OutboxKindEnquiryStatusChanged, change, change.ChangedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	enquiry, err = impl.deps.EnquiryRepository.UpdateStatus(ctx, id, change, outbox...)
+	if err != nil {
+		impl.deps.Logger.Error("Error updating enquiry status",
+			zap.String("enquiry_id", id),
+			zap.String("from", change.From),
+			zap.String("to", change.To),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("error updating enquiry status: %w", err)
+	}
+
+	impl.deps.Logger.Info("Enquiry status updated",
+		zap.String("enquiry_id", id),
+		zap.String("from", change.From),
+		zap.String("to", change.To),
+		zap.Int("notifications", len(outbox)),
+	)
+	impl.deps.OutboxWorker.Wake()
+	return impl.enquiryModel(enquiry), nil
+}
+
+func (impl *GraphQLControllerImpl) AddEnquiryNote(ctx context.Context, id, text string) (*model.Enquiry, error) {
+	enquiry, err := impl.deps.EnquiryRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error loading enquiry: %w", err)
+	}
+
+	note := entities.EnquiryNote{
+		Text:      text,
+		CreatedAt: time.Now().UTC(),
+	}
+	if userID, ok := middleware.GetUserID(ctx); ok {
+		note.Author = userID
+	}
+	outbox, err := impl.followUpMessages(enquiry, entities.OutboxKindEnquiryNoteAdded, note, note.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	enquiry, err = impl.deps.EnquiryRepository.AddNote(ctx, id, note, outbox...)
+	if err != nil {
+		impl.deps.Logger.Error("Error adding enquiry note",
+			zap.String("enquiry_id", id),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("error adding enquiry note: %w", err)
+	}
+
+	impl.deps.Logger.Info("Enquiry note added",
+		zap.String("enquiry_id", id),
+		zap.Int("notifications", len(outbox)),
+	)
+	impl.deps.OutboxWorker.Wake()
+	return impl.enquiryModel(enquiry), nil
+}
+
+func (impl *GraphQLControllerImpl) AssignEnquiry(ctx context.Context, id, assignee, slackUserID string) (*model.Enquiry, error) {
+	enquiry, err := impl.deps.EnquiryRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error loading enquiry: %w", err)
+	}
+
+	assignment := entities.EnquiryAssignment{
+		Assignee:    assignee,
+		SlackUserID: slackUserID,
+		AssignedAt:  time.Now().UTC(),
+	}
+	if userID, ok := middleware.GetUserID(ctx); ok {
+		assignment.AssignedBy = userID
+	}
+	outbox, err := impl.followUpMessages(enquiry, entities.OutboxKindEnquiryAssigned, assignment, assignment.AssignedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	enquiry, err = impl.deps.EnquiryRepository.Assign(ctx, id, assignment, outbox...)
+	if err != nil {
+		impl.deps.Logger.Error("Error assigning enquiry",
+			zap.String("enquiry_id", id),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("error assigning enquiry: %w", err)
+	}
+
+	impl.deps.Logger.Info("Enquiry assigned",
+		zap.String("enquiry_id", id),
+		zap.String("assignee", assignee),
+		zap.Int("notifications", len(outbox)),
+	)
+	impl.deps.OutboxWorker.Wake()
+	return impl.enquiryModel(enquiry), nil
+}
+
+// followUpMessages builds the follow-up notifications of the enquiry for the destinations that
+// thread them. An enquiry that was never released from quarantine was never notified, so there is nothing to follow up.
+func (impl *GraphQLControllerImpl) followUpMessages(enquiry *entities.Enquiry, kind string, payload interface{}, at time.Time) ([]*entities.OutboxMessage, error) {
+	if !enquiry.Notified() {
+		return nil, nil
+	}
+	destinations := impl.deps.Dispatcher.FollowUpDestinations(enquiry)
+	if len(destinations) == 0 {
+		return nil, nil
+	}
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding %s notification: %w", kind, err)
+	}
+	return newOutboxMessages(enquiry.ID, kind, encoded, at, destinations), nil
+}
+
+// toRepositoryFilter converts the GraphQL filter to the repository filter
+func toRepositoryFilter(filter *model.EnquiryFilter) data.EnquiryFilter {
+	var repoFilter data.EnquiryFilter
+	if filter == nil {
+		return repoFilter
+	}
+
+	if filter.Source != nil {
+		repoFilter.Source = filter.Source.String()
+	}
+	if filter.Status != nil {
+		repoFilter.Status = filter.Status.String()
+	}
+	repoFilter.CreatedFrom = filter.CreatedFrom
+	repoFilter.CreatedTo = filter.CreatedTo
+	if filter.Email != nil {
+		repoFilter.Email = *filter.Email
+	}
+	if filter.Company != nil {
+		repoFilter.Company = *filter.Company
+	}
+	if filter.Search != nil {
+		repoFilter.Search = *filter.Search
+	}
+	return repoFilter
+}
@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"context"
+	"testing"
+
+	"sct-backend-service/graph/model"
+)
+
+func TestEnquiriesPages(t *testing.T) {
+	c := newTestController(t, nil)
+	ctx := context.Background()
+	contacts := distinctContacts(5)
+	contacts[3].CompanyName = "Globex"
+	if _, err := c.SendContactInfo(ctx, model.SendContactInfoRequest{Source: model.WebsiteSourceSctgulf, ContactInfo: contacts}); err != nil {
+		t.Fatalf("SendContactInfo: %v", err)
+	}
+
+	first := 2
+	var after *string
+	var emails []string
+	for page := 1; ; page++ {
+		connection, err := c.Enquiries(ctx, nil, &first, after)
+		if err != nil {
+			t.Fatalf("page %d: %v", page, err)
+		}
+		if connection.TotalCount != 5 {
+			t.Errorf("page %d: totalCount = %d, want 5", page, connection.TotalCount)
+		}
+		for _, edge := range connection.Edges {
+			emails = append(emails, edge.Node.Email)
+		}
+		if !connection.PageInfo.HasNextPage {
+			if page != 3 {
+				t.Errorf("last page is %d, want 3", page)
+			}
+			break
+		}
+		if connection.PageInfo.EndCursor == nil || *connection.PageInfo.EndCursor != connection.Edges[len(connection.Edges)-1].Cursor {
+			t.Fatalf("page %d: endCursor = %v, want the cursor of the last edge", page, connection.PageInfo.EndCursor)
+		}
+		after = connection.PageInfo.EndCursor
+	}
+	if len(emails) != 5 {
+		t.Errorf("pages listed %v, want each of the 5 enquiries once", emails)
+	}
+	seen := make(map[string]bool)
+	for _, email := range emails {
+		if seen[email] {
+			t.Errorf("%s is listed twice", email)
+		}
+		seen[email] = true
+	}
+
+	company := "globex"
+	connection, err := c.Enquiries(ctx, &model.EnquiryFilter{Company: &company}, nil, nil)
+	if err != nil || connection.TotalCount != 1 || len(connection.Edges) != 1 || connection.Edges[0].Node.Email != contacts[3].Email {
+		t.Errorf("filtered by company = %+v, %v, want the Globex enquiry", connection, err)
+	}
+}
+
+func TestEnquiriesRejectsInvalidArguments(t *testing.T) {
+	c := newTestController(t, nil)
+	negative, cursor := -1, "not-a-cursor"
+	if _, err := c.Enquiries(context.Background(), nil, &negative, nil); err == nil {
+		t.Error("a negative page size was accepted")
+	}
+	if _, err := c.Enquiries(context.Background(), nil, nil, &cursor); err == nil {
+		t.Error("an invalid cursor was accepted")
+	}
+}
+
+func TestEnquiryNotFound(t *testing.T) {
+	c := newTestController(t, nil)
+	enquiry, err := c.Enquiry(context.Background(), "missing")
+	if err != nil || enquiry != nil {
+		t.Errorf("Enquiry = %v, %v, want nil without an error", enquiry, err)
+	}
+}
@@ -1,15 +1,23 @@
 package controllers
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
-	"os"
+	"strings"
+	"sync"
+	"time"
 
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/i18n"
 	"sct-backend-service/app/keys"
+	"sct-backend-service/app/phone"
+	"sct-backend-service/app/spam"
+	"sct-backend-service/app/utils"
 	"sct-backend-service/graph/model"
+	"sct-backend-service/internal/middleware"
 	"sct-backend-service/types"
 
 	"go.uber.org/zap"
@@ -17,6 +25,21 @@ import (
 
 type GraphQLController interface {
 	types.GraphQLService
+	// TransitionEnquiryStatus moves an enquiry from one status to another.
+	// It does not check whether the transition is allowed, that is up to the workflow.
+	TransitionEnquiryStatus(ctx context.Context, id string, from, to model.EnquiryStatus, reason string) (*model.Enquiry, error)
+	// SubmitContactInfo stores the contacts like SendContactInfo.
+	// Contacts whose verdict is spam are quarantined: stored as SPAM without notifications.
+	// verdicts is indexed like input.ContactInfo and may be nil.
+	SubmitContactInfo(ctx context.Context, input model.SendContactInfoRequest, verdicts []spam.Verdict) (*model.SendContactInfoResponse, error)
+	// ReleaseEnquiry moves a SPAM enquiry back to NEW and queues the notifications a quarantined enquiry never got
+	ReleaseEnquiry(ctx context.Context, id, reason string) (*model.Enquiry, error)
+	// AddEnquiryNote appends an internal note to the enquiry and queues it for the destinations that thread follow-ups
+	AddEnquiryNote(ctx context.Context, id, text string) (*model.Enquiry, error)
+	// AssignEnquiry sets the salesperson handling the enquiry, slackUserID may be empty
+	AssignEnquiry(ctx context.Context, id, assignee, slackUserID string) (*model.Enquiry, error)
+	// PreviewNotification renders the new enquiry notification of a channel for the contact without storing or sending anything
+	PreviewNotification(ctx context.Context, source model.WebsiteSource, channel model.NotificationChannel, input *model.ContactInfoInput) (*model.NotificationPreview, error)
 }
 
 type GraphQLControllerImpl struct {
@@ -30,120 +53,264 @@ func CreateGraphQLController(deps ControllerDeps) GraphQLController {
 }
 
 func (impl *GraphQLControllerImpl) SendContactInfo(ctx context.Context, input model.SendContactInfoRequest) (*model.SendContactInfoResponse, error) {
-	for _, contact := range input.ContactInfo {
-		slackBody := generateSlackMessage(input.Source, contact)
-		// Convert the struct to JSON
-		jsonData, err := json.Marshal(slackBody)
-		if err != nil {
-			impl.deps.Logger.Error("Error marshalling JSON", zap.Error(err))
-			return nil, fmt.Errorf("error marshalling JSON: %w", err)
-		}
+	return impl.SubmitContactInfo(ctx, input, nil)
+}
 
-		webhookURL := os.Getenv(keys.SlackWebhookEnvKey)
-		if webhookURL == "" {
-			impl.deps.Logger.Error("Slack webhook URL is not configured")
-			return nil, fmt.Errorf("slack webhook url is not configured")
-		}
+func (impl *GraphQLControllerImpl) SubmitContactInfo(ctx context.Context, input model.SendContactInfoRequest, verdicts []spam.Verdict) (*model.SendContactInfoResponse, error) {
+	// A failing contact does not stop the others, each one gets its own result
+	results := impl.submitContacts(ctx, input.Source, input.ContactInfo, verdicts, middleware.Locale(ctx))
 
-		// Send the HTTP POST request
-		resp, err := http.Post(webhookURL, "application/json", bytes.NewBuffer(jsonData))
-		if err != nil {
-			impl.deps.Logger.Error("Error sending message", zap.Error(err))
-			return nil, fmt.Errorf("error sending message: %w", err)
+	response := &model.SendContactInfoResponse{
+		IsSuccess: true,
+		Results:   results,
+	}
+	for _, result := range results {
+		if result.Status != model.SubmissionStatusAccepted {
+			response.IsSuccess = false
 		}
-		defer resp.Body.Close()
+	}
+
+	impl.deps.OutboxWorker.Wake()
+	return response, nil
+}
 
-		if resp.StatusCode != http.StatusOK {
-			impl.deps.Logger.Error("failed to send notification", zap.Int("status code", resp.StatusCode))
-			return nil, fmt.Errorf("failed to send notification, status code: %d", resp.StatusCode)
+// submitContacts processes the contacts with bounded concurrency and returns the results in input order.
+// Contacts that have not started when ctx is cancelled are reported as cancelled;
+// it always waits for started work, so no goroutine outlives the request.
+// locale is the language of the visitor, the enquiries store it and errors are reported in it.
+func (impl *GraphQLControllerImpl) submitContacts(ctx context.Context, source model.WebsiteSource, contacts []*model.ContactInfoInput, verdicts []spam.Verdict, locale string) []*model.ContactInfoResult {
+	results := make([]*model.ContactInfoResult, len(contacts))
+	semaphore := make(chan struct{}, max(impl.deps.SubmissionConcurrency, 1))
+	var wg sync.WaitGroup
+
+	for index, contact := range contacts {
+		select {
+		case semaphore <- struct{}{}:
+		case <-ctx.Done():
+			results[index] = cancelledResult(index, locale)
+			continue
 		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() { <-semaphore }()
+			defer func() {
+				if r := recover(); r != nil {
+					impl.deps.Logger.Error("Panic while submitting contact", zap.Int("index", index), zap.Any("panic", r))
+					results[index] = internalErrorResult(index, locale)
+				}
+			}()
+
+			var verdict spam.Verdict
+			if index < len(verdicts) {
+				verdict = verdicts[index]
+			}
+			results[index] = impl.submitContact(ctx, source, index, contact, verdict, locale)
+		}()
 	}
-	return &model.SendContactInfoResponse{
-		IsSuccess: true,
-	}, nil
+
+	wg.Wait()
+	return results
 }
 
-func generateSlackMessage(source model.WebsiteSource, input *model.ContactInfoInput) map[string]interface{} {
-	slackBody := map[string]interface{}{
-		"blocks": []map[string]interface{}{
-			{
-				"type": "header",
-				"text": map[string]string{
-					"type": "plain_text",
-					"text": fmt.Sprintf("New Customer Enquiry from %s", source.String()),
-				},
-			},
-			{
-				"type": "section",
-				"text": map[string]string{
-					"type": "mrkdwn",
-					"text": fmt.Sprintf("%s has filled out the enquiry form on the website", input.Name),
-				},
-			},
-			{
-				"type": "divider",
-			},
+// cancelledResult is reported for contacts that were never processed
+func cancelledResult(index int, locale string) *model.ContactInfoResult {
+	return &model.ContactInfoResult{
+		Index:  index,
+		Status: model.SubmissionStatusFailed,
+		Errors: []*model.SubmissionError{
 			{
-				"type": "section",
-				"text": map[string]string{
-					"type": "mrkdwn",
-					"text": fmt.Sprintf("Name: %s", input.Name),
-				},
-			},
-			{
-				"type": "section",
-				"text": map[string]string{
-					"type": "mrkdwn",
-					"text": fmt.Sprintf("Email: %s", input.Email),
-				},
-			},
-			{
-				"type": "section",
-				"text": map[string]string{
-					"type": "mrkdwn",
-					"text": fmt.Sprintf("Phone Number: %s", input.PhoneNumber),
-				},
-			},
-			{
-				"type": "section",
-				"text": map[string]string{
-					"type": "mrkdwn",
-					"text": fmt.Sprintf("Company Name: %s", input.CompanyName),
-				},
-			},
-			{
-				"type": "section",
-				"text": map[string]string{
-					"type": "mrkdwn",
-					"text": fmt.Sprintf("Subject: %s", input.Subject),
-				},
-			},
-			{
-				"type": "section",
-				"text": map[string]string{
-					"type": "mrkdwn",
-					"text": fmt.Sprintf("Message: %s", input.Message),
-				},
-			},
-			{
-				"type": "divider",
+				Code:    keys.ErrCodeCancelled,
+				Message: i18n.Translate(locale, "the request was cancelled before this enquiry was processed"),
 			},
+		},
+	}
+}
+
+// submitContact stores a single contact and queues its notifications
+func (impl *GraphQLControllerImpl) submitContact(ctx context.Context, source model.WebsiteSource, index int, contact *model.ContactInfoInput, verdict spam.Verdict, locale string) *model.ContactInfoResult {
+	// The workflow rejects numbers that do not parse, other callers keep the number as submitted
+	number, err := impl.deps.PhoneParser.Parse(source.String(), contact.PhoneNumber)
+	if err != nil {
+		impl.deps.Logger.Debug("Phone number could not be parsed", zap.Int("index", index), zap.Error(err))
+	}
+
+	enquiry, err := newEnquiry(source, contact, number, verdict, locale)
+	if err != nil {
+		impl.deps.Logger.Error("Error building enquiry", zap.Int("index", index), zap.Error(err))
+		return internalErrorResult(index, locale)
+	}
+
+	if verdict.Spam {
+		if result := impl.storeAttachments(ctx, index, enquiry, contact.Attachments); result != nil {
+			return result
+		}
+		return impl.quarantineEnquiry(ctx, index, enquiry)
+	}
+
+	if impl.deps.DuplicateWindow > 0 {
+		// The store serializes identical contacts, so two of them submitted at the same moment are not both stored
+		unlock, err := impl.deps.EnquiryRepository.LockFingerprint(ctx, enquiry.Fingerprint)
+		if err == nil {
+			defer unlock()
+			var original *entities.Enquiry
+			original, err = impl.deps.EnquiryRepository.FindDuplicate(ctx, enquiry.Fingerprint, enquiry.CreatedAt.Add(-impl.deps.DuplicateWindow))
+			if err == nil {
+				return impl.resubmitEnquiry(ctx, index, original, enquiry, contact.Attachments)
+			}
+		}
+		switch {
+		case ctx.Err() != nil:
+			return cancelledResult(index, enquiry.Locale)
+		case !errors.Is(err, data.ErrEnquiryNotFound):
+			// Keeping a possible duplicate is better than losing the lead
+			impl.deps.Logger.Error("Error checking for duplicate enquiry", zap.Int("index", index), zap.Error(err))
+		}
+	}
+
+	if result := impl.storeAttachments(ctx, index, enquiry, contact.Attachments); result != nil {
+		return result
+	}
+
+	// Notifications are queued in the same write as the enquiry and delivered by the outbox worker,
+	// so the lead is kept and the visitor sees success even if a channel is down
+	outbox := impl.newEnquiryMessages(enquiry, enquiry.CreatedAt)
+	if err := impl.deps.EnquiryRepository.Create(ctx, enquiry, outbox...); err != nil {
+		impl.deleteAttachments(ctx, enquiry)
+		if ctx.Err() != nil {
+			return cancelledResult(index, enquiry.Locale)
+		}
+		impl.deps.Logger.Error("Error saving enquiry", zap.Int("index", index), zap.Error(err))
+		return internalErrorResult(index, enquiry.Locale)
+	}
+	impl.deps.Logger.Info("Enquiry saved",
+		zap.String("enquiry_id", enquiry.ID),
+		zap.String("reference_number", enquiry.ReferenceNumber),
+		zap.String("source", enquiry.Source),
+		zap.Int("notifications", len(outbox)),
+	)
+
+	return acceptedResult(index, enquiry, false)
+}
+
+// acceptedResult reports a contact that was stored, or linked to the stored original when duplicate is set
+func acceptedResult(index int, enquiry *entities.Enquiry, duplicate bool) *model.ContactInfoResult {
+	return &model.ContactInfoResult{
+		Index:           index,
+		Status:          model.SubmissionStatusAccepted,
+		EnquiryID:       &enquiry.ID,
+		ReferenceNumber: &enquiry.ReferenceNumber,
+		Duplicate:       duplicate,
+		Errors:          []*model.SubmissionError{},
+	}
+}
+
+// internalErrorResult reports a contact that failed for an unexpected reason
+func internalErrorResult(index int, locale string) *model.ContactInfoResult {
+	return &model.ContactInfoResult{
+		Index:  index,
+		Status: model.SubmissionStatusFailed,
+		Errors: []*model.SubmissionError{internalSubmissionError(locale)},
+	}
+}
+
+// internalSubmissionError hides the cause of unexpected failures from the client, it is logged instead
+func internalSubmissionError(locale string) *model.SubmissionError {
+	return &model.SubmissionError{
+		Code:    keys.ErrCodeInternal,
+		Message: i18n.Translate(locale, "the enquiry could not be saved, please try again later"),
+	}
+}
+
+// newEnquiryMessages builds the notifications of a new enquiry for the sales team and the acknowledgement for the customer
+func (impl *GraphQLControllerImpl) newEnquiryMessages(enquiry *entities.Enquiry, at time.Time) []*entities.OutboxMessage {
+	outbox := newOutboxMessages(enquiry.ID, entities.OutboxKindEnquiryCreated, nil, at, impl.deps.Dispatcher.Destinations(enquiry))
+	return append(outbox, newOutboxMessages(enquiry.ID, entities.OutboxKindEnquiryAcknowledged, nil, at, impl.deps.Dispatcher.AcknowledgementDestinations(enquiry))...)
+}
+
+// newOutboxMessages builds one pending notification of the given kind per destination
+func newOutboxMessages(enquiryID, kind string, payload []byte, at time.Time, destinations []string) []*entities.OutboxMessage {
+	messages := make([]*entities.OutboxMessage, 0, len(destinations))
+	for _, destination := range destinations {
+		messages = append(messages, &entities.OutboxMessage{
+			ID:            utils.GenerateID(),
+			EnquiryID:     enquiryID,
+			Kind:          kind,
+			Destination:   destination,
+			Payload:       payload,
+			Status:        entities.OutboxStatusPending,
+			NextAttemptAt: at,
+			CreatedAt:     at,
+			UpdatedAt:     at,
+		})
+	}
+	return messages
+}
+
+// newEnquiry builds the enquiry entity for a single contact submission.
+// number is the parsed phone number, zero when it could not be parsed.
+// A spam verdict makes it start out as SPAM instead of NEW.
+func newEnquiry(source model.WebsiteSource, input *model.ContactInfoInput, number phone.Number, verdict spam.Verdict, locale string) (*entities.Enquiry, error) {
+	// The raw payload keeps what was typed, the files are stored separately
+	payload := *input
+	payload.Attachments = nil
+	rawPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling enquiry payload: %w", err)
+	}
+
+	now := time.Now().UTC()
+	enquiry := &entities.Enquiry{
+		ID:              utils.GenerateID(),
+		ReferenceNumber: utils.GenerateReferenceNumber(source.String(), now),
+		Source:          source.String(),
+		Name:            input.Name,
+		Email:           input.Email,
+		PhoneNumber:     input.PhoneNumber,
+		PhoneE164:       number.E164,
+		PhoneCountry:    number.Country,
+		PhoneLineType:   string(number.LineType),
+		CompanyName:     input.CompanyName,
+		Subject:         input.Subject,
+		Message:         input.Message,
+		RawPayload:      rawPayload,
+		Fingerprint:     utils.EnquiryFingerprint(input.Email, phoneForFingerprint(input.PhoneNumber, number), input.Message),
+		Status:          entities.EnquiryStatusNew,
+		StatusHistory: []entities.EnquiryStatusChange{
 			{
-				"type": "actions",
-				"elements": []map[string]interface{}{
-					{
-						"type": "button",
-						"text": map[string]string{
-							"type": "plain_text",
-							"text": "Reply to Customer",
-						},
-						"url":   fmt.Sprintf("mailto:%s", input.Email),
-						"style": "primary",
-					},
-				},
+				To:        entities.EnquiryStatusNew,
+				Reason:    "Enquiry received",
+				ChangedAt: now,
 			},
 		},
+		SpamScore:   verdict.Score,
+		SpamReasons: verdict.Reasons,
+		Locale:      locale,
+		CreatedAt:   now,
+		UpdatedAt:   now,
 	}
+	// Quarantined enquiries are notified when they are released
+	if !verdict.Spam {
+		enquiry.NotifiedAt = &now
+	} else {
+		enquiry.Status = entities.EnquiryStatusSpam
+		enquiry.StatusHistory[0] = entities.EnquiryStatusChange{
+			To:        entities.EnquiryStatusSpam,
+			Reason:    "Quarantined by the spam filter: " + strings.Join(verdict.Reasons, "; "),
+			ChangedAt: now,
+		}
+	}
+	if input.Country != nil {
+		enquiry.Country = strings.ToUpper(strings.TrimSpace(*input.Country))
+	}
+	return enquiry, nil
+}
 
-	return slackBody
+// phoneForFingerprint prefers the normalized number, so 050 123 4567 and +971 50 123 4567 match
+func phoneForFingerprint(raw string, number phone.Number) string {
+	if number.E164 != "" {
+		return number.E164
+	}
+	return raw
 }
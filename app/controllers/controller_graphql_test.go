@@ -0,0 +1,257 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/graph/model"
+)
+
+// instrumentedRepository tracks concurrent writes, holds them until release is closed
+// and panics on contacts named "panic"
+type instrumentedRepository struct {
+	*data.MemoryEnquiryRepository
+	release chan struct{}
+
+	mu          sync.Mutex
+	inFlight    int
+	maxInFlight int
+	started     chan struct{}
+}
+
+func (r *instrumentedRepository) Create(ctx context.Context, enquiry *entities.Enquiry, outbox ...*entities.OutboxMessage) error {
+	if enquiry.Name == "panic" {
+		panic("broken contact")
+	}
+	r.mu.Lock()
+	r.inFlight++
+	r.maxInFlight = max(r.maxInFlight, r.inFlight)
+	r.mu.Unlock()
+	r.started <- struct{}{}
+
+	<-r.release
+	r.mu.Lock()
+	r.inFlight--
+	r.mu.Unlock()
+	return r.MemoryEnquiryRepository.Create(ctx, enquiry, outbox...)
+}
+
+// newInstrumentedController creates a controller writing through an instrumented repository
+func newInstrumentedController(t *testing.T, concurrency int) (*testController, *instrumentedRepository) {
+	t.Helper()
+	var repository *instrumentedRepository
+	c := newTestController(t, func(deps *ControllerDeps) {
+		repository = &instrumentedRepository{
+			MemoryEnquiryRepository: deps.EnquiryRepository.(*data.MemoryEnquiryRepository),
+			release:                 make(chan struct{}),
+			started:                 make(chan struct{}, 100),
+		}
+		deps.EnquiryRepository = repository
+		deps.SubmissionConcurrency = concurrency
+		deps.DuplicateWindow = 0
+	})
+	return c, repository
+}
+
+// distinctContacts returns contacts that differ in name and email
+func distinctContacts(n int) []*model.ContactInfoInput {
+	contacts := make([]*model.ContactInfoInput, n)
+	for i := range contacts {
+		contacts[i] = testContact()
+		contacts[i].Name = fmt.Sprintf("Contact %d", i)
+		contacts[i].Email = fmt.Sprintf("contact%d@example.com", i)
+	}
+	return contacts
+}
+
+func TestSendContactInfoStoresEnquiry(t *testing.T) {
+	c := newTestController(t, nil)
+	ctx := context.Background()
+	contact := testContact()
+	country := "ae"
+	contact.Country = &country
+
+	before := time.Now().UTC()
+	response, err := c.SendContactInfo(ctx, model.SendContactInfoRequest{
+		Source:      model.WebsiteSourceSctgulf,
+		ContactInfo: []*model.ContactInfoInput{contact},
+	})
+	if err != nil || !response.IsSuccess || len(response.Results) != 1 {
+		t.Fatalf("SendContactInfo = %+v, %v", response, err)
+	}
+
+	enquiry, err := c.enquiries.GetByID(ctx, *response.Results[0].EnquiryID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if enquiry.Source != "SCTGULF" || enquiry.Name != contact.Name || enquiry.Email != contact.Email ||
+		enquiry.PhoneNumber != contact.PhoneNumber || enquiry.CompanyName != contact.CompanyName ||
+		enquiry.Subject != contact.Subject || enquiry.Message != contact.Message || enquiry.Country != "AE" {
+		t.Errorf("stored enquiry = %+v, want the fields of the contact", enquiry)
+	}
+	if enquiry.CreatedAt.Before(before) || !enquiry.UpdatedAt.Equal(enquiry.CreatedAt) {
+		t.Errorf("timestamps = %s, %s, want both set on submission", enquiry.CreatedAt, enquiry.UpdatedAt)
+	}
+
+	// The raw payload keeps the contact as it was submitted
+	var payload model.ContactInfoInput
+	if err := json.Unmarshal(enquiry.RawPayload, &payload); err != nil {
+		t.Fatalf("raw payload %s: %v", enquiry.RawPayload, err)
+	}
+	if !reflect.DeepEqual(&payload, contact) {
+		t.Errorf("raw payload = %+v, want %+v", payload, *contact)
+	}
+}
+
+// failingRepository fails to store contacts named "fail"
+type failingRepository struct {
+	*data.MemoryEnquiryRepository
+}
+
+func (r failingRepository) Create(ctx context.Context, enquiry *entities.Enquiry, outbox ...*entities.OutboxMessage) error {
+	if enquiry.Name == "fail" {
+		return errors.New("disk full")
+	}
+	return r.MemoryEnquiryRepository.Create(ctx, enquiry, outbox...)
+}
+
+func TestSendContactInfoPartialSuccess(t *testing.T) {
+	c := newTestController(t, func(deps *ControllerDeps) {
+		deps.EnquiryRepository = failingRepository{deps.EnquiryRepository.(*data.MemoryEnquiryRepository)}
+	})
+	contacts := distinctContacts(3)
+	contacts[1].Name = "fail"
+
+	response, err := c.SendContactInfo(context.Background(), model.SendContactInfoRequest{Source: model.WebsiteSourceSctgulf, ContactInfo: contacts})
+	if err != nil {
+		t.Fatalf("SendContactInfo: %v", err)
+	}
+	if response.IsSuccess || len(response.Results) != 3 {
+		t.Fatalf("response = %+v, want 3 results and no overall success", response)
+	}
+
+	failed := response.Results[1]
+	if failed.Index != 1 || failed.Status != model.SubmissionStatusFailed || failed.EnquiryID != nil || failed.ReferenceNumber != nil ||
+		len(failed.Errors) != 1 || failed.Errors[0].Code != keys.ErrCodeInternal || strings.Contains(failed.Errors[0].Message, "disk full") {
+		t.Errorf("failed result = %+v, want an internal error that hides its cause", failed)
+	}
+	for _, i := range []int{0, 2} {
+		result := response.Results[i]
+		if result.Index != i || result.Status != model.SubmissionStatusAccepted || result.EnquiryID == nil || len(result.Errors) != 0 {
+			t.Fatalf("result %d = %+v, want it accepted", i, result)
+		}
+		if !regexp.MustCompile(`^SCTGULF-\d{6}-[0-9A-Z]{6}$`).MatchString(*result.ReferenceNumber) {
+			t.Errorf("reference number = %q", *result.ReferenceNumber)
+		}
+	}
+
+	// The contacts after the failure are stored and notified
+	c.deliver(t)
+	if kinds := c.sales.kinds(); len(kinds) != 2 {
+		t.Errorf("sales team got %v, want the 2 stored enquiries", kinds)
+	}
+}
+
+func TestSubmitContactsConcurrencyAndOrder(t *testing.T) {
+	c, repository := newInstrumentedController(t, 3)
+	ctx := context.Background()
+
+	done := make(chan []*model.ContactInfoResult)
+	go func() {
+		done <- c.submitContacts(ctx, model.WebsiteSourceSctgulf, distinctContacts(10), nil, "en")
+	}()
+
+	// The cap is reached and not exceeded while the writes are held
+	for range 3 {
+		<-repository.started
+	}
+	select {
+	case <-repository.started:
+		t.Fatal("more contacts started than the concurrency allows")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(repository.release)
+	results := <-done
+
+	if repository.maxInFlight != 3 {
+		t.Errorf("%d contacts were written at once, want 3", repository.maxInFlight)
+	}
+	for i, result := range results {
+		if result.Index != i || result.Status != model.SubmissionStatusAccepted {
+			t.Fatalf("result %d = %+v, want contact %d accepted", i, result, i)
+		}
+		enquiry, err := c.enquiries.GetByID(ctx, *result.EnquiryID)
+		if err != nil {
+			t.Fatalf("GetByID: %v", err)
+		}
+		if want := fmt.Sprintf("contact%d@example.com", i); enquiry.Email != want {
+			t.Errorf("result %d is for %s, want %s", i, enquiry.Email, want)
+		}
+	}
+}
+
+func TestSubmitContactsRecoversFromPanic(t *testing.T) {
+	c, repository := newInstrumentedController(t, 2)
+	close(repository.release)
+
+	contacts := distinctContacts(3)
+	contacts[1].Name = "panic"
+	results := c.submitContacts(context.Background(), model.WebsiteSourceSctgulf, contacts, nil, "en")
+
+	if results[1].Status != model.SubmissionStatusFailed || len(results[1].Errors) != 1 || results[1].Errors[0].Code != keys.ErrCodeInternal {
+		t.Errorf("panicking contact = %+v, want an internal error", results[1])
+	}
+	for _, i := range []int{0, 2} {
+		if results[i].Status != model.SubmissionStatusAccepted {
+			t.Errorf("contact %d = %+v, want it accepted despite the panic", i, results[i])
+		}
+	}
+}
+
+func TestSubmitContactsCancelled(t *testing.T) {
+	c, repository := newInstrumentedController(t, 2)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	done := make(chan []*model.ContactInfoResult)
+	go func() {
+		done <- c.submitContacts(ctx, model.WebsiteSourceSctgulf, distinctContacts(5), nil, "en")
+	}()
+
+	// Cancel while the first two hold the slots, the others never start
+	<-repository.started
+	<-repository.started
+	cancel()
+	select {
+	case <-done:
+		t.Fatal("submitContacts returned before the started contacts finished")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(repository.release)
+	results := <-done
+
+	for i, result := range results {
+		if result.Index != i {
+			t.Errorf("result %d has index %d", i, result.Index)
+		}
+		if i < 2 {
+			if result.Status != model.SubmissionStatusAccepted {
+				t.Errorf("started contact %d = %+v, want it finished", i, result)
+			}
+			continue
+		}
+		if result.Status != model.SubmissionStatusFailed || len(result.Errors) != 1 || result.Errors[0].Code != keys.ErrCodeCancelled {
+			t.Errorf("contact %d = %+v, want it cancelled", i, result)
+		}
+	}
+}
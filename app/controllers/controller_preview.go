@@ -0,0 +1,42 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/notify"
+	"sct-backend-service/app/spam"
+	"sct-backend-service/graph/model"
+	"sct-backend-service/internal/middleware"
+)
+
+func (impl *GraphQLControllerImpl) PreviewNotification(ctx context.Context, source model.WebsiteSource, channel model.NotificationChannel, input *model.ContactInfoInput) (*model.NotificationPreview, error) {
+	number, err := impl.deps.PhoneParser.Parse(source.String(), input.PhoneNumber)
+	if err != nil {
+		impl.deps.Logger.Debug("Phone number could not be parsed", zap.Error(err))
+	}
+
+	// The enquiry only lives for the preview, it gets an ID and reference number like a real one
+	enquiry, err := newEnquiry(source, input, number, spam.Verdict{}, middleware.Locale(ctx))
+	if err != nil {
+		return nil, fmt.Errorf("error building enquiry: %w", err)
+	}
+
+	rendered, err := impl.deps.Templates.Render(notify.Channel(strings.ToLower(channel.String())), enquiry)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering notification: %w", err)
+	}
+
+	preview := &model.NotificationPreview{
+		Channel: channel,
+		Body:    rendered.Body,
+	}
+	if channel == model.NotificationChannelEmail {
+		preview.Subject = &rendered.Subject
+		preview.Text = &rendered.Text
+	}
+	return preview, nil
+}
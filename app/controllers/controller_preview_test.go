@@ -0,0 +1,51 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/notify"
+	"sct-backend-service/graph/model"
+)
+
+func TestPreviewNotification(t *testing.T) {
+	templates, err := notify.NewTemplates("", "", nil)
+	if err != nil {
+		t.Fatalf("NewTemplates: %v", err)
+	}
+	c := newTestController(t, func(deps *ControllerDeps) {
+		deps.Templates = templates
+	})
+	ctx := context.Background()
+
+	slack, err := c.PreviewNotification(ctx, model.WebsiteSourceSctgulf, model.NotificationChannelSLACk, testContact())
+	if err != nil {
+		t.Fatalf("PreviewNotification slack: %v", err)
+	}
+	if !json.Valid([]byte(slack.Body)) || !strings.Contains(slack.Body, "Jane Doe") {
+		t.Errorf("slack body = %s, want the Slack blocks of the contact", slack.Body)
+	}
+	if slack.Subject != nil || slack.Text != nil {
+		t.Error("a webhook channel preview has an email subject or text")
+	}
+
+	email, err := c.PreviewNotification(ctx, model.WebsiteSourceSctgulf, model.NotificationChannelEmail, testContact())
+	if err != nil {
+		t.Fatalf("PreviewNotification email: %v", err)
+	}
+	if email.Subject == nil || !strings.Contains(*email.Subject, "SCTGULF") || email.Text == nil || !strings.Contains(email.Body, "Jane Doe") {
+		t.Errorf("email preview = %+v, want a subject, HTML and text body", email)
+	}
+
+	// A preview is neither stored nor sent
+	c.deliver(t)
+	if count, err := c.enquiries.Count(ctx, data.EnquiryFilter{}); err != nil || count != 0 {
+		t.Errorf("stored enquiries = %d, %v, want none", count, err)
+	}
+	if kinds := c.sales.kinds(); len(kinds) != 0 {
+		t.Errorf("notifications sent = %v, want none", kinds)
+	}
+}
@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/entities"
+	"sct-backend-service/graph/model"
+	"sct-backend-service/internal/middleware"
+)
+
+// quarantineEnquiry stores a spam enquiry without queueing notifications.
+// The visitor gets the same result as for any accepted enquiry so bots learn nothing.
+func (impl *GraphQLControllerImpl) quarantineEnquiry(ctx context.Context, index int, enquiry *entities.Enquiry) *model.ContactInfoResult {
+	if err := impl.deps.EnquiryRepository.Create(ctx, enquiry); err != nil {
+		impl.deleteAttachments(ctx, enquiry)
+		if ctx.Err() != nil {
+			return cancelledResult(index, enquiry.Locale)
+		}
+		impl.deps.Logger.Error("Error saving quarantined enquiry", zap.Int("index", index), zap.Error(err))
+		return internalErrorResult(index, enquiry.Locale)
+	}
+	impl.deps.Logger.Info("Enquiry quarantined as spam",
+		zap.String("enquiry_id", enquiry.ID),
+		zap.String("source", enquiry.Source),
+		zap.Float64("spam_score", enquiry.SpamScore),
+		zap.Strings("spam_reasons", enquiry.SpamReasons),
+	)
+
+	return acceptedResult(index, enquiry, false)
+}
+
+func (impl *GraphQLControllerImpl) ReleaseEnquiry(ctx context.Context, id, reason string) (*model.Enquiry, error) {
+	enquiry, err := impl.deps.EnquiryRepository.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error loading enquiry: %w", err)
+	}
+
+	change := entities.EnquiryStatusChange{
+		From:      entities.EnquiryStatusSpam,
+		To:        entities.EnquiryStatusNew,
+		Reason:    reason,
+		ChangedAt: time.Now().UTC(),
+	}
+	if userID, ok := middleware.GetUserID(ctx); ok {
+		change.ChangedBy = userID
+	}
+
+	// A quarantined enquiry gets the notifications it missed on its first release,
+	// enquiries that were notified before only get the status change
+	var outbox []*entities.OutboxMessage
+	var released *entities.Enquiry
+	if !enquiry.Notified() {
+		outbox = impl.newEnquiryMessages(enquiry, change.ChangedAt)
+		released, err = impl.deps.EnquiryRepository.Release(ctx, id, change, outbox...)
+	} else if outbox, err = impl.followUpMessages(enquiry, entities.OutboxKindEnquiryStatusChanged, change, change.ChangedAt); err == nil {
+		released, err = impl.deps.EnquiryRepository.UpdateStatus(ctx, id, change, outbox...)
+	}
+	if err != nil {
+		impl.deps.Logger.Error("Error releasing enquiry",
+			zap.String("enquiry_id", id),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("error releasing enquiry: %w", err)
+	}
+
+	impl.deps.Logger.Info("Enquiry released from spam",
+		zap.String("enquiry_id", id),
+		zap.Int("notifications", len(outbox)),
+	)
+	impl.deps.OutboxWorker.Wake()
+	return impl.enquiryModel(released), nil
+}
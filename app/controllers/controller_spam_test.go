@@ -0,0 +1,89 @@
+package controllers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/spam"
+	"sct-backend-service/graph/model"
+)
+
+func TestReleaseEnquiry(t *testing.T) {
+	c := newTestController(t, nil)
+	ctx := context.Background()
+
+	// A quarantined enquiry is stored without notifications
+	result := c.submitContact(ctx, model.WebsiteSourceSctgulf, 0, testContact(), spam.Verdict{Score: 7, Reasons: []string{"gibberish"}, Spam: true}, "en")
+	if result.Status != model.SubmissionStatusAccepted {
+		t.Fatalf("submission = %+v, want it accepted", result)
+	}
+	id := *result.EnquiryID
+	c.deliver(t)
+	if kinds := c.sales.kinds(); len(kinds) != 0 {
+		t.Fatalf("sales team notified of %q while in quarantine", kinds)
+	}
+	if kinds := c.customer.kinds(); len(kinds) != 0 {
+		t.Fatalf("customer notified of %q while in quarantine", kinds)
+	}
+
+	// The first release sends the notifications it missed
+	released, err := c.ReleaseEnquiry(ctx, id, "real customer")
+	if err != nil {
+		t.Fatalf("ReleaseEnquiry: %v", err)
+	}
+	if released.Status != model.EnquiryStatusNew {
+		t.Errorf("status = %s, want NEW", released.Status)
+	}
+	c.deliver(t)
+	if kinds := c.sales.kinds(); !slices.Equal(kinds, []string{entities.OutboxKindEnquiryCreated}) {
+		t.Errorf("sales team notified of %q, want the new enquiry", kinds)
+	}
+	if kinds := c.customer.kinds(); !slices.Equal(kinds, []string{entities.OutboxKindEnquiryAcknowledged}) {
+		t.Errorf("customer notified of %q, want the acknowledgement", kinds)
+	}
+	enquiry, err := c.enquiries.GetByID(ctx, id)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	if !enquiry.Notified() {
+		t.Error("released enquiry is not marked notified")
+	}
+	c.sales.reset()
+	c.customer.reset()
+
+	// Marked as spam by hand, the notified threads hear about it
+	if _, err := c.TransitionEnquiryStatus(ctx, id, model.EnquiryStatusNew, model.EnquiryStatusSpam, "spam after all"); err != nil {
+		t.Fatalf("TransitionEnquiryStatus: %v", err)
+	}
+	c.deliver(t)
+	if kinds := c.sales.kinds(); !slices.Equal(kinds, []string{entities.OutboxKindEnquiryStatusChanged}) {
+		t.Errorf("sales team notified of %q, want the status change", kinds)
+	}
+	c.sales.reset()
+
+	// Released again, the enquiry is only followed up, never announced twice
+	if _, err := c.ReleaseEnquiry(ctx, id, "not spam"); err != nil {
+		t.Fatalf("ReleaseEnquiry: %v", err)
+	}
+	c.deliver(t)
+	if kinds := c.sales.kinds(); !slices.Equal(kinds, []string{entities.OutboxKindEnquiryStatusChanged}) {
+		t.Errorf("sales team notified of %q on the second release, want only the status change", kinds)
+	}
+	if kinds := c.customer.kinds(); len(kinds) != 0 {
+		t.Errorf("customer notified of %q on the second release", kinds)
+	}
+}
+
+func TestReleaseEnquiryNotInQuarantine(t *testing.T) {
+	c := newTestController(t, nil)
+	ctx := context.Background()
+
+	result := c.submitContact(ctx, model.WebsiteSourceSctgulf, 0, testContact(), spam.Verdict{}, "en")
+	if _, err := c.ReleaseEnquiry(ctx, *result.EnquiryID, "not spam"); !errors.Is(err, data.ErrStatusConflict) {
+		t.Errorf("ReleaseEnquiry of a NEW enquiry = %v, want ErrStatusConflict", err)
+	}
+}
@@ -1,13 +1,34 @@
 package controllers
 
 import (
+	"time"
+
 	"go.uber.org/zap"
 
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/blob"
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/notify"
+	"sct-backend-service/app/outbox"
+	"sct-backend-service/app/phone"
 	"sct-backend-service/app/query"
 )
 
 // ControllerDeps holds shared dependencies for controllers
 type ControllerDeps struct {
-	Logger       *zap.Logger
-	QueryBuilder *query.QueryBuilder
+	Logger            *zap.Logger
+	QueryBuilder      *query.QueryBuilder
+	EnquiryRepository data.EnquiryRepository
+	Dispatcher        *notify.Dispatcher
+	OutboxWorker      *outbox.Worker
+	PhoneParser       *phone.Parser
+	Templates         *notify.Templates
+	// Blobs stores the files attached to enquiries, nil when attachments are disabled
+	Blobs blob.Store
+	// AttachmentLinks signs the download links of attachments, nil when attachments are disabled
+	AttachmentLinks *attachment.Links
+	// SubmissionConcurrency bounds the contacts of one request processed in parallel
+	SubmissionConcurrency int
+	// DuplicateWindow is how long an identical submission is linked to the original enquiry, zero disables it
+	DuplicateWindow time.Duration
 }
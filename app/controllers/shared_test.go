@@ -0,0 +1,178 @@
+package controllers
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/blob"
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/notify"
+	"sct-backend-service/app/outbox"
+	"sct-backend-service/app/phone"
+	"sct-backend-service/graph/model"
+)
+
+// recordingNotifier records what it is asked to deliver, follow-ups included
+type recordingNotifier struct {
+	name          string
+	mu            sync.Mutex
+	notifications []notify.Notification
+}
+
+func (n *recordingNotifier) Name() string {
+	return n.name
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = append(n.notifications, notification)
+	return nil
+}
+
+func (n *recordingNotifier) FollowUp(ctx context.Context, notification notify.Notification) error {
+	return n.Notify(ctx, notification)
+}
+
+// kinds returns the kinds of the recorded notifications in delivery order
+func (n *recordingNotifier) kinds() []string {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	kinds := make([]string, len(n.notifications))
+	for i, notification := range n.notifications {
+		kinds[i] = notification.Kind
+	}
+	return kinds
+}
+
+// reset forgets the recorded notifications
+func (n *recordingNotifier) reset() {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	n.notifications = nil
+}
+
+// last returns the last recorded notification
+func (n *recordingNotifier) last(t *testing.T) notify.Notification {
+	t.Helper()
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(n.notifications) == 0 {
+		t.Fatalf("%s received no notification", n.name)
+	}
+	return n.notifications[len(n.notifications)-1]
+}
+
+// recordingAcknowledger stands in for the acknowledgement email to the customer
+type recordingAcknowledger struct {
+	recordingNotifier
+}
+
+func (n *recordingAcknowledger) Acknowledges(enquiry *entities.Enquiry) bool {
+	return true
+}
+
+// testController is a controller on memory repositories with a notifier for the sales team and one for the customer
+type testController struct {
+	*GraphQLControllerImpl
+	enquiries *data.MemoryEnquiryRepository
+	worker    *outbox.Worker
+	sales     *recordingNotifier
+	customer  *recordingAcknowledger
+}
+
+// newTestController creates the controller, configure may change the dependencies before it is built
+func newTestController(t *testing.T, configure func(deps *ControllerDeps)) *testController {
+	t.Helper()
+	sales := &recordingNotifier{name: "sales"}
+	customer := &recordingAcknowledger{recordingNotifier{name: "customer"}}
+	dispatcher, err := notify.NewDispatcher([]notify.Notifier{sales, customer}, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+	parser, err := phone.NewParser(map[string]string{"SCTGULF": "AE"})
+	if err != nil {
+		t.Fatalf("NewParser: %v", err)
+	}
+	blobs, err := blob.NewDiskStore(t.TempDir())
+	if err != nil {
+		t.Fatalf("NewDiskStore: %v", err)
+	}
+
+	outboxRepository := data.NewMemoryOutboxRepository()
+	enquiries := data.NewMemoryEnquiryRepository(outboxRepository)
+	worker := outbox.NewWorker(outbox.Config{
+		BatchSize:      100,
+		MaxAttempts:    3,
+		InitialBackoff: time.Second,
+		MaxBackoff:     time.Minute,
+		Lease:          time.Minute,
+	}, outboxRepository, enquiries, dispatcher, zap.NewNop())
+
+	deps := ControllerDeps{
+		Logger:                zap.NewNop(),
+		EnquiryRepository:     enquiries,
+		Dispatcher:            dispatcher,
+		OutboxWorker:          worker,
+		PhoneParser:           parser,
+		Blobs:                 blobs,
+		AttachmentLinks:       attachment.NewLinks("https://api.example.com", "secret", time.Hour),
+		SubmissionConcurrency: 4,
+		DuplicateWindow:       30 * time.Minute,
+	}
+	if configure != nil {
+		configure(&deps)
+	}
+	return &testController{
+		GraphQLControllerImpl: CreateGraphQLController(deps).(*GraphQLControllerImpl),
+		enquiries:             enquiries,
+		worker:                worker,
+		sales:                 sales,
+		customer:              customer,
+	}
+}
+
+// deliver runs the outbox worker until no message is due
+func (c *testController) deliver(t *testing.T) {
+	t.Helper()
+	for {
+		processed, err := c.worker.RunOnce(context.Background())
+		if err != nil {
+			t.Fatalf("RunOnce: %v", err)
+		}
+		if processed == 0 {
+			return
+		}
+	}
+}
+
+// testContact is a valid contact of the SCTGULF site
+func testContact() *model.ContactInfoInput {
+	return &model.ContactInfoInput{
+		Name:        "Jane Doe",
+		Email:       "jane@example.com",
+		PhoneNumber: "+971 50 123 4567",
+		CompanyName: "Acme",
+		Subject:     "RFQ pumps",
+		Message:     "Please quote 10 pumps",
+	}
+}
+
+// testUpload is a small PDF as it arrives in a multipart request
+func testUpload(name string) *graphql.Upload {
+	content := "%PDF-1.4\n% drawing\n"
+	return &graphql.Upload{
+		File:        strings.NewReader(content),
+		Filename:    name,
+		Size:        int64(len(content)),
+		ContentType: "application/pdf",
+	}
+}
@@ -0,0 +1,131 @@
+package data
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"sct-backend-service/app/entities"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// EnquiryFilter narrows down the enquiries returned by List and Count.
+// Zero values are ignored.
+type EnquiryFilter struct {
+	Source      string
+	Status      string
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// Email matches the customer email exactly, ignoring case
+	Email string
+	// Company matches any part of the company name, ignoring case
+	Company string
+	// Search matches any part of the reference number, name, email, company, subject or message, ignoring case
+	Search string
+}
+
+// EnquiryStats counts the enquiries matching a filter
+type EnquiryStats struct {
+	Total int
+	// ByStatus and BySource leave out statuses and sources without enquiries
+	ByStatus map[string]int
+	BySource map[string]int
+}
+
+// add counts count enquiries of the status and source
+func (s *EnquiryStats) add(status, source string, count int) {
+	s.Total += count
+	s.ByStatus[status] += count
+	s.BySource[source] += count
+}
+
+func newEnquiryStats() *EnquiryStats {
+	return &EnquiryStats{
+		ByStatus: make(map[string]int),
+		BySource: make(map[string]int),
+	}
+}
+
+// Matches reports whether the enquiry satisfies the filter
+func (f EnquiryFilter) Matches(enquiry *entities.Enquiry) bool {
+	if f.Source != "" && enquiry.Source != f.Source {
+		return false
+	}
+	if f.Status != "" && enquiry.Status != f.Status {
+		return false
+	}
+	if f.CreatedFrom != nil && enquiry.CreatedAt.Before(*f.CreatedFrom) {
+		return false
+	}
+	if f.CreatedTo != nil && !enquiry.CreatedAt.Before(*f.CreatedTo) {
+		return false
+	}
+	if f.Email != "" && !strings.EqualFold(enquiry.Email, f.Email) {
+		return false
+	}
+	if f.Company != "" && !containsFold(enquiry.CompanyName, f.Company) {
+		return false
+	}
+	if f.Search != "" &&
+		!containsFold(enquiry.ReferenceNumber, f.Search) &&
+		!containsFold(enquiry.Name, f.Search) &&
+		!containsFold(enquiry.Email, f.Search) &&
+		!containsFold(enquiry.CompanyName, f.Search) &&
+		!containsFold(enquiry.Subject, f.Search) &&
+		!containsFold(enquiry.Message, f.Search) {
+		return false
+	}
+	return true
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
+
+// EnquiryCursor identifies a position in the enquiry list.
+// Enquiries are ordered by creation time, newest first, with the ID as a tie breaker.
+type EnquiryCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// CursorFor returns the cursor pointing at the given enquiry
+func CursorFor(enquiry *entities.Enquiry) EnquiryCursor {
+	return EnquiryCursor{CreatedAt: enquiry.CreatedAt, ID: enquiry.ID}
+}
+
+// IsAfter reports whether the enquiry comes after the cursor in list order
+func (c EnquiryCursor) IsAfter(enquiry *entities.Enquiry) bool {
+	if enquiry.CreatedAt.Equal(c.CreatedAt) {
+		return enquiry.ID < c.ID
+	}
+	return enquiry.CreatedAt.Before(c.CreatedAt)
+}
+
+// Encode returns the opaque string form of the cursor
+func (c EnquiryCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// DecodeEnquiryCursor parses a cursor produced by Encode
+func DecodeEnquiryCursor(s string) (*EnquiryCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+
+	createdAt, id, ok := strings.Cut(string(raw), "|")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, createdAt)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &EnquiryCursor{CreatedAt: t, ID: id}, nil
+}
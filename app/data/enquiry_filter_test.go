@@ -0,0 +1,116 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"sct-backend-service/app/entities"
+)
+
+func TestEnquiryFilterMatches(t *testing.T) {
+	created := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	enquiry := &entities.Enquiry{
+		ReferenceNumber: "GLF-20261018-AB12",
+		Source:          "SCTGULF",
+		Status:          entities.EnquiryStatusNew,
+		Name:            "Jane Doe",
+		Email:           "Jane@Example.com",
+		CompanyName:     "Acme Pumps LLC",
+		Subject:         "RFQ",
+		Message:         "Please quote 10 centrifugal pumps",
+		CreatedAt:       created,
+	}
+	before, after := created.Add(-time.Hour), created.Add(time.Hour)
+
+	tests := []struct {
+		name   string
+		filter EnquiryFilter
+		want   bool
+	}{
+		{"empty filter", EnquiryFilter{}, true},
+		{"source", EnquiryFilter{Source: "SCTGULF"}, true},
+		{"other source", EnquiryFilter{Source: "AGEM"}, false},
+		{"status", EnquiryFilter{Status: entities.EnquiryStatusNew}, true},
+		{"other status", EnquiryFilter{Status: entities.EnquiryStatusSpam}, false},
+		{"created from is inclusive", EnquiryFilter{CreatedFrom: &created}, true},
+		{"created after from", EnquiryFilter{CreatedFrom: &after}, false},
+		{"created to is exclusive", EnquiryFilter{CreatedTo: &created}, false},
+		{"created before to", EnquiryFilter{CreatedFrom: &before, CreatedTo: &after}, true},
+		{"email ignores case", EnquiryFilter{Email: "jane@example.COM"}, true},
+		{"email matches exactly", EnquiryFilter{Email: "jane@example"}, false},
+		{"company matches a part", EnquiryFilter{Company: "pumps"}, true},
+		{"other company", EnquiryFilter{Company: "Globex"}, false},
+		{"search in reference", EnquiryFilter{Search: "glf-20261018"}, true},
+		{"search in message", EnquiryFilter{Search: "CENTRIFUGAL"}, true},
+		{"search without match", EnquiryFilter{Search: "valves"}, false},
+		{"all filters must match", EnquiryFilter{Source: "SCTGULF", Search: "valves"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Matches(enquiry); got != tt.want {
+				t.Errorf("Matches = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEnquiryCursor(t *testing.T) {
+	cursor := EnquiryCursor{CreatedAt: time.Date(2026, 10, 18, 9, 0, 0, 123456789, time.UTC), ID: "b"}
+	decoded, err := DecodeEnquiryCursor(cursor.Encode())
+	if err != nil || !decoded.CreatedAt.Equal(cursor.CreatedAt) || decoded.ID != cursor.ID {
+		t.Fatalf("decoded cursor = %+v, %v, want %+v", decoded, err, cursor)
+	}
+
+	for _, invalid := range []string{"", "not base64!", "bm8tc2VwYXJhdG9y", "MjAyNi0xMC0xOHw"} {
+		if _, err := DecodeEnquiryCursor(invalid); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("DecodeEnquiryCursor(%q) = %v, want ErrInvalidCursor", invalid, err)
+		}
+	}
+
+	// Enquiries created at the same time are ordered by ID
+	same := func(id string) *entities.Enquiry { return &entities.Enquiry{ID: id, CreatedAt: cursor.CreatedAt} }
+	if !cursor.IsAfter(same("a")) || cursor.IsAfter(same("b")) || cursor.IsAfter(same("c")) {
+		t.Error("IsAfter does not break ties by descending ID")
+	}
+	if !cursor.IsAfter(&entities.Enquiry{ID: "z", CreatedAt: cursor.CreatedAt.Add(-time.Nanosecond)}) {
+		t.Error("an older enquiry is not after the cursor")
+	}
+}
+
+func TestMemoryEnquiryRepositoryListPages(t *testing.T) {
+	repository := NewMemoryEnquiryRepository(NewMemoryOutboxRepository())
+	ctx := context.Background()
+	created := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	// Two enquiries share each creation time, so pages have to break ties by ID
+	for i := range 6 {
+		enquiry := &entities.Enquiry{ID: fmt.Sprintf("enquiry-%d", i), Source: "SCTGULF", CreatedAt: created.Add(time.Duration(i/2) * time.Minute)}
+		if err := repository.Create(ctx, enquiry); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	var ids []string
+	var after *EnquiryCursor
+	for {
+		page, err := repository.List(ctx, EnquiryFilter{}, after, 4)
+		if err != nil {
+			t.Fatalf("List: %v", err)
+		}
+		for _, enquiry := range page {
+			ids = append(ids, enquiry.ID)
+		}
+		if len(page) < 4 {
+			break
+		}
+		cursor := CursorFor(page[len(page)-1])
+		after = &cursor
+	}
+
+	want := []string{"enquiry-5", "enquiry-4", "enquiry-3", "enquiry-2", "enquiry-1", "enquiry-0"}
+	if fmt.Sprint(ids) != fmt.Sprint(want) {
+		t.Errorf("pages list %v, want %v", ids, want)
+	}
+}
@@ -0,0 +1,294 @@
+package data
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"sct-backend-service/app/entities"
+)
+
+// MemoryEnquiryRepository keeps enquiries in process memory.
+// It is intended for local development and tests; data is lost on restart.
+type MemoryEnquiryRepository struct {
+	mu        sync.RWMutex
+	enquiries map[string]*entities.Enquiry
+	order     []string
+	outbox    *MemoryOutboxRepository
+
+	// fingerprints holds a channel per locked fingerprint, closed when it is unlocked
+	fingerprintMu sync.Mutex
+	fingerprints  map[string]chan struct{}
+}
+
+// NewMemoryEnquiryRepository creates an empty in-memory enquiry store writing to the given outbox
+func NewMemoryEnquiryRepository(outbox *MemoryOutboxRepository) *MemoryEnquiryRepository {
+	return &MemoryEnquiryRepository{
+		enquiries:    make(map[string]*entities.Enquiry),
+		outbox:       outbox,
+		fingerprints: make(map[string]chan struct{}),
+	}
+}
+
+// Create stores a copy of the enquiry and its outbox messages
+func (r *MemoryEnquiryRepository) Create(ctx context.Context, enquiry *entities.Enquiry, outbox ...*entities.OutboxMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.enquiries[enquiry.ID]; exists {
+		return fmt.Errorf("enquiry %s already exists", enquiry.ID)
+	}
+
+	r.enquiries[enquiry.ID] = copyEnquiry(enquiry)
+	r.order = append(r.order, enquiry.ID)
+	r.outbox.add(outbox)
+	return nil
+}
+
+// GetByID returns a copy of the stored enquiry
+func (r *MemoryEnquiryRepository) GetByID(ctx context.Context, id string) (*entities.Enquiry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	enquiry, ok := r.enquiries[id]
+	if !ok {
+		return nil, ErrEnquiryNotFound
+	}
+	return copyEnquiry(enquiry), nil
+}
+
+// List returns copies of the matching enquiries, newest first
+func (r *MemoryEnquiryRepository) List(ctx context.Context, filter EnquiryFilter, after *EnquiryCursor, limit int) ([]*entities.Enquiry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	matches := make([]*entities.Enquiry, 0)
+	for _, id := range r.order {
+		enquiry := r.enquiries[id]
+		if !filter.Matches(enquiry) {
+			continue
+		}
+		if after != nil && !after.IsAfter(enquiry) {
+			continue
+		}
+		matches = append(matches, enquiry)
+	}
+
+	sort.Slice(matches, func(i, j int) bool {
+		return CursorFor(matches[i]).IsAfter(matches[j])
+	})
+
+	if limit >= 0 && len(matches) > limit {
+		matches = matches[:limit]
+	}
+
+	result := make([]*entities.Enquiry, len(matches))
+	for i, enquiry := range matches {
+		result[i] = copyEnquiry(enquiry)
+	}
+	return result, nil
+}
+
+// Count returns the number of matching enquiries
+func (r *MemoryEnquiryRepository) Count(ctx context.Context, filter EnquiryFilter) (int, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	count := 0
+	for _, enquiry := range r.enquiries {
+		if filter.Matches(enquiry) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Stats counts the matching enquiries by status and source
+func (r *MemoryEnquiryRepository) Stats(ctx context.Context, filter EnquiryFilter) (*EnquiryStats, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	stats := newEnquiryStats()
+	for _, enquiry := range r.enquiries {
+		if filter.Matches(enquiry) {
+			stats.add(enquiry.Status, enquiry.Source, 1)
+		}
+	}
+	return stats, nil
+}
+
+// UpdateStatus applies the status change and stores the outbox messages under the store lock
+func (r *MemoryEnquiryRepository) UpdateStatus(ctx context.Context, id string, change entities.EnquiryStatusChange, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enquiry, ok := r.enquiries[id]
+	if !ok {
+		return nil, ErrEnquiryNotFound
+	}
+	if enquiry.Status != change.From {
+		return nil, ErrStatusConflict
+	}
+
+	enquiry.Status = change.To
+	enquiry.StatusHistory = append(enquiry.StatusHistory, change)
+	enquiry.UpdatedAt = change.ChangedAt
+	r.outbox.add(outbox)
+	return copyEnquiry(enquiry), nil
+}
+
+// Release applies the status change, marks the enquiry notified and stores the outbox messages under the store lock
+func (r *MemoryEnquiryRepository) Release(ctx context.Context, id string, change entities.EnquiryStatusChange, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enquiry, ok := r.enquiries[id]
+	if !ok {
+		return nil, ErrEnquiryNotFound
+	}
+	if enquiry.Status != change.From || enquiry.NotifiedAt != nil {
+		return nil, ErrStatusConflict
+	}
+
+	notifiedAt := change.ChangedAt
+	enquiry.Status = change.To
+	enquiry.StatusHistory = append(enquiry.StatusHistory, change)
+	enquiry.NotifiedAt = &notifiedAt
+	enquiry.UpdatedAt = change.ChangedAt
+	r.outbox.add(outbox)
+	return copyEnquiry(enquiry), nil
+}
+
+// FindDuplicate scans for the newest matching enquiry
+func (r *MemoryEnquiryRepository) FindDuplicate(ctx context.Context, fingerprint string, since time.Time) (*entities.Enquiry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var newest *entities.Enquiry
+	for _, enquiry := range r.enquiries {
+		if enquiry.Fingerprint != fingerprint || enquiry.Status == entities.EnquiryStatusSpam || enquiry.CreatedAt.Before(since) {
+			continue
+		}
+		if newest == nil || enquiry.CreatedAt.After(newest.CreatedAt) {
+			newest = enquiry
+		}
+	}
+	if newest == nil {
+		return nil, ErrEnquiryNotFound
+	}
+	return copyEnquiry(newest), nil
+}
+
+// LockFingerprint waits until no other caller holds the fingerprint and takes it
+func (r *MemoryEnquiryRepository) LockFingerprint(ctx context.Context, fingerprint string) (func(), error) {
+	for {
+		r.fingerprintMu.Lock()
+		held, locked := r.fingerprints[fingerprint]
+		if !locked {
+			released := make(chan struct{})
+			r.fingerprints[fingerprint] = released
+			r.fingerprintMu.Unlock()
+			return func() {
+				r.fingerprintMu.Lock()
+				delete(r.fingerprints, fingerprint)
+				r.fingerprintMu.Unlock()
+				close(released)
+			}, nil
+		}
+		r.fingerprintMu.Unlock()
+
+		select {
+		case <-held:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// AddResubmission appends the resubmission and its files and stores the outbox messages under the store lock
+func (r *MemoryEnquiryRepository) AddResubmission(ctx context.Context, id string, resubmission entities.EnquiryResubmission, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enquiry, ok := r.enquiries[id]
+	if !ok {
+		return nil, ErrEnquiryNotFound
+	}
+
+	enquiry.Resubmissions = append(enquiry.Resubmissions, resubmission)
+	enquiry.Attachments = append(enquiry.Attachments, resubmission.Attachments...)
+	enquiry.UpdatedAt = resubmission.ReceivedAt
+	r.outbox.add(outbox)
+	return copyEnquiry(enquiry), nil
+}
+
+// AddNote appends the note and stores its outbox messages
+func (r *MemoryEnquiryRepository) AddNote(ctx context.Context, id string, note entities.EnquiryNote, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enquiry, ok := r.enquiries[id]
+	if !ok {
+		return nil, ErrEnquiryNotFound
+	}
+
+	enquiry.Notes = append(enquiry.Notes, note)
+	enquiry.UpdatedAt = note.CreatedAt
+	r.outbox.add(outbox)
+	return copyEnquiry(enquiry), nil
+}
+
+// Assign sets the assignee and stores the outbox messages
+func (r *MemoryEnquiryRepository) Assign(ctx context.Context, id string, assignment entities.EnquiryAssignment, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enquiry, ok := r.enquiries[id]
+	if !ok {
+		return nil, ErrEnquiryNotFound
+	}
+
+	enquiry.Assignee = assignment.Assignee
+	enquiry.AssigneeSlackID = assignment.SlackUserID
+	enquiry.UpdatedAt = assignment.AssignedAt
+	r.outbox.add(outbox)
+	return copyEnquiry(enquiry), nil
+}
+
+// SaveSlackMessage records the message, replacing an earlier one of the same destination
+func (r *MemoryEnquiryRepository) SaveSlackMessage(ctx context.Context, id string, message entities.SlackMessage) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	enquiry, ok := r.enquiries[id]
+	if !ok {
+		return ErrEnquiryNotFound
+	}
+
+	messages := make([]entities.SlackMessage, 0, len(enquiry.SlackMessages)+1)
+	for _, existing := range enquiry.SlackMessages {
+		if existing.Destination != message.Destination {
+			messages = append(messages, existing)
+		}
+	}
+	enquiry.SlackMessages = append(messages, message)
+	return nil
+}
+
+func copyEnquiry(enquiry *entities.Enquiry) *entities.Enquiry {
+	clone := *enquiry
+	clone.RawPayload = append([]byte(nil), enquiry.RawPayload...)
+	clone.StatusHistory = append([]entities.EnquiryStatusChange(nil), enquiry.StatusHistory...)
+	clone.Resubmissions = append([]entities.EnquiryResubmission(nil), enquiry.Resubmissions...)
+	clone.SpamReasons = append([]string(nil), enquiry.SpamReasons...)
+	clone.Notes = append([]entities.EnquiryNote(nil), enquiry.Notes...)
+	clone.SlackMessages = append([]entities.SlackMessage(nil), enquiry.SlackMessages...)
+	clone.Attachments = append([]entities.EnquiryAttachment(nil), enquiry.Attachments...)
+	if enquiry.NotifiedAt != nil {
+		notifiedAt := *enquiry.NotifiedAt
+		clone.NotifiedAt = &notifiedAt
+	}
+	return &clone
+}
@@ -0,0 +1,59 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"sct-backend-service/app/entities"
+)
+
+var (
+	// ErrEnquiryNotFound is returned when an enquiry does not exist in the store
+	ErrEnquiryNotFound = errors.New("enquiry not found")
+	// ErrStatusConflict is returned when an enquiry's status changed since it was read
+	ErrStatusConflict = errors.New("enquiry status was changed concurrently")
+	// ErrOutboxMessageNotFound is returned when an outbox message does not exist in the store
+	ErrOutboxMessageNotFound = errors.New("outbox message not found")
+	// ErrIdempotencyKeyNotFound is returned when an idempotency key is not stored
+	ErrIdempotencyKeyNotFound = errors.New("idempotency key not found")
+	// ErrIdempotencyKeyNotOwned is returned when an idempotency key was taken over by another request
+	ErrIdempotencyKeyNotOwned = errors.New("idempotency key is held by another request")
+)
+
+// EnquiryRepository persists enquiries received through the contact form
+type EnquiryRepository interface {
+	// Create stores a new enquiry together with its outbox messages, atomically
+	Create(ctx context.Context, enquiry *entities.Enquiry, outbox ...*entities.OutboxMessage) error
+	// GetByID returns the enquiry with the given ID or ErrEnquiryNotFound
+	GetByID(ctx context.Context, id string) (*entities.Enquiry, error)
+	// List returns up to limit enquiries matching the filter, newest first,
+	// starting after the given cursor when it is not nil
+	List(ctx context.Context, filter EnquiryFilter, after *EnquiryCursor, limit int) ([]*entities.Enquiry, error)
+	// Count returns the number of enquiries matching the filter
+	Count(ctx context.Context, filter EnquiryFilter) (int, error)
+	// Stats counts the enquiries matching the filter by status and source
+	Stats(ctx context.Context, filter EnquiryFilter) (*EnquiryStats, error)
+	// UpdateStatus applies the status change if the enquiry is still in change.From,
+	// appending it to the status history and storing the outbox messages atomically.
+	// It returns ErrStatusConflict otherwise.
+	UpdateStatus(ctx context.Context, id string, change entities.EnquiryStatusChange, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error)
+	// Release applies the status change to an enquiry that was never notified and sets its NotifiedAt
+	// to the time of the change, storing the outbox messages of the missed notifications atomically.
+	// It returns ErrStatusConflict if the status moved on or the enquiry was notified meanwhile.
+	Release(ctx context.Context, id string, change entities.EnquiryStatusChange, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error)
+	// FindDuplicate returns the newest non-spam enquiry with the fingerprint created at or after since,
+	// or ErrEnquiryNotFound
+	FindDuplicate(ctx context.Context, fingerprint string, since time.Time) (*entities.Enquiry, error)
+	// LockFingerprint serializes the duplicate check and write of the fingerprint across all writers of the store,
+	// so identical contacts submitted at the same moment are not both stored. The returned unlock must be called.
+	LockFingerprint(ctx context.Context, fingerprint string) (unlock func(), err error)
+	// AddResubmission records a duplicate submission on the enquiry together with its outbox messages, atomically
+	AddResubmission(ctx context.Context, id string, resubmission entities.EnquiryResubmission, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error)
+	// AddNote appends the note to the enquiry together with its outbox messages, atomically
+	AddNote(ctx context.Context, id string, note entities.EnquiryNote, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error)
+	// Assign sets the enquiry's assignee and stores the outbox messages, atomically
+	Assign(ctx context.Context, id string, assignment entities.EnquiryAssignment, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error)
+	// SaveSlackMessage records the Slack message posted about the enquiry, replacing an earlier one of the same destination
+	SaveSlackMessage(ctx context.Context, id string, message entities.SlackMessage) error
+}
@@ -0,0 +1,628 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/query"
+)
+
+// SQLEnquiryRepository stores enquiries in a SQL database.
+// Queries are produced by the query builder and target PostgreSQL.
+type SQLEnquiryRepository struct {
+	db           *sql.DB
+	queryBuilder *query.QueryBuilder
+}
+
+// NewSQLEnquiryRepository creates a SQL-backed enquiry store
+func NewSQLEnquiryRepository(db *sql.DB, queryBuilder *query.QueryBuilder) *SQLEnquiryRepository {
+	return &SQLEnquiryRepository{
+		db:           db,
+		queryBuilder: queryBuilder,
+	}
+}
+
+// EnsureSchema creates the enquiry tables if they do not exist yet
+func (r *SQLEnquiryRepository) EnsureSchema(ctx context.Context) error {
+	for _, statement := range r.queryBuilder.EnquirySchema() {
+		if _, err := r.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("error applying enquiry schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// Create inserts a new enquiry row and its outbox rows in one transaction
+func (r *SQLEnquiryRepository) Create(ctx context.Context, enquiry *entities.Enquiry, outbox ...*entities.OutboxMessage) error {
+	statusHistory, err := json.Marshal(enquiry.StatusHistory)
+	if err != nil {
+		return fmt.Errorf("error marshalling status history: %w", err)
+	}
+	resubmissions, err := json.Marshal(enquiry.Resubmissions)
+	if err != nil {
+		return fmt.Errorf("error marshalling resubmissions: %w", err)
+	}
+	if enquiry.Resubmissions == nil {
+		resubmissions = []byte("[]")
+	}
+	spamReasons, err := json.Marshal(enquiry.SpamReasons)
+	if err != nil {
+		return fmt.Errorf("error marshalling spam reasons: %w", err)
+	}
+	if enquiry.SpamReasons == nil {
+		spamReasons = []byte("[]")
+	}
+	notes, err := json.Marshal(enquiry.Notes)
+	if err != nil {
+		return fmt.Errorf("error marshalling notes: %w", err)
+	}
+	if enquiry.Notes == nil {
+		notes = []byte("[]")
+	}
+	slackMessages, err := json.Marshal(enquiry.SlackMessages)
+	if err != nil {
+		return fmt.Errorf("error marshalling slack messages: %w", err)
+	}
+	if enquiry.SlackMessages == nil {
+		slackMessages = []byte("[]")
+	}
+	attachments, err := json.Marshal(enquiry.Attachments)
+	if err != nil {
+		return fmt.Errorf("error marshalling attachments: %w", err)
+	}
+	if enquiry.Attachments == nil {
+		attachments = []byte("[]")
+	}
+
+	q, args, err := r.queryBuilder.BuildEnquiryQuery(ctx, "create_enquiry", map[string]interface{}{
+		"id":                enquiry.ID,
+		"reference_number":  enquiry.ReferenceNumber,
+		"source":            enquiry.Source,
+		"name":              enquiry.Name,
+		"email":             enquiry.Email,
+		"phone_number":      enquiry.PhoneNumber,
+		"company_name":      enquiry.CompanyName,
+		"subject":           enquiry.Subject,
+		"message":           enquiry.Message,
+		"country":           enquiry.Country,
+		"raw_payload":       string(enquiry.RawPayload),
+		"status":            enquiry.Status,
+		"status_history":    string(statusHistory),
+		"created_at":        enquiry.CreatedAt,
+		"updated_at":        enquiry.UpdatedAt,
+		"fingerprint":       enquiry.Fingerprint,
+		"resubmissions":     string(resubmissions),
+		"spam_score":        enquiry.SpamScore,
+		"spam_reasons":      string(spamReasons),
+		"phone_e164":        enquiry.PhoneE164,
+		"phone_country":     enquiry.PhoneCountry,
+		"phone_line_type":   enquiry.PhoneLineType,
+		"notes":             string(notes),
+		"slack_messages":    string(slackMessages),
+		"assignee":          enquiry.Assignee,
+		"assignee_slack_id": enquiry.AssigneeSlackID,
+		"locale":            enquiry.Locale,
+		"attachments":       string(attachments),
+		"notified_at":       enquiry.NotifiedAt,
+	})
+	if err != nil {
+		return err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+		return fmt.Errorf("error inserting enquiry: %w", err)
+	}
+
+	if err := r.insertOutboxMessages(ctx, tx, outbox); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("error committing enquiry: %w", err)
+	}
+	return nil
+}
+
+// insertOutboxMessages adds outbox rows inside the caller's transaction
+func (r *SQLEnquiryRepository) insertOutboxMessages(ctx context.Context, tx *sql.Tx, outbox []*entities.OutboxMessage) error {
+	for _, message := range outbox {
+		// An empty payload is stored as NULL, JSONB rejects empty strings
+		var payload interface{}
+		if len(message.Payload) > 0 {
+			payload = string(message.Payload)
+		}
+
+		q, args, err := r.queryBuilder.BuildOutboxQuery(ctx, "create_outbox_message", map[string]interface{}{
+			"id":              message.ID,
+			"enquiry_id":      message.EnquiryID,
+			"kind":            message.Kind,
+			"destination":     message.Destination,
+			"status":          message.Status,
+			"next_attempt_at": message.NextAttemptAt,
+			"created_at":      message.CreatedAt,
+			"updated_at":      message.UpdatedAt,
+			"payload":         payload,
+		})
+		if err != nil {
+			return err
+		}
+		if _, err := tx.ExecContext(ctx, q, args...); err != nil {
+			return fmt.Errorf("error inserting outbox message: %w", err)
+		}
+	}
+	return nil
+}
+
+// GetByID loads a single enquiry row
+func (r *SQLEnquiryRepository) GetByID(ctx context.Context, id string) (*entities.Enquiry, error) {
+	q, args, err := r.queryBuilder.BuildEnquiryQuery(ctx, "get_enquiry", map[string]interface{}{
+		"id": id,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	enquiry, err := scanEnquiry(r.db.QueryRowContext(ctx, q, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEnquiryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading enquiry: %w", err)
+	}
+	return enquiry, nil
+}
+
+// List loads the matching enquiry rows, newest first
+func (r *SQLEnquiryRepository) List(ctx context.Context, filter EnquiryFilter, after *EnquiryCursor, limit int) ([]*entities.Enquiry, error) {
+	params := filterParams(filter)
+	params["limit"] = limit
+	if after != nil {
+		params["after_created_at"] = after.CreatedAt
+		params["after_id"] = after.ID
+	}
+
+	q, args, err := r.queryBuilder.BuildEnquiryQuery(ctx, "list_enquiries", params)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error listing enquiries: %w", err)
+	}
+	defer rows.Close()
+
+	enquiries := make([]*entities.Enquiry, 0)
+	for rows.Next() {
+		enquiry, err := scanEnquiry(rows)
+		if err != nil {
+			return nil, fmt.Errorf("error reading enquiry: %w", err)
+		}
+		enquiries = append(enquiries, enquiry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error listing enquiries: %w", err)
+	}
+	return enquiries, nil
+}
+
+// Count returns the number of matching enquiry rows
+func (r *SQLEnquiryRepository) Count(ctx context.Context, filter EnquiryFilter) (int, error) {
+	q, args, err := r.queryBuilder.BuildEnquiryQuery(ctx, "count_enquiries", filterParams(filter))
+	if err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err := r.db.QueryRowContext(ctx, q, args...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("error counting enquiries: %w", err)
+	}
+	return count, nil
+}
+
+// Stats counts the matching enquiries with one grouped query
+func (r *SQLEnquiryRepository) Stats(ctx context.Context, filter EnquiryFilter) (*EnquiryStats, error) {
+	q, args, err := r.queryBuilder.BuildEnquiryQuery(ctx, "enquiry_stats", filterParams(filter))
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error counting enquiries: %w", err)
+	}
+	defer rows.Close()
+
+	stats := newEnquiryStats()
+	for rows.Next() {
+		var status, source string
+		var count int
+		if err := rows.Scan(&status, &source, &count); err != nil {
+			return nil, fmt.Errorf("error scanning enquiry stats: %w", err)
+		}
+		stats.add(status, source, count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error counting enquiries: %w", err)
+	}
+	return stats, nil
+}
+
+// UpdateStatus applies the status change with a conditional update on the current status,
+// inserting the outbox rows in the same transaction
+func (r *SQLEnquiryRepository) UpdateStatus(ctx context.Context, id string, change entities.EnquiryStatusChange, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error) {
+	return r.changeStatus(ctx, "update_enquiry_status", id, change, outbox)
+}
+
+// Release applies the status change with a conditional update on the current status and on
+// notified_at still being NULL, inserting the outbox rows in the same transaction
+func (r *SQLEnquiryRepository) Release(ctx context.Context, id string, change entities.EnquiryStatusChange, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error) {
+	return r.changeStatus(ctx, "release_enquiry", id, change, outbox)
+}
+
+// changeStatus runs one of the conditional status updates and inserts the outbox rows in the same transaction
+func (r *SQLEnquiryRepository) changeStatus(ctx context.Context, operation, id string, change entities.EnquiryStatusChange, outbox []*entities.OutboxMessage) (*entities.Enquiry, error) {
+	entry, err := json.Marshal([]entities.EnquiryStatusChange{change})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling status change: %w", err)
+	}
+
+	q, args, err := r.queryBuilder.BuildEnquiryQuery(ctx, operation, map[string]interface{}{
+		"id":          id,
+		"from_status": change.From,
+		"to_status":   change.To,
+		"history":     string(entry),
+		"updated_at":  change.ChangedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	enquiry, err := scanEnquiry(tx.QueryRowContext(ctx, q, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		// Either the enquiry does not exist or its status moved on
+		if _, getErr := r.GetByID(ctx, id); getErr != nil {
+			return nil, getErr
+		}
+		return nil, ErrStatusConflict
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error updating enquiry status: %w", err)
+	}
+
+	if err := r.insertOutboxMessages(ctx, tx, outbox); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing status change: %w", err)
+	}
+	return enquiry, nil
+}
+
+// FindDuplicate loads the newest non-spam enquiry with the fingerprint created since the given time
+func (r *SQLEnquiryRepository) FindDuplicate(ctx context.Context, fingerprint string, since time.Time) (*entities.Enquiry, error) {
+	q, args, err := r.queryBuilder.BuildEnquiryQuery(ctx, "find_duplicate_enquiry", map[string]interface{}{
+		"fingerprint": fingerprint,
+		"since":       since,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	enquiry, err := scanEnquiry(r.db.QueryRowContext(ctx, q, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEnquiryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error finding duplicate enquiry: %w", err)
+	}
+	return enquiry, nil
+}
+
+// LockFingerprint takes a Postgres session advisory lock on the fingerprint, so instances sharing
+// the database serialize on it. The lock holds a pool connection until it is released.
+func (r *SQLEnquiryRepository) LockFingerprint(ctx context.Context, fingerprint string) (func(), error) {
+	lockQuery, lockArgs, err := r.queryBuilder.BuildEnquiryQuery(ctx, "lock_enquiry_fingerprint", map[string]interface{}{"fingerprint": fingerprint})
+	if err != nil {
+		return nil, err
+	}
+	unlockQuery, unlockArgs, err := r.queryBuilder.BuildEnquiryQuery(ctx, "unlock_enquiry_fingerprint", map[string]interface{}{"fingerprint": fingerprint})
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error getting connection for fingerprint lock: %w", err)
+	}
+	if _, err := conn.ExecContext(ctx, lockQuery, lockArgs...); err != nil {
+		discardConn(conn)
+		return nil, fmt.Errorf("error locking fingerprint: %w", err)
+	}
+
+	return func() {
+		// The lock is released even if the request was cancelled meanwhile
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), unlockQuery, unlockArgs...); err != nil {
+			// A session that may still hold the lock must not go back to the pool
+			discardConn(conn)
+			return
+		}
+		conn.Close()
+	}, nil
+}
+
+// discardConn closes the connection instead of returning it to the pool,
+// which ends its session and with it any advisory lock it holds
+func discardConn(conn *sql.Conn) {
+	conn.Raw(func(driverConn interface{}) error {
+		return driver.ErrBadConn
+	})
+	conn.Close()
+}
+
+// AddResubmission appends the resubmission and its files and inserts its outbox rows in one transaction
+func (r *SQLEnquiryRepository) AddResubmission(ctx context.Context, id string, resubmission entities.EnquiryResubmission, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error) {
+	entry, err := json.Marshal([]entities.EnquiryResubmission{resubmission})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling resubmission: %w", err)
+	}
+	attachments, err := json.Marshal(resubmission.Attachments)
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling attachments: %w", err)
+	}
+	if resubmission.Attachments == nil {
+		attachments = []byte("[]")
+	}
+
+	q, args, err := r.queryBuilder.BuildEnquiryQuery(ctx, "add_enquiry_resubmission", map[string]interface{}{
+		"id":           id,
+		"resubmission": string(entry),
+		"attachments":  string(attachments),
+		"updated_at":   resubmission.ReceivedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	enquiry, err := scanEnquiry(tx.QueryRowContext(ctx, q, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEnquiryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error adding resubmission: %w", err)
+	}
+
+	if err := r.insertOutboxMessages(ctx, tx, outbox); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing resubmission: %w", err)
+	}
+	return enquiry, nil
+}
+
+// AddNote appends the note and inserts its outbox rows in one transaction
+func (r *SQLEnquiryRepository) AddNote(ctx context.Context, id string, note entities.EnquiryNote, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error) {
+	entry, err := json.Marshal([]entities.EnquiryNote{note})
+	if err != nil {
+		return nil, fmt.Errorf("error marshalling note: %w", err)
+	}
+
+	q, args, err := r.queryBuilder.BuildEnquiryQuery(ctx, "add_enquiry_note", map[string]interface{}{
+		"id":         id,
+		"note":       string(entry),
+		"updated_at": note.CreatedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	enquiry, err := scanEnquiry(tx.QueryRowContext(ctx, q, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEnquiryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error adding note: %w", err)
+	}
+
+	if err := r.insertOutboxMessages(ctx, tx, outbox); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing note: %w", err)
+	}
+	return enquiry, nil
+}
+
+// Assign sets the assignee and inserts the outbox rows in one transaction
+func (r *SQLEnquiryRepository) Assign(ctx context.Context, id string, assignment entities.EnquiryAssignment, outbox ...*entities.OutboxMessage) (*entities.Enquiry, error) {
+	q, args, err := r.queryBuilder.BuildEnquiryQuery(ctx, "assign_enquiry", map[string]interface{}{
+		"id":                id,
+		"assignee":          assignment.Assignee,
+		"assignee_slack_id": assignment.SlackUserID,
+		"updated_at":        assignment.AssignedAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("error starting transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	enquiry, err := scanEnquiry(tx.QueryRowContext(ctx, q, args...))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrEnquiryNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error assigning enquiry: %w", err)
+	}
+
+	if err := r.insertOutboxMessages(ctx, tx, outbox); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("error committing assignment: %w", err)
+	}
+	return enquiry, nil
+}
+
+// SaveSlackMessage stores the message in place of an earlier one of the same destination
+func (r *SQLEnquiryRepository) SaveSlackMessage(ctx context.Context, id string, message entities.SlackMessage) error {
+	entry, err := json.Marshal([]entities.SlackMessage{message})
+	if err != nil {
+		return fmt.Errorf("error marshalling slack message: %w", err)
+	}
+
+	q, args, err := r.queryBuilder.BuildEnquiryQuery(ctx, "save_enquiry_slack_message", map[string]interface{}{
+		"id":          id,
+		"destination": message.Destination,
+		"message":     string(entry),
+	})
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("error saving slack message: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err == nil && rows == 0 {
+		return ErrEnquiryNotFound
+	}
+	return nil
+}
+
+// filterParams converts a filter into query builder params, skipping zero values
+func filterParams(filter EnquiryFilter) map[string]interface{} {
+	params := map[string]interface{}{}
+	if filter.Source != "" {
+		params["source"] = filter.Source
+	}
+	if filter.Status != "" {
+		params["status"] = filter.Status
+	}
+	if filter.CreatedFrom != nil {
+		params["created_from"] = *filter.CreatedFrom
+	}
+	if filter.CreatedTo != nil {
+		params["created_to"] = *filter.CreatedTo
+	}
+	if filter.Email != "" {
+		params["email"] = filter.Email
+	}
+	if filter.Company != "" {
+		params["company"] = filter.Company
+	}
+	if filter.Search != "" {
+		params["search"] = filter.Search
+	}
+	return params
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanEnquiry(row rowScanner) (*entities.Enquiry, error) {
+	var enquiry entities.Enquiry
+	var rawPayload, statusHistory, resubmissions, spamReasons, notes, slackMessages, attachments string
+	// Enquiries stored before reference numbers were introduced have none
+	var referenceNumber sql.NullString
+	var notifiedAt sql.NullTime
+	err := row.Scan(
+		&enquiry.ID,
+		&referenceNumber,
+		&enquiry.Source,
+		&enquiry.Name,
+		&enquiry.Email,
+		&enquiry.PhoneNumber,
+		&enquiry.CompanyName,
+		&enquiry.Subject,
+		&enquiry.Message,
+		&enquiry.Country,
+		&rawPayload,
+		&enquiry.Status,
+		&statusHistory,
+		&enquiry.CreatedAt,
+		&enquiry.UpdatedAt,
+		&enquiry.Fingerprint,
+		&resubmissions,
+		&enquiry.SpamScore,
+		&spamReasons,
+		&enquiry.PhoneE164,
+		&enquiry.PhoneCountry,
+		&enquiry.PhoneLineType,
+		&notes,
+		&slackMessages,
+		&enquiry.Assignee,
+		&enquiry.AssigneeSlackID,
+		&enquiry.Locale,
+		&attachments,
+		&notifiedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	enquiry.ReferenceNumber = referenceNumber.String
+	if notifiedAt.Valid {
+		enquiry.NotifiedAt = &notifiedAt.Time
+	}
+	enquiry.RawPayload = []byte(rawPayload)
+	if err := json.Unmarshal([]byte(statusHistory), &enquiry.StatusHistory); err != nil {
+		return nil, fmt.Errorf("error unmarshalling status history: %w", err)
+	}
+	if err := json.Unmarshal([]byte(resubmissions), &enquiry.Resubmissions); err != nil {
+		return nil, fmt.Errorf("error unmarshalling resubmissions: %w", err)
+	}
+	if err := json.Unmarshal([]byte(spamReasons), &enquiry.SpamReasons); err != nil {
+		return nil, fmt.Errorf("error unmarshalling spam reasons: %w", err)
+	}
+	if err := json.Unmarshal([]byte(notes), &enquiry.Notes); err != nil {
+		return nil, fmt.Errorf("error unmarshalling notes: %w", err)
+	}
+	if err := json.Unmarshal([]byte(slackMessages), &enquiry.SlackMessages); err != nil {
+		return nil, fmt.Errorf("error unmarshalling slack messages: %w", err)
+	}
+	if err := json.Unmarshal([]byte(attachments), &enquiry.Attachments); err != nil {
+		return nil, fmt.Errorf("error unmarshalling attachments: %w", err)
+	}
+	return &enquiry, nil
+}
@@ -0,0 +1,88 @@
+package data
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"sct-backend-service/app/entities"
+)
+
+// MemoryIdempotencyRepository keeps idempotency records in process memory
+type MemoryIdempotencyRepository struct {
+	mu      sync.Mutex
+	records map[string]*entities.IdempotencyRecord
+}
+
+// NewMemoryIdempotencyRepository creates an empty in-memory idempotency store
+func NewMemoryIdempotencyRepository() *MemoryIdempotencyRepository {
+	return &MemoryIdempotencyRepository{
+		records: make(map[string]*entities.IdempotencyRecord),
+	}
+}
+
+// Reserve claims the key under the store lock
+func (r *MemoryIdempotencyRepository) Reserve(ctx context.Context, record *entities.IdempotencyRecord, now time.Time) (*entities.IdempotencyRecord, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Expired records are dropped lazily whenever a key is reserved
+	for key, existing := range r.records {
+		if !existing.ExpiresAt.After(now) {
+			delete(r.records, key)
+		}
+	}
+
+	if existing, ok := r.records[record.Key]; ok {
+		abandoned := existing.Status == entities.IdempotencyStatusInProgress && !existing.LockedUntil.After(now)
+		if !abandoned {
+			return copyIdempotencyRecord(existing), false, nil
+		}
+	}
+
+	r.records[record.Key] = copyIdempotencyRecord(record)
+	return copyIdempotencyRecord(record), true, nil
+}
+
+// Complete stores the response of a key still reserved by owner
+func (r *MemoryIdempotencyRepository) Complete(ctx context.Context, key, owner string, response []byte) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[key]
+	if !ok || record.Owner != owner || record.Status != entities.IdempotencyStatusInProgress {
+		return ErrIdempotencyKeyNotOwned
+	}
+	record.Status = entities.IdempotencyStatusCompleted
+	record.Response = append([]byte(nil), response...)
+	return nil
+}
+
+// Release drops a reservation still held by owner
+func (r *MemoryIdempotencyRepository) Release(ctx context.Context, key, owner string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if record, ok := r.records[key]; ok && record.Owner == owner && record.Status == entities.IdempotencyStatusInProgress {
+		delete(r.records, key)
+	}
+	return nil
+}
+
+// Get returns a copy of the record
+func (r *MemoryIdempotencyRepository) Get(ctx context.Context, key string) (*entities.IdempotencyRecord, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	record, ok := r.records[key]
+	if !ok {
+		return nil, ErrIdempotencyKeyNotFound
+	}
+	return copyIdempotencyRecord(record), nil
+}
+
+func copyIdempotencyRecord(record *entities.IdempotencyRecord) *entities.IdempotencyRecord {
+	clone := *record
+	clone.Response = append([]byte(nil), record.Response...)
+	return &clone
+}
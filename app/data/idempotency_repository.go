@@ -0,0 +1,23 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"sct-backend-service/app/entities"
+)
+
+// IdempotencyRepository stores the responses of requests made with an idempotency key
+type IdempotencyRepository interface {
+	// Reserve claims the key for a new request. It succeeds when the key is unused, expired,
+	// or held by an abandoned request; otherwise it returns the existing record and false.
+	Reserve(ctx context.Context, record *entities.IdempotencyRecord, now time.Time) (*entities.IdempotencyRecord, bool, error)
+	// Complete stores the response of a key reserved by owner. It returns ErrIdempotencyKeyNotOwned
+	// when the reservation was taken over meanwhile, the response of the new holder is kept.
+	Complete(ctx context.Context, key, owner string, response []byte) error
+	// Release drops a reservation of owner so the request can be retried.
+	// A reservation taken over by another request is left alone.
+	Release(ctx context.Context, key, owner string) error
+	// Get returns the record for the key or ErrIdempotencyKeyNotFound
+	Get(ctx context.Context, key string) (*entities.IdempotencyRecord, error)
+}
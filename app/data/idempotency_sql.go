@@ -0,0 +1,149 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/query"
+)
+
+// SQLIdempotencyRepository stores idempotency records in a SQL database,
+// so replays work across instances
+type SQLIdempotencyRepository struct {
+	db           *sql.DB
+	queryBuilder *query.QueryBuilder
+}
+
+// NewSQLIdempotencyRepository creates a SQL-backed idempotency store
+func NewSQLIdempotencyRepository(db *sql.DB, queryBuilder *query.QueryBuilder) *SQLIdempotencyRepository {
+	return &SQLIdempotencyRepository{
+		db:           db,
+		queryBuilder: queryBuilder,
+	}
+}
+
+// EnsureSchema creates the idempotency table if it does not exist yet
+func (r *SQLIdempotencyRepository) EnsureSchema(ctx context.Context) error {
+	for _, statement := range r.queryBuilder.IdempotencySchema() {
+		if _, err := r.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("error applying idempotency schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// Reserve claims the key with a single upsert
+func (r *SQLIdempotencyRepository) Reserve(ctx context.Context, record *entities.IdempotencyRecord, now time.Time) (*entities.IdempotencyRecord, bool, error) {
+	// Expired records are dropped lazily whenever a key is reserved
+	if _, err := r.exec(ctx, "delete_expired_idempotency_keys", map[string]interface{}{"now": now}); err != nil {
+		return nil, false, err
+	}
+
+	q, args, err := r.queryBuilder.BuildIdempotencyQuery(ctx, "reserve_idempotency_key", map[string]interface{}{
+		"key":          record.Key,
+		"request_hash": record.RequestHash,
+		"owner":        record.Owner,
+		"locked_until": record.LockedUntil,
+		"expires_at":   record.ExpiresAt,
+		"created_at":   record.CreatedAt,
+		"now":          now,
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	for {
+		var key string
+		err = r.db.QueryRowContext(ctx, q, args...).Scan(&key)
+		if err == nil {
+			return record, true, nil
+		}
+		if !errors.Is(err, sql.ErrNoRows) {
+			return nil, false, fmt.Errorf("error reserving idempotency key: %w", err)
+		}
+
+		existing, err := r.Get(ctx, record.Key)
+		if !errors.Is(err, ErrIdempotencyKeyNotFound) {
+			return existing, false, err
+		}
+		// Released between the upsert and the read, the key is free again
+		if err := ctx.Err(); err != nil {
+			return nil, false, err
+		}
+	}
+}
+
+// Complete stores the response of a key still reserved by owner
+func (r *SQLIdempotencyRepository) Complete(ctx context.Context, key, owner string, response []byte) error {
+	updated, err := r.exec(ctx, "complete_idempotency_key", map[string]interface{}{
+		"key":      key,
+		"owner":    owner,
+		"response": response,
+	})
+	if err != nil {
+		return err
+	}
+	if updated == 0 {
+		return ErrIdempotencyKeyNotOwned
+	}
+	return nil
+}
+
+// Release drops a reservation still held by owner
+func (r *SQLIdempotencyRepository) Release(ctx context.Context, key, owner string) error {
+	_, err := r.exec(ctx, "release_idempotency_key", map[string]interface{}{
+		"key":   key,
+		"owner": owner,
+	})
+	return err
+}
+
+// Get loads the record for the key
+func (r *SQLIdempotencyRepository) Get(ctx context.Context, key string) (*entities.IdempotencyRecord, error) {
+	q, args, err := r.queryBuilder.BuildIdempotencyQuery(ctx, "get_idempotency_key", map[string]interface{}{
+		"key": key,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var record entities.IdempotencyRecord
+	err = r.db.QueryRowContext(ctx, q, args...).Scan(
+		&record.Key,
+		&record.RequestHash,
+		&record.Status,
+		&record.Owner,
+		&record.Response,
+		&record.LockedUntil,
+		&record.ExpiresAt,
+		&record.CreatedAt,
+	)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrIdempotencyKeyNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error loading idempotency key: %w", err)
+	}
+	return &record, nil
+}
+
+// exec runs the statement and returns the number of rows it changed
+func (r *SQLIdempotencyRepository) exec(ctx context.Context, operation string, params map[string]interface{}) (int64, error) {
+	q, args, err := r.queryBuilder.BuildIdempotencyQuery(ctx, operation, params)
+	if err != nil {
+		return 0, err
+	}
+	result, err := r.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return 0, fmt.Errorf("error updating idempotency key: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("error updating idempotency key: %w", err)
+	}
+	return affected, nil
+}
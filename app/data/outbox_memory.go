@@ -0,0 +1,122 @@
+package data
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"sct-backend-service/app/entities"
+)
+
+// MemoryOutboxRepository keeps outbox messages in process memory
+type MemoryOutboxRepository struct {
+	mu       sync.Mutex
+	messages map[string]*entities.OutboxMessage
+}
+
+// NewMemoryOutboxRepository creates an empty in-memory outbox
+func NewMemoryOutboxRepository() *MemoryOutboxRepository {
+	return &MemoryOutboxRepository{
+		messages: make(map[string]*entities.OutboxMessage),
+	}
+}
+
+// add stores copies of the messages, called by the enquiry repository
+func (r *MemoryOutboxRepository) add(messages []*entities.OutboxMessage) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, message := range messages {
+		clone := *message
+		r.messages[message.ID] = &clone
+	}
+}
+
+// ClaimDue locks the due messages, oldest first
+func (r *MemoryOutboxRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*entities.OutboxMessage, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	due := make([]*entities.OutboxMessage, 0)
+	for _, message := range r.messages {
+		if message.Status != entities.OutboxStatusPending || message.NextAttemptAt.After(now) {
+			continue
+		}
+		if message.LockedUntil != nil && message.LockedUntil.After(now) {
+			continue
+		}
+		due = append(due, message)
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if len(due) > limit {
+		due = due[:limit]
+	}
+
+	lockedUntil := now.Add(lease)
+	claimed := make([]*entities.OutboxMessage, len(due))
+	for i, message := range due {
+		message.LockedUntil = &lockedUntil
+		message.UpdatedAt = now
+		clone := *message
+		claimed[i] = &clone
+	}
+	return claimed, nil
+}
+
+// MarkDelivered records a successful delivery
+func (r *MemoryOutboxRepository) MarkDelivered(ctx context.Context, id string, at time.Time) error {
+	return r.update(id, func(message *entities.OutboxMessage) {
+		message.Status = entities.OutboxStatusDelivered
+		message.LastError = ""
+	}, at)
+}
+
+// Reschedule records a failed attempt and schedules the next one
+func (r *MemoryOutboxRepository) Reschedule(ctx context.Context, id string, lastError string, nextAttemptAt time.Time, at time.Time) error {
+	return r.update(id, func(message *entities.OutboxMessage) {
+		message.LastError = lastError
+		message.NextAttemptAt = nextAttemptAt
+	}, at)
+}
+
+// MarkDead moves the message to the dead-letter state
+func (r *MemoryOutboxRepository) MarkDead(ctx context.Context, id string, lastError string, at time.Time) error {
+	return r.update(id, func(message *entities.OutboxMessage) {
+		message.Status = entities.OutboxStatusDead
+		message.LastError = lastError
+	}, at)
+}
+
+// HasPending reports whether a matching message is still pending
+func (r *MemoryOutboxRepository) HasPending(ctx context.Context, enquiryID, kind, destination string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, message := range r.messages {
+		if message.EnquiryID == enquiryID && message.Kind == kind && message.Destination == destination &&
+			message.Status == entities.OutboxStatusPending {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// update counts the attempt, releases the lock and applies the change
+func (r *MemoryOutboxRepository) update(id string, apply func(message *entities.OutboxMessage), at time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	message, ok := r.messages[id]
+	if !ok {
+		return ErrOutboxMessageNotFound
+	}
+	message.Attempts++
+	message.LockedUntil = nil
+	message.UpdatedAt = at
+	apply(message)
+	return nil
+}
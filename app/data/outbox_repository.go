@@ -0,0 +1,24 @@
+package data
+
+import (
+	"context"
+	"time"
+
+	"sct-backend-service/app/entities"
+)
+
+// OutboxRepository gives the outbox worker access to pending notifications.
+// Messages are added together with their enquiry through EnquiryRepository.Create.
+type OutboxRepository interface {
+	// ClaimDue locks up to limit pending messages that are due at now for the lease duration,
+	// so concurrent workers never deliver the same message twice
+	ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*entities.OutboxMessage, error)
+	// MarkDelivered records a successful delivery
+	MarkDelivered(ctx context.Context, id string, at time.Time) error
+	// Reschedule records a failed attempt and schedules the next one
+	Reschedule(ctx context.Context, id string, lastError string, nextAttemptAt time.Time, at time.Time) error
+	// MarkDead records a failed attempt and moves the message to the dead-letter state
+	MarkDead(ctx context.Context, id string, lastError string, at time.Time) error
+	// HasPending reports whether a message of the kind for the enquiry and destination still waits for delivery
+	HasPending(ctx context.Context, enquiryID, kind, destination string) (bool, error)
+}
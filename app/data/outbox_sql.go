@@ -0,0 +1,146 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/query"
+)
+
+// SQLOutboxRepository stores outbox messages in the same database as the enquiries
+type SQLOutboxRepository struct {
+	db           *sql.DB
+	queryBuilder *query.QueryBuilder
+}
+
+// NewSQLOutboxRepository creates a SQL-backed outbox
+func NewSQLOutboxRepository(db *sql.DB, queryBuilder *query.QueryBuilder) *SQLOutboxRepository {
+	return &SQLOutboxRepository{
+		db:           db,
+		queryBuilder: queryBuilder,
+	}
+}
+
+// EnsureSchema creates the outbox table if it does not exist yet
+func (r *SQLOutboxRepository) EnsureSchema(ctx context.Context) error {
+	for _, statement := range r.queryBuilder.OutboxSchema() {
+		if _, err := r.db.ExecContext(ctx, statement); err != nil {
+			return fmt.Errorf("error applying outbox schema: %w", err)
+		}
+	}
+	return nil
+}
+
+// ClaimDue locks the due messages for the lease duration
+func (r *SQLOutboxRepository) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*entities.OutboxMessage, error) {
+	q, args, err := r.queryBuilder.BuildOutboxQuery(ctx, "claim_due_outbox_messages", map[string]interface{}{
+		"now":          now,
+		"locked_until": now.Add(lease),
+		"limit":        limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.QueryContext(ctx, q, args...)
+	if err != nil {
+		return nil, fmt.Errorf("error claiming outbox messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]*entities.OutboxMessage, 0)
+	for rows.Next() {
+		var message entities.OutboxMessage
+		var lockedUntil sql.NullTime
+		err := rows.Scan(
+			&message.ID,
+			&message.EnquiryID,
+			&message.Kind,
+			&message.Destination,
+			&message.Status,
+			&message.Attempts,
+			&message.LastError,
+			&message.NextAttemptAt,
+			&lockedUntil,
+			&message.CreatedAt,
+			&message.UpdatedAt,
+			&message.Payload,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("error reading outbox message: %w", err)
+		}
+		if lockedUntil.Valid {
+			message.LockedUntil = &lockedUntil.Time
+		}
+		messages = append(messages, &message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error claiming outbox messages: %w", err)
+	}
+	return messages, nil
+}
+
+// MarkDelivered records a successful delivery
+func (r *SQLOutboxRepository) MarkDelivered(ctx context.Context, id string, at time.Time) error {
+	return r.exec(ctx, "mark_outbox_message_delivered", map[string]interface{}{
+		"id":         id,
+		"updated_at": at,
+	})
+}
+
+// Reschedule records a failed attempt and schedules the next one
+func (r *SQLOutboxRepository) Reschedule(ctx context.Context, id string, lastError string, nextAttemptAt time.Time, at time.Time) error {
+	return r.exec(ctx, "reschedule_outbox_message", map[string]interface{}{
+		"id":              id,
+		"last_error":      lastError,
+		"next_attempt_at": nextAttemptAt,
+		"updated_at":      at,
+	})
+}
+
+// MarkDead moves the message to the dead-letter state
+func (r *SQLOutboxRepository) MarkDead(ctx context.Context, id string, lastError string, at time.Time) error {
+	return r.exec(ctx, "mark_outbox_message_dead", map[string]interface{}{
+		"id":         id,
+		"last_error": lastError,
+		"updated_at": at,
+	})
+}
+
+// HasPending reports whether a matching message is still pending
+func (r *SQLOutboxRepository) HasPending(ctx context.Context, enquiryID, kind, destination string) (bool, error) {
+	q, args, err := r.queryBuilder.BuildOutboxQuery(ctx, "has_pending_outbox_message", map[string]interface{}{
+		"enquiry_id":  enquiryID,
+		"kind":        kind,
+		"destination": destination,
+	})
+	if err != nil {
+		return false, err
+	}
+
+	var pending bool
+	if err := r.db.QueryRowContext(ctx, q, args...).Scan(&pending); err != nil {
+		return false, fmt.Errorf("error checking pending outbox messages: %w", err)
+	}
+	return pending, nil
+}
+
+// exec runs an update that must affect exactly one message
+func (r *SQLOutboxRepository) exec(ctx context.Context, operation string, params map[string]interface{}) error {
+	q, args, err := r.queryBuilder.BuildOutboxQuery(ctx, operation, params)
+	if err != nil {
+		return err
+	}
+
+	result, err := r.db.ExecContext(ctx, q, args...)
+	if err != nil {
+		return fmt.Errorf("error updating outbox message: %w", err)
+	}
+	if affected, err := result.RowsAffected(); err == nil && affected == 0 {
+		return ErrOutboxMessageNotFound
+	}
+	return nil
+}
@@ -0,0 +1,259 @@
+package entities
+
+import (
+	"time"
+
+	"sct-backend-service/app/phone"
+	"sct-backend-service/graph/model"
+)
+
+// Enquiry statuses, see workflow for the allowed transitions
+const (
+	EnquiryStatusNew       = "NEW"
+	EnquiryStatusContacted = "CONTACTED"
+	EnquiryStatusQualified = "QUALIFIED"
+	EnquiryStatusWon       = "WON"
+	EnquiryStatusLost      = "LOST"
+	EnquiryStatusSpam      = "SPAM"
+)
+
+// Enquiry represents a contact form submission received from one of the websites
+type Enquiry struct {
+	ID string
+	// ReferenceNumber is the short reference shown to the customer and the sales team
+	ReferenceNumber string
+	Source          string
+	Name            string
+	Email           string
+	PhoneNumber     string
+	// PhoneE164, PhoneCountry and PhoneLineType describe the parsed phone number, they are empty
+	// when PhoneNumber could not be parsed
+	PhoneE164     string
+	PhoneCountry  string
+	PhoneLineType string
+	CompanyName   string
+	Subject       string
+	Message       string
+	// Country is the ISO 3166-1 alpha-2 code of the customer's country, if known
+	Country string
+	// RawPayload holds the submitted input exactly as it was received, encoded as JSON
+	RawPayload    []byte
+	Status        string
+	StatusHistory []EnquiryStatusChange
+	// Fingerprint identifies the same person sending the same message, see utils.EnquiryFingerprint
+	Fingerprint string
+	// Resubmissions records identical submissions that were linked to this enquiry
+	Resubmissions []EnquiryResubmission
+	// SpamScore and SpamReasons hold the outcome of the spam filter
+	SpamScore   float64
+	SpamReasons []string
+	// Notes are internal remarks added by the sales team
+	Notes []EnquiryNote
+	// SlackMessages are the messages posted through the Slack Web API, at most one per notifier
+	SlackMessages []SlackMessage
+	// Assignee is the salesperson handling the enquiry, empty while it is unassigned.
+	// AssigneeSlackID is their Slack user ID when known.
+	Assignee        string
+	AssigneeSlackID string
+	// Locale is the language the customer used, emails to them are written in it, see i18n.Supported
+	Locale string
+	// Attachments are the files sent with the enquiry, their contents are in blob storage
+	Attachments []EnquiryAttachment
+	// NotifiedAt is when the notifications of the new enquiry were queued: on arrival, or for an
+	// enquiry quarantined by the spam filter when it was first released. It is nil until then.
+	NotifiedAt *time.Time
+	CreatedAt  time.Time
+	UpdatedAt  time.Time
+}
+
+// Notified reports whether the sales team was told about the enquiry, only then are there follow-ups to send
+func (e *Enquiry) Notified() bool {
+	return e.NotifiedAt != nil
+}
+
+// SlackMessage returns the message posted about the enquiry by the named notifier
+func (e *Enquiry) SlackMessage(destination string) (SlackMessage, bool) {
+	for _, message := range e.SlackMessages {
+		if message.Destination == destination {
+			return message, true
+		}
+	}
+	return SlackMessage{}, false
+}
+
+// DisplayPhoneNumber returns the normalized phone number, or the number as submitted when it could not be parsed
+func (e *Enquiry) DisplayPhoneNumber() string {
+	if e.PhoneE164 != "" {
+		return e.PhoneE164
+	}
+	return e.PhoneNumber
+}
+
+// TelURI returns a tel: link to the customer's phone, empty when the number could not be parsed
+func (e *Enquiry) TelURI() string {
+	return phone.TelURI(e.PhoneE164)
+}
+
+// WhatsAppURL returns a link to a WhatsApp chat with the customer, empty when the number cannot use WhatsApp
+func (e *Enquiry) WhatsAppURL() string {
+	return phone.WhatsAppURL(e.PhoneE164, phone.LineType(e.PhoneLineType))
+}
+
+// EnquiryResubmission records a duplicate submission of an enquiry
+type EnquiryResubmission struct {
+	Source     string    `json:"source"`
+	Subject    string    `json:"subject"`
+	ReceivedAt time.Time `json:"receivedAt"`
+	// Attachments are the files sent with the duplicate, they are added to the enquiry's attachments
+	Attachments []EnquiryAttachment `json:"attachments,omitempty"`
+}
+
+// EnquiryNote is an internal remark on an enquiry
+type EnquiryNote struct {
+	Text      string    `json:"text"`
+	Author    string    `json:"author,omitempty"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// EnquiryAssignment records who an enquiry was assigned to
+type EnquiryAssignment struct {
+	Assignee    string    `json:"assignee"`
+	SlackUserID string    `json:"slackUserId,omitempty"`
+	AssignedBy  string    `json:"assignedBy,omitempty"`
+	AssignedAt  time.Time `json:"assignedAt"`
+}
+
+// SlackMessage identifies a message posted through the Slack Web API, follow-ups are threaded under it
+type SlackMessage struct {
+	// Destination is the notifier name
+	Destination string `json:"destination"`
+	Channel     string `json:"channel"`
+	TS          string `json:"ts"`
+}
+
+// EnquiryAttachment describes a file the customer sent with the enquiry
+type EnquiryAttachment struct {
+	ID string `json:"id"`
+	// FileName is the name the file had on the customer's computer
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	// Key locates the content in blob storage
+	Key       string    `json:"key"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// EnquiryStatusChange records a single status transition of an enquiry
+type EnquiryStatusChange struct {
+	// From is empty for the initial status
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to"`
+	Reason    string    `json:"reason"`
+	ChangedBy string    `json:"changedBy,omitempty"`
+	ChangedAt time.Time `json:"changedAt"`
+}
+
+// ToModel converts the enquiry to its GraphQL model
+func (e *Enquiry) ToModel() *model.Enquiry {
+	enquiry := &model.Enquiry{
+		ID:              e.ID,
+		ReferenceNumber: e.ReferenceNumber,
+		Source:          model.WebsiteSource(e.Source),
+		Name:            e.Name,
+		Email:           e.Email,
+		PhoneNumber:     e.PhoneNumber,
+		CompanyName:     e.CompanyName,
+		Subject:         e.Subject,
+		Message:         e.Message,
+		Status:          model.EnquiryStatus(e.Status),
+		StatusHistory:   make([]*model.EnquiryStatusChange, 0, len(e.StatusHistory)),
+		SpamScore:       e.SpamScore,
+		SpamReasons:     append([]string{}, e.SpamReasons...),
+		Resubmissions:   make([]*model.EnquiryResubmission, 0, len(e.Resubmissions)),
+		Notes:           make([]*model.EnquiryNote, 0, len(e.Notes)),
+		Attachments:     make([]*model.EnquiryAttachment, 0, len(e.Attachments)),
+		Locale:          e.Locale,
+		CreatedAt:       e.CreatedAt,
+		UpdatedAt:       e.UpdatedAt,
+	}
+	if e.Country != "" {
+		country := e.Country
+		enquiry.Country = &country
+	}
+	if e.Assignee != "" {
+		assignee := e.Assignee
+		enquiry.Assignee = &assignee
+	}
+	if e.PhoneE164 != "" {
+		enquiry.Phone = &model.ParsedPhoneNumber{
+			E164:     e.PhoneE164,
+			Country:  e.PhoneCountry,
+			LineType: model.PhoneLineType(e.PhoneLineType),
+		}
+		if uri := e.TelURI(); uri != "" {
+			enquiry.Phone.TelLink = &uri
+		}
+		if url := e.WhatsAppURL(); url != "" {
+			enquiry.Phone.WhatsAppLink = &url
+		}
+	}
+	for _, change := range e.StatusHistory {
+		enquiry.StatusHistory = append(enquiry.StatusHistory, change.ToModel())
+	}
+	for _, resubmission := range e.Resubmissions {
+		enquiry.Resubmissions = append(enquiry.Resubmissions, &model.EnquiryResubmission{
+			Source:     model.WebsiteSource(resubmission.Source),
+			Subject:    resubmission.Subject,
+			ReceivedAt: resubmission.ReceivedAt,
+		})
+	}
+	for _, note := range e.Notes {
+		enquiry.Notes = append(enquiry.Notes, note.ToModel())
+	}
+	for _, attachment := range e.Attachments {
+		enquiry.Attachments = append(enquiry.Attachments, attachment.ToModel())
+	}
+	return enquiry
+}
+
+// ToModel converts the attachment to its GraphQL model, the download link is left for the caller to sign
+func (a EnquiryAttachment) ToModel() *model.EnquiryAttachment {
+	return &model.EnquiryAttachment{
+		ID:          a.ID,
+		FileName:    a.FileName,
+		ContentType: a.ContentType,
+		Size:        int(a.Size),
+		CreatedAt:   a.CreatedAt,
+	}
+}
+
+// ToModel converts the note to its GraphQL model
+func (n EnquiryNote) ToModel() *model.EnquiryNote {
+	note := &model.EnquiryNote{
+		Text:      n.Text,
+		CreatedAt: n.CreatedAt,
+	}
+	if n.Author != "" {
+		author := n.Author
+		note.Author = &author
+	}
+	return note
+}
+
+// ToModel converts the status change to its GraphQL model
+func (c EnquiryStatusChange) ToModel() *model.EnquiryStatusChange {
+	change := &model.EnquiryStatusChange{
+		To:        model.EnquiryStatus(c.To),
+		Reason:    c.Reason,
+		ChangedAt: c.ChangedAt,
+	}
+	if c.From != "" {
+		from := model.EnquiryStatus(c.From)
+		change.From = &from
+	}
+	if c.ChangedBy != "" {
+		changedBy := c.ChangedBy
+		change.ChangedBy = &changedBy
+	}
+	return change
+}
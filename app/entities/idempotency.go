@@ -0,0 +1,25 @@
+package entities
+
+import "time"
+
+// Idempotency record statuses
+const (
+	IdempotencyStatusInProgress = "IN_PROGRESS"
+	IdempotencyStatusCompleted  = "COMPLETED"
+)
+
+// IdempotencyRecord remembers the response of a request made with an idempotency key
+type IdempotencyRecord struct {
+	Key string
+	// RequestHash fingerprints the request so a key cannot be reused for a different request
+	RequestHash string
+	Status      string
+	// Owner is the random token of the request holding the key, only it may complete or release the key
+	Owner string
+	// Response is the JSON encoded response, set once the request completed
+	Response []byte
+	// LockedUntil is when an in-progress request is considered abandoned
+	LockedUntil time.Time
+	ExpiresAt   time.Time
+	CreatedAt   time.Time
+}
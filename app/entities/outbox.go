@@ -0,0 +1,46 @@
+package entities
+
+import "time"
+
+// Outbox message kinds
+const (
+	OutboxKindEnquiryCreated = "enquiry.created"
+	// OutboxKindEnquiryResubmitted carries an EnquiryResubmission payload
+	OutboxKindEnquiryResubmitted = "enquiry.resubmitted"
+	// OutboxKindEnquiryStatusChanged carries an EnquiryStatusChange payload, it only goes to notifiers that follow up
+	OutboxKindEnquiryStatusChanged = "enquiry.status_changed"
+	// OutboxKindEnquiryNoteAdded carries an EnquiryNote payload, it only goes to notifiers that follow up
+	OutboxKindEnquiryNoteAdded = "enquiry.note_added"
+	// OutboxKindEnquiryAssigned carries an EnquiryAssignment payload, it only goes to notifiers that follow up
+	OutboxKindEnquiryAssigned = "enquiry.assigned"
+	// OutboxKindEnquiryAcknowledged is the email telling the customer their enquiry arrived, it carries no payload
+	OutboxKindEnquiryAcknowledged = "enquiry.acknowledged"
+)
+
+// Outbox message statuses
+const (
+	OutboxStatusPending   = "PENDING"
+	OutboxStatusDelivered = "DELIVERED"
+	// OutboxStatusDead marks messages that ran out of delivery attempts
+	OutboxStatusDead = "DEAD"
+)
+
+// OutboxMessage is a pending notification for a single destination.
+// It is stored together with the enquiry and delivered by the outbox worker.
+type OutboxMessage struct {
+	ID        string
+	EnquiryID string
+	Kind      string
+	// Destination is the notifier name
+	Destination string
+	// Payload holds kind specific JSON data, empty for enquiry.created
+	Payload       []byte
+	Status        string
+	Attempts      int
+	LastError     string
+	NextAttemptAt time.Time
+	// LockedUntil is set while a worker is delivering the message
+	LockedUntil *time.Time
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
@@ -0,0 +1,53 @@
+package i18n
+
+// catalogs translates the English messages by locale. Keys are the English text exactly as it is
+// passed to Translate or Sprintf, fmt verbs included; input field names translate to their labels.
+// Messages missing from a catalog are shown in English.
+var catalogs = map[string]map[string]string{
+	Arabic: {
+		// Input field labels
+		"contactInfo": "بيانات التواصل",
+		"name":        "الاسم",
+		"email":       "البريد الإلكتروني",
+		"phoneNumber": "رقم الهاتف",
+		"companyName": "اسم الشركة",
+		"subject":     "الموضوع",
+		"message":     "الرسالة",
+		"country":     "الدولة",
+		"locale":      "اللغة",
+		"attachments": "المرفقات",
+
+		// Validation
+		"is required":                                                        "مطلوب",
+		"must be at least %d characters":                                     "يجب ألا يقل عن %d أحرف",
+		"must be at most %d characters":                                      "يجب ألا يزيد عن %d حرفًا",
+		"must be a valid email address":                                      "يجب أن يكون عنوان بريد إلكتروني صالحًا",
+		"must be a valid phone number":                                       "يجب أن يكون رقم هاتف صالحًا",
+		"must include the country code, e.g. %s":                             "يجب أن يتضمن رمز الدولة، مثل %s",
+		"must be a two letter ISO country code":                              "يجب أن يكون رمز دولة من حرفين وفق ISO",
+		"contains characters that are not allowed":                           "يحتوي على أحرف غير مسموح بها",
+		"must contain at least one contact":                                  "يجب أن يحتوي على جهة اتصال واحدة على الأقل",
+		"may only contain letters, spaces, apostrophes, periods and hyphens": "يجب أن يحتوي على أحرف ومسافات وفواصل عليا ونقاط وشرطات فقط",
+		"are not accepted":                                                   "غير مقبولة",
+		"may contain at most %d files":                                       "يجب ألا تزيد عن %d ملفات",
+		"must be at most %s":                                                 "يجب ألا يزيد حجمه عن %s",
+		"may carry at most %s of files in total":                             "يجب ألا يزيد الحجم الإجمالي للملفات عن %s",
+		"is not an accepted file type":                                       "نوع ملف غير مقبول",
+
+		// Request errors
+		"a captcha token is required":                                             "رمز التحقق (captcha) مطلوب",
+		"captcha verification failed, please try again":                           "فشل التحقق (captcha)، يرجى المحاولة مرة أخرى",
+		"captcha verification is temporarily unavailable, please try again later": "التحقق (captcha) غير متاح مؤقتًا، يرجى المحاولة لاحقًا",
+		"too many requests, retry in %d seconds":                                  "طلبات كثيرة جدًا، يرجى المحاولة بعد %d ثانية",
+		"the request was cancelled before this enquiry was processed":             "أُلغي الطلب قبل معالجة هذا الاستفسار",
+		"the enquiry could not be saved, please try again later":                  "تعذّر حفظ الاستفسار، يرجى المحاولة لاحقًا",
+
+		// Acknowledgement email
+		"We received your enquiry: %s": "استلمنا استفسارك: %s",
+		"Dear %s,":                     "مرحبًا %s،",
+		"Thank you for contacting %s. We have received your enquiry and our team will get back to you shortly.": "شكرًا لتواصلك مع %s. لقد استلمنا استفسارك وسيتواصل معك فريقنا قريبًا.",
+		"Your reference number is":                               "رقمك المرجعي هو",
+		"Please quote it if you write to us about this enquiry.": "يرجى ذكره عند مراسلتنا بخصوص هذا الاستفسار.",
+		"Subject": "الموضوع",
+	},
+}
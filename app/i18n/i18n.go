@@ -0,0 +1,113 @@
+package i18n
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Supported locales, messages are written in English and translated through the catalogs
+const (
+	English = "en"
+	Arabic  = "ar"
+)
+
+// DefaultLocale is used when the visitor asks for no supported locale
+const DefaultLocale = English
+
+// Supported lists the locales with a catalog, and English
+var Supported = []string{English, Arabic}
+
+// rtlLocales are written right to left
+var rtlLocales = map[string]bool{
+	Arabic: true,
+}
+
+// Negotiate picks the supported locale that suits an Accept-Language header or a single
+// language tag best, e.g. "ar-AE,ar;q=0.9,en;q=0.8" gives ar. Only the language of a tag
+// is compared, so en-GB and en-US both give en. It returns DefaultLocale when nothing matches.
+func Negotiate(header string) string {
+	type preference struct {
+		language string
+		quality  float64
+	}
+
+	var preferences []preference
+	for _, entry := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(entry, ";")
+		quality := 1.0
+		if q, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		language, _, _ := strings.Cut(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"), "-")
+		if language == "" || language == "*" || quality <= 0 {
+			continue
+		}
+		preferences = append(preferences, preference{strings.ToLower(language), quality})
+	}
+
+	// Entries of equal quality keep the order the client sent them in
+	slices.SortStableFunc(preferences, func(a, b preference) int {
+		switch {
+		case a.quality > b.quality:
+			return -1
+		case a.quality < b.quality:
+			return 1
+		}
+		return 0
+	})
+	for _, preference := range preferences {
+		if IsSupported(preference.language) {
+			return preference.language
+		}
+	}
+	return DefaultLocale
+}
+
+// IsSupported reports whether the locale has a catalog or is English
+func IsSupported(locale string) bool {
+	return slices.Contains(Supported, locale)
+}
+
+// Direction returns the HTML dir attribute of the locale, rtl or ltr
+func Direction(locale string) string {
+	if rtlLocales[locale] {
+		return "rtl"
+	}
+	return "ltr"
+}
+
+// Translate returns the message in the locale, or the English message when the catalog has no translation
+func Translate(locale, message string) string {
+	if translated, ok := catalogs[locale][message]; ok {
+		return translated
+	}
+	return message
+}
+
+// Sprintf translates the format and then formats it like fmt.Sprintf
+func Sprintf(locale, format string, args ...interface{}) string {
+	return fmt.Sprintf(Translate(locale, format), args...)
+}
+
+// FieldMessage puts an input path such as contactInfo[0].email in front of the message.
+// Other locales than English name the field by its translated label when the catalog has one,
+// visitors do not read input paths.
+func FieldMessage(locale, path, message string) string {
+	if locale != English {
+		name := path[strings.LastIndex(path, ".")+1:]
+		// A list element such as attachments[1] is named by its list
+		if index := strings.IndexByte(name, '['); index > 0 {
+			name = name[:index]
+		}
+		if label, ok := catalogs[locale][name]; ok {
+			return label + " " + message
+		}
+	}
+	return path + " " + message
+}
@@ -0,0 +1,81 @@
+package i18n
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNegotiate(t *testing.T) {
+	tests := []struct {
+		header string
+		want   string
+	}{
+		{"", English},
+		{"ar", Arabic},
+		{"ar-AE,ar;q=0.9,en;q=0.8", Arabic},
+		{"en-GB,ar;q=0.5", English},
+		{"fr-FR,ar;q=0.5,en;q=0.4", Arabic},
+		{"en;q=0.3,ar;q=0.7", Arabic},
+		{"AR_ae", Arabic},
+		{"ar;q=0,en", English},
+		{"ar;q=abc,fr", English},
+		{"de,fr", DefaultLocale},
+		{"*", DefaultLocale},
+	}
+	for _, tt := range tests {
+		if got := Negotiate(tt.header); got != tt.want {
+			t.Errorf("Negotiate(%q) = %q, want %q", tt.header, got, tt.want)
+		}
+	}
+}
+
+func TestSprintf(t *testing.T) {
+	if got := Sprintf(Arabic, "must be at least %d characters", 2); got != "يجب ألا يقل عن 2 أحرف" {
+		t.Errorf("Arabic = %q", got)
+	}
+	if got := Sprintf(English, "must be at least %d characters", 2); got != "must be at least 2 characters" {
+		t.Errorf("English = %q", got)
+	}
+	// Messages without a translation stay in English
+	if got := Sprintf(Arabic, "has %d widgets", 3); got != "has 3 widgets" {
+		t.Errorf("untranslated = %q", got)
+	}
+	if got := Sprintf("fr", "is required"); got != "is required" {
+		t.Errorf("unsupported locale = %q", got)
+	}
+}
+
+func TestFieldMessage(t *testing.T) {
+	tests := []struct {
+		locale string
+		path   string
+		want   string
+	}{
+		{English, "contactInfo[0].email", "contactInfo[0].email مطلوب"},
+		{Arabic, "contactInfo[0].email", "البريد الإلكتروني مطلوب"},
+		{Arabic, "contactInfo[1].attachments[2]", "المرفقات مطلوب"},
+		{Arabic, "contactInfo", "بيانات التواصل مطلوب"},
+		{Arabic, "contactInfo[0].fax", "contactInfo[0].fax مطلوب"},
+	}
+	for _, tt := range tests {
+		if got := FieldMessage(tt.locale, tt.path, "مطلوب"); got != tt.want {
+			t.Errorf("FieldMessage(%s, %s) = %q, want %q", tt.locale, tt.path, got, tt.want)
+		}
+	}
+}
+
+func TestCatalogsKeepFormatVerbs(t *testing.T) {
+	for locale, catalog := range catalogs {
+		for message, translated := range catalog {
+			if strings.Count(message, "%") != strings.Count(translated, "%") {
+				t.Errorf("%s translation of %q has other fmt verbs: %q", locale, message, translated)
+			}
+		}
+	}
+}
+
+func TestDirection(t *testing.T) {
+	if Direction(Arabic) != "rtl" || Direction(English) != "ltr" {
+		t.Errorf("Direction = %s for ar and %s for en", Direction(Arabic), Direction(English))
+	}
+}
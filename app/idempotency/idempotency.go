@@ -0,0 +1,137 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/utils"
+)
+
+var (
+	// ErrKeyReused is returned when a key is sent again with a different request
+	ErrKeyReused = errors.New("idempotency key was already used for a different request")
+	// ErrInProgress is returned when the first request with the key did not finish within the wait timeout
+	ErrInProgress = errors.New("a request with this idempotency key is still in progress")
+)
+
+// defaultPollInterval is how often a waiting repeat checks whether the first request finished
+const defaultPollInterval = 100 * time.Millisecond
+
+// Config holds the replay settings
+type Config struct {
+	// Window is how long the first response of a key is replayed
+	Window time.Duration
+	// LockTimeout is how long a request may hold its key before a repeat may run it again
+	LockTimeout time.Duration
+	// WaitTimeout bounds how long a repeat waits for the first request to finish
+	WaitTimeout time.Duration
+	// PollInterval is how often a waiting repeat checks the key
+	PollInterval time.Duration
+}
+
+// Store runs requests at most once per idempotency key and replays their responses
+type Store struct {
+	config     Config
+	repository data.IdempotencyRepository
+	logger     *zap.Logger
+}
+
+// NewStore creates an idempotency store
+func NewStore(config Config, repository data.IdempotencyRepository, logger *zap.Logger) *Store {
+	if config.PollInterval <= 0 {
+		config.PollInterval = defaultPollInterval
+	}
+	return &Store{
+		config:     config,
+		repository: repository,
+		logger:     logger,
+	}
+}
+
+// Do runs fn unless the key was seen before, and returns its JSON encoded response.
+// A repeat of a finished request gets the stored response with replayed set to true,
+// a repeat of a running request waits for it to finish.
+// When fn fails the key is released so the client can retry.
+// A request that outlives LockTimeout loses the key to a repeat and cannot overwrite its outcome.
+func (s *Store) Do(ctx context.Context, key, requestHash string, fn func(ctx context.Context) ([]byte, error)) (response []byte, replayed bool, err error) {
+	deadline := time.Now().Add(s.config.WaitTimeout)
+
+	for {
+		now := time.Now()
+		owner := utils.GenerateID()
+		existing, reserved, err := s.repository.Reserve(ctx, &entities.IdempotencyRecord{
+			Key:         key,
+			RequestHash: requestHash,
+			Status:      entities.IdempotencyStatusInProgress,
+			Owner:       owner,
+			LockedUntil: now.Add(s.config.LockTimeout),
+			ExpiresAt:   now.Add(s.config.Window),
+			CreatedAt:   now,
+		}, now)
+		if err != nil {
+			return nil, false, fmt.Errorf("error reserving idempotency key: %w", err)
+		}
+
+		if reserved {
+			response, err := s.run(ctx, key, owner, fn)
+			return response, false, err
+		}
+
+		if existing.RequestHash != requestHash {
+			return nil, false, ErrKeyReused
+		}
+		if existing.Status == entities.IdempotencyStatusCompleted {
+			return existing.Response, true, nil
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil, false, ErrInProgress
+		}
+		select {
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-time.After(s.config.PollInterval):
+		}
+	}
+}
+
+// run executes fn for a key reserved by owner and records the outcome
+func (s *Store) run(ctx context.Context, key, owner string, fn func(ctx context.Context) ([]byte, error)) (response []byte, err error) {
+	// The outcome is recorded even if the client went away in the meantime
+	storeCtx := context.WithoutCancel(ctx)
+
+	completed := false
+	defer func() {
+		if completed {
+			return
+		}
+		if releaseErr := s.repository.Release(storeCtx, key, owner); releaseErr != nil {
+			s.logger.Error("Error releasing idempotency key", zap.String("key", key), zap.Error(releaseErr))
+		}
+	}()
+
+	response, err = fn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.repository.Complete(storeCtx, key, owner, response)
+	switch {
+	case errors.Is(err, data.ErrIdempotencyKeyNotOwned):
+		// The lock timed out and a repeat took the key over, its outcome is the one replayed
+		completed = true
+		s.logger.Warn("Idempotency key was taken over before the request finished", zap.String("key", key))
+	case err != nil:
+		// The request itself succeeded, a failed store only means a repeat runs again
+		s.logger.Error("Error storing idempotent response", zap.String("key", key), zap.Error(err))
+	default:
+		completed = true
+	}
+	return response, nil
+}
@@ -0,0 +1,208 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/data"
+)
+
+// newTestStore creates a store on a memory repository that polls quickly
+func newTestStore(lockTimeout, waitTimeout time.Duration) (*Store, *data.MemoryIdempotencyRepository) {
+	repository := data.NewMemoryIdempotencyRepository()
+	return NewStore(Config{
+		Window:       time.Hour,
+		LockTimeout:  lockTimeout,
+		WaitTimeout:  waitTimeout,
+		PollInterval: time.Millisecond,
+	}, repository, zap.NewNop()), repository
+}
+
+// respond returns fn that counts its calls and answers with response
+func respond(calls *atomic.Int32, response string) func(ctx context.Context) ([]byte, error) {
+	return func(ctx context.Context) ([]byte, error) {
+		calls.Add(1)
+		return []byte(response), nil
+	}
+}
+
+func TestDoReplaysCompletedRequests(t *testing.T) {
+	store, _ := newTestStore(time.Minute, time.Second)
+	ctx := context.Background()
+	var calls atomic.Int32
+
+	response, replayed, err := store.Do(ctx, "key", "hash", respond(&calls, `"first"`))
+	if err != nil || replayed || string(response) != `"first"` {
+		t.Fatalf("first Do = %s, %v, %v", response, replayed, err)
+	}
+	response, replayed, err = store.Do(ctx, "key", "hash", respond(&calls, `"second"`))
+	if err != nil || !replayed || string(response) != `"first"` {
+		t.Fatalf("repeat Do = %s, %v, %v, want the first response replayed", response, replayed, err)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("fn ran %d times, want once", calls.Load())
+	}
+
+	if _, _, err := store.Do(ctx, "key", "other", respond(&calls, `"third"`)); !errors.Is(err, ErrKeyReused) {
+		t.Errorf("Do with a different request = %v, want ErrKeyReused", err)
+	}
+}
+
+func TestDoWaitsForRequestInProgress(t *testing.T) {
+	store, _ := newTestStore(time.Minute, 5*time.Second)
+	ctx := context.Background()
+	started, finish := make(chan struct{}), make(chan struct{})
+
+	go func() {
+		_, _, _ = store.Do(ctx, "key", "hash", func(ctx context.Context) ([]byte, error) {
+			close(started)
+			<-finish
+			return []byte(`"first"`), nil
+		})
+	}()
+	<-started
+
+	done := make(chan struct{})
+	var response []byte
+	var replayed bool
+	var err error
+	go func() {
+		defer close(done)
+		response, replayed, err = store.Do(ctx, "key", "hash", func(ctx context.Context) ([]byte, error) {
+			t.Error("the repeat ran while the first request was in progress")
+			return nil, nil
+		})
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("the repeat did not wait for the first request")
+	case <-time.After(20 * time.Millisecond):
+	}
+	close(finish)
+	<-done
+	if err != nil || !replayed || string(response) != `"first"` {
+		t.Errorf("repeat Do = %s, %v, %v, want the first response replayed", response, replayed, err)
+	}
+}
+
+func TestDoGivesUpWaiting(t *testing.T) {
+	store, _ := newTestStore(time.Minute, 10*time.Millisecond)
+	ctx := context.Background()
+	started, finish := make(chan struct{}), make(chan struct{})
+	defer close(finish)
+
+	go func() {
+		_, _, _ = store.Do(ctx, "key", "hash", func(ctx context.Context) ([]byte, error) {
+			close(started)
+			<-finish
+			return nil, nil
+		})
+	}()
+	<-started
+
+	var calls atomic.Int32
+	if _, _, err := store.Do(ctx, "key", "hash", respond(&calls, `"second"`)); !errors.Is(err, ErrInProgress) {
+		t.Errorf("Do = %v, want ErrInProgress", err)
+	}
+	if calls.Load() != 0 {
+		t.Error("the repeat ran while the first request was in progress")
+	}
+}
+
+func TestDoReleasesKeyOnError(t *testing.T) {
+	store, repository := newTestStore(time.Minute, time.Second)
+	ctx := context.Background()
+	failure := errors.New("database down")
+
+	_, _, err := store.Do(ctx, "key", "hash", func(ctx context.Context) ([]byte, error) {
+		return nil, failure
+	})
+	if !errors.Is(err, failure) {
+		t.Fatalf("Do = %v, want the error of fn", err)
+	}
+	if _, err := repository.Get(ctx, "key"); !errors.Is(err, data.ErrIdempotencyKeyNotFound) {
+		t.Fatalf("Get after the failure = %v, want the key released", err)
+	}
+
+	var calls atomic.Int32
+	response, replayed, err := store.Do(ctx, "key", "hash", respond(&calls, `"retried"`))
+	if err != nil || replayed || string(response) != `"retried"` || calls.Load() != 1 {
+		t.Errorf("retry Do = %s, %v, %v, want it to run", response, replayed, err)
+	}
+}
+
+func TestDoTakesOverAbandonedKey(t *testing.T) {
+	store, repository := newTestStore(10*time.Millisecond, time.Second)
+	ctx := context.Background()
+	started, finish, slowDone := make(chan struct{}), make(chan struct{}), make(chan error)
+
+	// The first request outlives its lock
+	go func() {
+		_, _, err := store.Do(ctx, "key", "hash", func(ctx context.Context) ([]byte, error) {
+			close(started)
+			<-finish
+			return nil, errors.New("too late")
+		})
+		slowDone <- err
+	}()
+	<-started
+	time.Sleep(20 * time.Millisecond)
+
+	var calls atomic.Int32
+	response, replayed, err := store.Do(ctx, "key", "hash", respond(&calls, `"takeover"`))
+	if err != nil || replayed || string(response) != `"takeover"` {
+		t.Fatalf("takeover Do = %s, %v, %v, want it to run", response, replayed, err)
+	}
+
+	// The first request failing afterwards must not release the key it lost
+	close(finish)
+	<-slowDone
+	record, err := repository.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if string(record.Response) != `"takeover"` {
+		t.Errorf("stored response = %s, want the one of the takeover", record.Response)
+	}
+}
+
+func TestDoKeepsResponseOfTakeover(t *testing.T) {
+	store, repository := newTestStore(10*time.Millisecond, time.Second)
+	ctx := context.Background()
+	started, finish, slowDone := make(chan struct{}), make(chan struct{}), make(chan []byte)
+
+	go func() {
+		response, _, _ := store.Do(ctx, "key", "hash", func(ctx context.Context) ([]byte, error) {
+			close(started)
+			<-finish
+			return []byte(`"slow"`), nil
+		})
+		slowDone <- response
+	}()
+	<-started
+	time.Sleep(20 * time.Millisecond)
+
+	var calls atomic.Int32
+	if _, _, err := store.Do(ctx, "key", "hash", respond(&calls, `"takeover"`)); err != nil {
+		t.Fatalf("takeover Do: %v", err)
+	}
+
+	// The slow request still gets its own response but cannot overwrite the stored one
+	close(finish)
+	if response := <-slowDone; string(response) != `"slow"` {
+		t.Errorf("slow Do = %s, want its own response", response)
+	}
+	record, err := repository.Get(ctx, "key")
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if string(record.Response) != `"takeover"` {
+		t.Errorf("stored response = %s, want the one of the takeover", record.Response)
+	}
+}
@@ -0,0 +1,18 @@
+package keys
+
+const (
+	// AttachmentSigningSecretEnvKey is the environment variable name that stores the secret signing attachment download links.
+	AttachmentSigningSecretEnvKey = "ATTACHMENT_SIGNING_SECRET"
+	// AttachmentPublicURLEnvKey is the environment variable name that stores the public URL download links are built on.
+	AttachmentPublicURLEnvKey = "ATTACHMENT_PUBLIC_URL"
+	// AttachmentS3AccessKeyIDEnvKey is the environment variable name that stores the access key ID of the attachment bucket.
+	AttachmentS3AccessKeyIDEnvKey = "ATTACHMENT_S3_ACCESS_KEY_ID"
+	// AttachmentS3SecretAccessKeyEnvKey is the environment variable name that stores the secret access key of the attachment bucket.
+	AttachmentS3SecretAccessKeyEnvKey = "ATTACHMENT_S3_SECRET_ACCESS_KEY"
+)
+
+// Blob storage backends of attachments
+const (
+	BlobStorageDisk = "disk"
+	BlobStorageS3   = "s3"
+)
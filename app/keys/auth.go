@@ -0,0 +1,10 @@
+package keys
+
+const (
+	// AdminAPITokenEnvKey is the environment variable name that stores the bearer token for admin queries.
+	// Admin queries are rejected when it is not set.
+	AdminAPITokenEnvKey = "ADMIN_API_TOKEN"
+
+	// AdminUserID is the user ID assigned to requests authenticated with the admin token
+	AdminUserID = "admin"
+)
@@ -0,0 +1,16 @@
+package keys
+
+const (
+	// CaptchaSecretEnvKeyPrefix prefixes the environment variable names that store the captcha secret of a source,
+	// e.g. CAPTCHA_SECRET_SCTGULF.
+	CaptchaSecretEnvKeyPrefix = "CAPTCHA_SECRET_"
+)
+
+// Captcha provider names used in configuration
+const (
+	CaptchaProviderTurnstile = "turnstile"
+	CaptchaProviderHCaptcha  = "hcaptcha"
+	CaptchaProviderReCaptcha = "recaptcha"
+	// CaptchaProviderFake accepts only the configured secret as token, for tests and staging
+	CaptchaProviderFake = "fake"
+)
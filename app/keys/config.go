@@ -0,0 +1,7 @@
+package keys
+
+const (
+	// ConfigFileEnvKey is the environment variable name that stores the path of the JSON configuration file.
+	// It is used when no path is passed on the command line, e.g. on Vercel.
+	ConfigFileEnvKey = "CONFIG_FILE"
+)
@@ -0,0 +1,18 @@
+package keys
+
+const (
+	// DBDriverEnvKey selects the enquiry store: "memory" (default) or "postgres".
+	DBDriverEnvKey = "DB_DRIVER"
+	// DatabaseURLEnvKey is the environment variable name that stores the database connection string.
+	// When set it takes precedence over the individual DB settings.
+	DatabaseURLEnvKey = "DATABASE_URL"
+	// DBPasswordEnvKey is the environment variable name that stores the database password.
+	DBPasswordEnvKey = "DB_PASSWORD"
+)
+
+const (
+	// DBDriverMemory keeps enquiries in process memory
+	DBDriverMemory = "memory"
+	// DBDriverPostgres stores enquiries in PostgreSQL
+	DBDriverPostgres = "postgres"
+)
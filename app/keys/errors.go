@@ -0,0 +1,42 @@
+package keys
+
+// Machine-readable error codes returned to API clients
+const (
+	// ErrCodeInternal reports an unexpected server side failure
+	ErrCodeInternal = "INTERNAL_ERROR"
+	// ErrCodeCancelled reports work that was abandoned because the client went away
+	ErrCodeCancelled = "CANCELLED"
+	// ErrCodeInvalidIdempotencyKey reports an idempotency key that is empty or too long
+	ErrCodeInvalidIdempotencyKey = "INVALID_IDEMPOTENCY_KEY"
+	// ErrCodeIdempotencyKeyReused reports an idempotency key sent again with a different request
+	ErrCodeIdempotencyKeyReused = "IDEMPOTENCY_KEY_REUSED"
+	// ErrCodeRequestInProgress reports a repeat that gave up waiting for the first request to finish
+	ErrCodeRequestInProgress = "REQUEST_IN_PROGRESS"
+	// ErrCodeCaptchaFailed reports a missing or rejected captcha token
+	ErrCodeCaptchaFailed = "CAPTCHA_FAILED"
+	// ErrCodeCaptchaUnavailable reports that the captcha provider could not be reached, the request may be retried
+	ErrCodeCaptchaUnavailable = "CAPTCHA_UNAVAILABLE"
+	// ErrCodeRateLimited reports a call rejected by @rateLimit, the retryAfter extension holds the seconds to wait
+	ErrCodeRateLimited = "RATE_LIMITED"
+
+	// ErrCodeRequired reports a missing or blank input field, the field extension holds its input path
+	ErrCodeRequired = "REQUIRED"
+	// ErrCodeTooShort reports an input field below its minimum length
+	ErrCodeTooShort = "TOO_SHORT"
+	// ErrCodeTooLong reports an input field above its maximum length
+	ErrCodeTooLong = "TOO_LONG"
+	// ErrCodeInvalidEmail reports a malformed email address
+	ErrCodeInvalidEmail = "INVALID_EMAIL"
+	// ErrCodeInvalidPhone reports a malformed phone number
+	ErrCodeInvalidPhone = "INVALID_PHONE"
+	// ErrCodeInvalidCharacters reports an input field containing characters it does not allow
+	ErrCodeInvalidCharacters = "INVALID_CHARACTERS"
+	// ErrCodeInvalidFormat reports an input field that does not have the expected format
+	ErrCodeInvalidFormat = "INVALID_FORMAT"
+	// ErrCodeTooManyAttachments reports a contact with more attachments than allowed, or any when attachments are off
+	ErrCodeTooManyAttachments = "TOO_MANY_ATTACHMENTS"
+	// ErrCodeAttachmentTooLarge reports an attachment above the maximum file size
+	ErrCodeAttachmentTooLarge = "ATTACHMENT_TOO_LARGE"
+	// ErrCodeUnsupportedAttachment reports an attachment whose file type is not accepted or does not match its content
+	ErrCodeUnsupportedAttachment = "UNSUPPORTED_ATTACHMENT_TYPE"
+)
@@ -0,0 +1,40 @@
+package keys
+
+const (
+	// TeamsWebhookEnvKey is the environment variable name that stores the Microsoft Teams webhook URL.
+	TeamsWebhookEnvKey = "TEAMS_WEBHOOK_URL"
+	// DiscordWebhookEnvKey is the environment variable name that stores the Discord webhook URL.
+	DiscordWebhookEnvKey = "DISCORD_WEBHOOK_URL"
+	// NotifyWebhookEnvKey is the environment variable name that stores the generic JSON webhook URL.
+	NotifyWebhookEnvKey = "NOTIFY_WEBHOOK_URL"
+	// NotifyWebhookSecretEnvKey is the environment variable name that stores the secret used to sign generic webhook bodies.
+	NotifyWebhookSecretEnvKey = "NOTIFY_WEBHOOK_SECRET"
+	// NotifyTemplatesDirEnvKey is the environment variable name that stores the directory of notification message templates.
+	NotifyTemplatesDirEnvKey = "NOTIFY_TEMPLATES_DIR"
+	// NotifyEnquiryURLEnvKey is the environment variable name that stores the admin UI link of an enquiry, {id} is replaced by the enquiry ID.
+	NotifyEnquiryURLEnvKey = "NOTIFY_ENQUIRY_URL"
+
+	// SMTPHostEnvKey is the environment variable name that stores the SMTP relay host.
+	SMTPHostEnvKey = "SMTP_HOST"
+	// SMTPPortEnvKey is the environment variable name that stores the SMTP relay port.
+	SMTPPortEnvKey = "SMTP_PORT"
+	// SMTPUsernameEnvKey is the environment variable name that stores the SMTP username.
+	SMTPUsernameEnvKey = "SMTP_USERNAME"
+	// SMTPPasswordEnvKey is the environment variable name that stores the SMTP password.
+	SMTPPasswordEnvKey = "SMTP_PASSWORD"
+	// SMTPFromEnvKey is the environment variable name that stores the sender address of notification emails.
+	SMTPFromEnvKey = "SMTP_FROM"
+	// NotifyEmailToEnvKey is the environment variable name that stores the comma separated notification email recipients.
+	NotifyEmailToEnvKey = "NOTIFY_EMAIL_TO"
+	// AcknowledgementSMTPPasswordEnvKey is the environment variable name that stores the SMTP password of customer acknowledgement emails.
+	AcknowledgementSMTPPasswordEnvKey = "ACKNOWLEDGEMENT_SMTP_PASSWORD"
+)
+
+// Default notifier names used when a backend is configured from the environment
+const (
+	NotifierSlack   = "slack"
+	NotifierEmail   = "email"
+	NotifierTeams   = "teams"
+	NotifierDiscord = "discord"
+	NotifierWebhook = "webhook"
+)
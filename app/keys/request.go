@@ -0,0 +1,7 @@
+package keys
+
+const (
+	// TrustProxyHeadersEnvKey is the environment variable name that enables reading the client IP from X-Forwarded-For.
+	// Only set it to "true" behind a proxy that overwrites the header, such as Vercel.
+	TrustProxyHeadersEnvKey = "TRUST_PROXY_HEADERS"
+)
@@ -3,4 +3,15 @@ package keys
 const (
 	// SlackWebhookEnvKey is the environment variable name that stores the Slack webhook URL.
 	SlackWebhookEnvKey = "SLACK_WEBHOOK_URL"
+	// SlackBotTokenEnvKey is the environment variable name that stores the bot token of the Slack Web API notifier.
+	SlackBotTokenEnvKey = "SLACK_BOT_TOKEN"
+	// SlackChannelEnvKey is the environment variable name that stores the channel ID the Slack bot posts to.
+	SlackChannelEnvKey = "SLACK_CHANNEL"
+	// SlackAPIURLEnvKey is the environment variable name that stores the Slack Web API base URL, set it to use a fake Slack API.
+	SlackAPIURLEnvKey = "SLACK_API_URL"
+	// SlackBotTokenEnvKeyPrefix prefixes the environment variable names that store the bot token of a configured Slack notifier,
+	// e.g. SLACK_BOT_TOKEN_SLACK_GULF for the notifier named slack-gulf.
+	SlackBotTokenEnvKeyPrefix = "SLACK_BOT_TOKEN_"
+	// SlackSigningSecretEnvKey is the environment variable name that stores the signing secret of the Slack app.
+	SlackSigningSecretEnvKey = "SLACK_SIGNING_SECRET"
 )
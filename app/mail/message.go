@@ -0,0 +1,136 @@
+package mail
+
+import (
+	"bytes"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"sct-backend-service/app/utils"
+)
+
+// Message is an email with a plain text and an optional HTML body
+type Message struct {
+	From     string
+	To       []string
+	ReplyTo  string
+	Subject  string
+	TextBody string
+	HTMLBody string
+	// Headers holds additional headers, e.g. List-Unsubscribe
+	Headers map[string]string
+}
+
+// Bytes renders the message in RFC 5322 format
+func (m *Message) Bytes() ([]byte, error) {
+	var buf bytes.Buffer
+
+	header := textproto.MIMEHeader{}
+	header.Set("From", headerValue(m.From))
+	header.Set("To", headerValue(strings.Join(m.To, ", ")))
+	if m.ReplyTo != "" {
+		header.Set("Reply-To", headerValue(m.ReplyTo))
+	}
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", headerValue(m.Subject)))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-ID", fmt.Sprintf("<%s@%s>", utils.GenerateID(), domainOf(m.From)))
+	header.Set("MIME-Version", "1.0")
+	for key, value := range m.Headers {
+		header.Set(key, headerValue(value))
+	}
+
+	if m.HTMLBody == "" {
+		header.Set("Content-Type", "text/plain; charset=utf-8")
+		header.Set("Content-Transfer-Encoding", "quoted-printable")
+		writeHeader(&buf, header)
+		if err := writeQuotedPrintable(&buf, m.TextBody); err != nil {
+			return nil, err
+		}
+		return buf.Bytes(), nil
+	}
+
+	writer := multipart.NewWriter(&buf)
+	header.Set("Content-Type", "multipart/alternative; boundary="+writer.Boundary())
+	// The multipart writer writes into buf, so the top level header must go first
+	var out bytes.Buffer
+	writeHeader(&out, header)
+
+	for _, part := range []struct {
+		contentType string
+		body        string
+	}{
+		{"text/plain; charset=utf-8", m.TextBody},
+		{"text/html; charset=utf-8", m.HTMLBody},
+	} {
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error creating mime part: %w", err)
+		}
+		if err := writeQuotedPrintable(w, part.body); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("error closing mime writer: %w", err)
+	}
+
+	out.Write(buf.Bytes())
+	return out.Bytes(), nil
+}
+
+func writeHeader(buf *bytes.Buffer, header textproto.MIMEHeader) {
+	for key, values := range header {
+		for _, value := range values {
+			// Header values come from configuration and user input, never allow them to add lines
+			value = strings.NewReplacer("\r", "", "\n", "").Replace(value)
+			fmt.Fprintf(buf, "%s: %s\r\n", key, value)
+		}
+	}
+	buf.WriteString("\r\n")
+}
+
+func writeQuotedPrintable(w interface{ Write([]byte) (int, error) }, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := qp.Write([]byte(body)); err != nil {
+		return fmt.Errorf("error encoding body: %w", err)
+	}
+	return qp.Close()
+}
+
+// domainOf returns the domain of an address, used for Message-ID
+func domainOf(address string) string {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		address = parsed.Address
+	}
+	if at := strings.LastIndex(address, "@"); at >= 0 {
+		return address[at+1:]
+	}
+	return "localhost"
+}
+
+// AddressOf returns the bare email address of a "Name <address>" string
+func AddressOf(address string) string {
+	if parsed, err := mail.ParseAddress(address); err == nil {
+		return parsed.Address
+	}
+	return address
+}
+
+// headerValue folds line breaks into spaces, a subject or address taken from a form
+// must not be able to start a header of its own
+func headerValue(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '\r' || r == '\n' {
+			return ' '
+		}
+		return r
+	}, s)
+}
@@ -0,0 +1,76 @@
+package mail
+
+import (
+	"mime"
+	"net/mail"
+	"strings"
+	"testing"
+)
+
+func TestMessageBytesHeaderInjection(t *testing.T) {
+	tests := []struct {
+		name    string
+		message Message
+		header  string
+		want    string
+	}{
+		{
+			name:    "CRLF in subject",
+			message: Message{Subject: "Quote\r\nBcc: victim@example.com"},
+			header:  "Subject",
+			want:    "Quote  Bcc: victim@example.com",
+		},
+		{
+			name:    "LF in subject",
+			message: Message{Subject: "Quote\nBcc: victim@example.com"},
+			header:  "Subject",
+			want:    "Quote Bcc: victim@example.com",
+		},
+		{
+			name:    "CRLF in reply-to",
+			message: Message{ReplyTo: "jane@example.com\r\nBcc: victim@example.com"},
+			header:  "Reply-To",
+			want:    "jane@example.com  Bcc: victim@example.com",
+		},
+		{
+			name:    "CR in reply-to",
+			message: Message{ReplyTo: "jane@example.com\rCc: victim@example.com"},
+			header:  "Reply-To",
+			want:    "jane@example.com Cc: victim@example.com",
+		},
+		{
+			name:    "CRLF in additional header",
+			message: Message{Headers: map[string]string{"X-Reference": "ABC\r\nBcc: victim@example.com"}},
+			header:  "X-Reference",
+			want:    "ABC  Bcc: victim@example.com",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.message.From = "SCT <notify@example.com>"
+			tt.message.To = []string{"sales@example.com"}
+			tt.message.TextBody = "body"
+
+			raw, err := tt.message.Bytes()
+			if err != nil {
+				t.Fatalf("Bytes: %v", err)
+			}
+			msg, err := mail.ReadMessage(strings.NewReader(string(raw)))
+			if err != nil {
+				t.Fatalf("ReadMessage: %v", err)
+			}
+			for _, injected := range []string{"Bcc", "Cc"} {
+				if value := msg.Header.Get(injected); value != "" {
+					t.Errorf("input added a %s header: %q", injected, value)
+				}
+			}
+			got, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get(tt.header))
+			if err != nil {
+				t.Fatalf("DecodeHeader: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("%s = %q, want %q", tt.header, got, tt.want)
+			}
+		})
+	}
+}
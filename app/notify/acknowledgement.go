@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"context"
+
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/mail"
+)
+
+// AcknowledgementDestination names the acknowledgement notifier in the outbox
+const AcknowledgementDestination = "customer-acknowledgement"
+
+// AcknowledgementSite holds the branding of one site's acknowledgement email
+type AcknowledgementSite struct {
+	From      string
+	ReplyTo   string
+	SiteName  string
+	LogoURL   string
+	Signature string
+	// Localized replaces the name and signature for enquiries in another language, keyed by locale
+	Localized map[string]LocalizedBranding
+}
+
+// LocalizedBranding is the site name and signature in one language, empty fields keep the default
+type LocalizedBranding struct {
+	SiteName  string
+	Signature string
+}
+
+// AcknowledgementNotifier emails customers that their enquiry arrived, quoting its reference number.
+// Every site has its own sender and branding, sites without an entry send nothing.
+type AcknowledgementNotifier struct {
+	sites     map[string]AcknowledgementSite
+	sender    mail.Sender
+	templates *Templates
+}
+
+// NewAcknowledgementNotifier creates the notifier, sites maps a WebsiteSource to its branding
+func NewAcknowledgementNotifier(sites map[string]AcknowledgementSite, sender mail.Sender, templates *Templates) *AcknowledgementNotifier {
+	return &AcknowledgementNotifier{
+		sites:     sites,
+		sender:    sender,
+		templates: templates,
+	}
+}
+
+// Name identifies the notifier
+func (n *AcknowledgementNotifier) Name() string {
+	return AcknowledgementDestination
+}
+
+// Acknowledges reports whether the acknowledgement is turned on for the enquiry's site
+func (n *AcknowledgementNotifier) Acknowledges(enquiry *entities.Enquiry) bool {
+	_, ok := n.sites[enquiry.Source]
+	return ok
+}
+
+// Notify emails the customer. Replies go to the site's sales mailbox, and the
+// Auto-Submitted header keeps the customer's own auto-replies from answering.
+func (n *AcknowledgementNotifier) Notify(ctx context.Context, notification Notification) error {
+	enquiry := notification.Enquiry
+	site, ok := n.sites[enquiry.Source]
+	if !ok {
+		// Turned off after the acknowledgement was queued
+		return nil
+	}
+
+	rendered, err := n.templates.RenderAcknowledgement(enquiry, site)
+	if err != nil {
+		return err
+	}
+	return n.sender.Send(ctx, &mail.Message{
+		From:     site.From,
+		To:       []string{enquiry.Email},
+		ReplyTo:  site.ReplyTo,
+		Subject:  rendered.Subject,
+		TextBody: rendered.Text,
+		HTMLBody: rendered.Body,
+		Headers: map[string]string{
+			"Auto-Submitted":   "auto-replied",
+			"Content-Language": rendered.Language,
+		},
+	})
+}
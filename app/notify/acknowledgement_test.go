@@ -0,0 +1,251 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"mime"
+	"net"
+	netmail "net/mail"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/mail"
+)
+
+// smtpDelivery is a message received by the SMTP listener
+type smtpDelivery struct {
+	From       string
+	Recipients []string
+	Data       string
+}
+
+// startSMTPListener accepts one plain SMTP session on a local port and returns its address and delivery
+func startSMTPListener(t *testing.T) (string, int, <-chan smtpDelivery) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	t.Cleanup(func() { listener.Close() })
+
+	deliveries := make(chan smtpDelivery, 1)
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		_ = conn.SetDeadline(time.Now().Add(10 * time.Second))
+		text := textproto.NewConn(conn)
+
+		var delivery smtpDelivery
+		_ = text.PrintfLine("220 localhost ESMTP test")
+		for {
+			line, err := text.ReadLine()
+			if err != nil {
+				return
+			}
+			command := strings.ToUpper(line)
+			switch {
+			case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+				_ = text.PrintfLine("250 localhost")
+			case strings.HasPrefix(command, "MAIL FROM:"):
+				delivery.From = line[len("MAIL FROM:"):]
+				_ = text.PrintfLine("250 OK")
+			case strings.HasPrefix(command, "RCPT TO:"):
+				delivery.Recipients = append(delivery.Recipients, line[len("RCPT TO:"):])
+				_ = text.PrintfLine("250 OK")
+			case command == "DATA":
+				_ = text.PrintfLine("354 End data with <CR><LF>.<CR><LF>")
+				data, err := io.ReadAll(text.DotReader())
+				if err != nil {
+					return
+				}
+				delivery.Data = string(data)
+				_ = text.PrintfLine("250 OK queued")
+			case command == "QUIT":
+				_ = text.PrintfLine("221 Bye")
+				deliveries <- delivery
+				return
+			default:
+				_ = text.PrintfLine("502 Command not implemented")
+			}
+		}
+	}()
+
+	host, port, err := net.SplitHostPort(listener.Addr().String())
+	if err != nil {
+		t.Fatalf("SplitHostPort: %v", err)
+	}
+	portNumber, err := strconv.Atoi(port)
+	if err != nil {
+		t.Fatalf("Atoi: %v", err)
+	}
+	return host, portNumber, deliveries
+}
+
+// sendAcknowledgement sends the acknowledgement of enquiry through a local SMTP listener and parses what arrived
+func sendAcknowledgement(t *testing.T, enquiry *entities.Enquiry) (smtpDelivery, *netmail.Message) {
+	t.Helper()
+	host, port, deliveries := startSMTPListener(t)
+	sites := map[string]AcknowledgementSite{
+		"SCTGULF": {
+			From:      "SCT Gulf <no-reply@sctgulf.example>",
+			ReplyTo:   "sales@sctgulf.example",
+			SiteName:  "SCT Gulf",
+			LogoURL:   "https://sctgulf.example/logo.png",
+			Signature: "SCT Gulf Sales Team\n+971 4 000 0000",
+			Localized: map[string]LocalizedBranding{
+				"ar": {SiteName: "إس سي تي الخليج", Signature: "فريق المبيعات"},
+			},
+		},
+	}
+	sender := mail.NewSMTPSender(mail.SMTPConfig{Host: host, Port: port})
+	notifier := NewAcknowledgementNotifier(sites, sender, newTestTemplates(t))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+	if err := notifier.Notify(ctx, Notification{Kind: entities.OutboxKindEnquiryCreated, Enquiry: enquiry}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	var delivery smtpDelivery
+	select {
+	case delivery = <-deliveries:
+	case <-ctx.Done():
+		t.Fatal("the SMTP listener received no message")
+	}
+	msg, err := netmail.ReadMessage(bufio.NewReader(strings.NewReader(delivery.Data)))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v\n%s", err, delivery.Data)
+	}
+	return delivery, msg
+}
+
+// acknowledgementEnquiry is an enquiry of the SCTGULF site in locale
+func acknowledgementEnquiry(locale string) *entities.Enquiry {
+	return &entities.Enquiry{
+		ID:              "enquiry-1",
+		ReferenceNumber: "SCTGULF-261018-ABC123",
+		Source:          "SCTGULF",
+		Name:            "Jane Doe",
+		Email:           "jane@example.com",
+		Subject:         "RFQ pumps",
+		Message:         "Please quote 10 pumps",
+		Status:          entities.EnquiryStatusNew,
+		Locale:          locale,
+		CreatedAt:       time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+// decodedHeader returns a header with RFC 2047 encoded words decoded
+func decodedHeader(t *testing.T, msg *netmail.Message, name string) string {
+	t.Helper()
+	value, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get(name))
+	if err != nil {
+		t.Fatalf("DecodeHeader(%s): %v", name, err)
+	}
+	return value
+}
+
+func TestAcknowledgementNotifierSendsBrandedEmail(t *testing.T) {
+	tests := []struct {
+		locale  string
+		subject string
+		text    []string
+		html    []string
+	}{
+		{
+			locale:  "en",
+			subject: "[SCTGULF-261018-ABC123] We received your enquiry: RFQ pumps",
+			text: []string{
+				"Dear Jane Doe,",
+				"Thank you for contacting SCT Gulf.",
+				"Your reference number is SCTGULF-261018-ABC123.",
+				"Please quote 10 pumps",
+				"--\nSCT Gulf Sales Team\n+971 4 000 0000",
+			},
+			html: []string{`<html dir="ltr" lang="en">`, `<img src="https://sctgulf.example/logo.png" alt="SCT Gulf"`, "<strong dir=\"ltr\">SCTGULF-261018-ABC123</strong>"},
+		},
+		{
+			locale:  "ar",
+			subject: "[SCTGULF-261018-ABC123] استلمنا استفسارك: RFQ pumps",
+			text:    []string{"مرحبًا Jane Doe،", "شكرًا لتواصلك مع إس سي تي الخليج.", "رقمك المرجعي هو SCTGULF-261018-ABC123.", "--\nفريق المبيعات"},
+			html:    []string{`<html dir="rtl" lang="ar">`, "<strong dir=\"ltr\">SCTGULF-261018-ABC123</strong>"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.locale, func(t *testing.T) {
+			delivery, msg := sendAcknowledgement(t, acknowledgementEnquiry(tt.locale))
+
+			if delivery.From != "<no-reply@sctgulf.example>" {
+				t.Errorf("MAIL FROM = %q, want the site's sender", delivery.From)
+			}
+			if len(delivery.Recipients) != 1 || delivery.Recipients[0] != "<jane@example.com>" {
+				t.Errorf("RCPT TO = %q, want only the customer", delivery.Recipients)
+			}
+			headers := map[string]string{
+				"From":             "SCT Gulf <no-reply@sctgulf.example>",
+				"To":               "jane@example.com",
+				"Reply-To":         "sales@sctgulf.example",
+				"Subject":          tt.subject,
+				"Auto-Submitted":   "auto-replied",
+				"Content-Language": tt.locale,
+			}
+			for name, want := range headers {
+				if got := decodedHeader(t, msg, name); got != want {
+					t.Errorf("%s = %q, want %q", name, got, want)
+				}
+			}
+
+			text, html := emailBodies(t, msg)
+			for _, want := range tt.text {
+				if !strings.Contains(text, want) {
+					t.Errorf("text body does not contain %q:\n%s", want, text)
+				}
+			}
+			for _, want := range tt.html {
+				if !strings.Contains(html, want) {
+					t.Errorf("HTML body does not contain %q:\n%s", want, html)
+				}
+			}
+		})
+	}
+}
+
+func TestAcknowledgementNotifierHeaderInjection(t *testing.T) {
+	enquiry := acknowledgementEnquiry("en")
+	enquiry.Name = "Jane\r\nBcc: victim@example.com"
+	enquiry.Subject = "RFQ\nBcc: victim@example.com\n\nInjected body"
+	delivery, msg := sendAcknowledgement(t, enquiry)
+
+	if len(delivery.Recipients) != 1 || delivery.Recipients[0] != "<jane@example.com>" {
+		t.Errorf("RCPT TO = %q, want only the customer", delivery.Recipients)
+	}
+	for _, injected := range []string{"Bcc", "Cc"} {
+		if value := msg.Header.Get(injected); value != "" {
+			t.Errorf("enquiry input added a %s header: %q", injected, value)
+		}
+	}
+	if got, want := decodedHeader(t, msg, "Subject"), "[SCTGULF-261018-ABC123] We received your enquiry: RFQ Bcc: victim@example.com  Injected body"; got != want {
+		t.Errorf("Subject = %q, want %q", got, want)
+	}
+	if !strings.HasPrefix(msg.Header.Get("Content-Type"), "multipart/alternative") {
+		t.Errorf("Content-Type = %q, the injected blank line ended the headers", msg.Header.Get("Content-Type"))
+	}
+
+	// In the bodies the input is only text
+	text, html := emailBodies(t, msg)
+	text, html = strings.ReplaceAll(text, "\r\n", "\n"), strings.ReplaceAll(html, "\r\n", "\n")
+	if !strings.Contains(text, "Dear Jane\nBcc: victim@example.com,") {
+		t.Errorf("text body does not quote the name as it was typed:\n%s", text)
+	}
+	if !strings.Contains(html, "RFQ\nBcc: victim@example.com\n\nInjected body") {
+		t.Errorf("HTML body does not contain the subject as typed:\n%s", html)
+	}
+}
@@ -0,0 +1,54 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/entities"
+)
+
+// DiscordNotifier posts enquiries to a Discord webhook as an embed
+type DiscordNotifier struct {
+	name       string
+	webhookURL string
+	client     *http.Client
+	templates  *Templates
+}
+
+// NewDiscordNotifier creates a notifier for a single Discord webhook
+func NewDiscordNotifier(name, webhookURL string, client *http.Client, templates *Templates) *DiscordNotifier {
+	return &DiscordNotifier{
+		name:       name,
+		webhookURL: webhookURL,
+		client:     client,
+		templates:  templates,
+	}
+}
+
+// Name identifies the notifier
+func (n *DiscordNotifier) Name() string {
+	return n.name
+}
+
+// Notify posts the enquiry as an embed message, or a short note for a resubmission
+func (n *DiscordNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.IsResubmission() {
+		return postJSON(ctx, n.client, n.webhookURL, renderDiscordResubmission(notification.Enquiry, notification.Resubmission, n.templates.links), nil)
+	}
+	rendered, err := n.templates.Render(ChannelDiscord, notification.Enquiry)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.client, n.webhookURL, json.RawMessage(rendered.Body), nil)
+}
+
+func renderDiscordResubmission(enquiry *entities.Enquiry, resubmission *entities.EnquiryResubmission, links *attachment.Links) map[string]interface{} {
+	return map[string]interface{}{
+		"allowed_mentions": map[string]interface{}{
+			"parse": []string{},
+		},
+		"content": resubmissionText(enquiry, resubmission, links, escapeDiscord, markdownLink),
+	}
+}
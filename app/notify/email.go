@@ -0,0 +1,78 @@
+package notify
+
+import (
+	"context"
+
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/mail"
+)
+
+// EmailNotifier sends enquiries to a fixed list of recipients
+type EmailNotifier struct {
+	name      string
+	from      string
+	to        []string
+	sender    mail.Sender
+	templates *Templates
+}
+
+// NewEmailNotifier creates a notifier that emails the given recipients
+func NewEmailNotifier(name, from string, to []string, sender mail.Sender, templates *Templates) *EmailNotifier {
+	return &EmailNotifier{
+		name:      name,
+		from:      from,
+		to:        to,
+		sender:    sender,
+		templates: templates,
+	}
+}
+
+// Name identifies the notifier
+func (n *EmailNotifier) Name() string {
+	return n.name
+}
+
+// Notify emails the enquiry, replies go straight to the customer
+func (n *EmailNotifier) Notify(ctx context.Context, notification Notification) error {
+	var msg *mail.Message
+	var err error
+	if notification.IsResubmission() {
+		msg, err = n.renderEmailResubmission(notification.Enquiry, notification.Resubmission)
+	} else {
+		msg, err = n.renderEmailMessage(notification.Enquiry)
+	}
+	if err != nil {
+		return err
+	}
+	msg.From = n.from
+	msg.To = n.to
+	return n.sender.Send(ctx, msg)
+}
+
+func (n *EmailNotifier) renderEmailMessage(enquiry *entities.Enquiry) (*mail.Message, error) {
+	rendered, err := n.templates.Render(ChannelEmail, enquiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mail.Message{
+		ReplyTo:  enquiry.Email,
+		Subject:  rendered.Subject,
+		TextBody: rendered.Text,
+		HTMLBody: rendered.Body,
+	}, nil
+}
+
+// renderEmailResubmission uses the subject of the original email so mail clients group them
+func (n *EmailNotifier) renderEmailResubmission(enquiry *entities.Enquiry, resubmission *entities.EnquiryResubmission) (*mail.Message, error) {
+	rendered, err := n.templates.Render(ChannelEmail, enquiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &mail.Message{
+		ReplyTo:  enquiry.Email,
+		Subject:  "Re: " + rendered.Subject,
+		TextBody: resubmissionText(enquiry, resubmission, n.templates.links, plainText, plainLink) + "\n",
+	}, nil
+}
@@ -0,0 +1,84 @@
+package notify
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Enquiry fields are typed by anonymous visitors, so every channel encodes them for its own markup.
+// HTML emails are escaped by html/template.
+
+// slackEscaper escapes the control characters of Slack mrkdwn. Escaped, <!channel> can no longer
+// ping the channel and <https://evil|click here> can no longer hide a link behind other text.
+var slackEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// EscapeSlack makes user input safe for a Slack mrkdwn text
+func EscapeSlack(s string) string {
+	return slackEscaper.Replace(s)
+}
+
+// Teams and Discord read different subsets of Markdown. Only the characters each one turns into
+// formatting are escaped, a backslash in front of anything else shows up in the message.
+
+// teamsEscaper escapes the inline markup of Adaptive Card text: bold, italic and links
+var teamsEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"[", `\[`,
+	"]", `\]`,
+)
+
+// discordEscaper escapes the inline markup of Discord messages. Mentions are also disabled through
+// allowed_mentions, the escape keeps <@id>, @everyone and @here from rendering as mentions.
+var discordEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	"*", `\*`,
+	"_", `\_`,
+	"~", `\~`,
+	"`", "\\`",
+	"|", `\|`,
+	"[", `\[`,
+	"]", `\]`,
+	"<", `\<`,
+	"@everyone", `\@everyone`,
+	"@here", `\@here`,
+)
+
+var (
+	// teamsBlockPattern matches a list item at the start of a line
+	teamsBlockPattern = regexp.MustCompile(`(?m)^([ \t]*)([-+])([ \t])`)
+	// discordBlockPattern matches a list item, heading, subtext or quote at the start of a line
+	discordBlockPattern = regexp.MustCompile(`(?m)^([ \t]*)(-#|>>>|[-+>]|#{1,3})([ \t])`)
+	// orderedListPattern matches a numbered list item at the start of a line
+	orderedListPattern = regexp.MustCompile(`(?m)^([ \t]*\d{1,9})([.)])([ \t])`)
+)
+
+// escapeTeams makes user input safe for the Markdown of Teams Adaptive Cards
+func escapeTeams(s string) string {
+	return escapeBlocks(teamsEscaper.Replace(s), teamsBlockPattern)
+}
+
+// escapeDiscord makes user input safe for Discord Markdown
+func escapeDiscord(s string) string {
+	return escapeBlocks(discordEscaper.Replace(s), discordBlockPattern)
+}
+
+// escapeBlocks escapes the markers that only count at the start of a line, so a line such as
+// "- item" stays text while "+971 50 123 4567" is left alone
+func escapeBlocks(s string, pattern *regexp.Regexp) string {
+	s = pattern.ReplaceAllString(s, `${1}\${2}${3}`)
+	return orderedListPattern.ReplaceAllString(s, `${1}\${2}${3}`)
+}
+
+// mailtoURL builds a mailto: link to a single address, escaping ? and # so the
+// address cannot add recipients or prefill the reply
+func mailtoURL(email string) string {
+	return "mailto:" + url.PathEscape(email)
+}
+
+// plainText leaves user input as it is, for channels without markup
+func plainText(s string) string {
+	return s
+}
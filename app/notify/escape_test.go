@@ -0,0 +1,103 @@
+package notify
+
+import "testing"
+
+func TestEscapeSlack(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"channel ping", "<!channel> urgent", "&lt;!channel&gt; urgent"},
+		{"disguised link", "<https://evil|click here>", "&lt;https://evil|click here&gt;"},
+		{"user mention", "<@U123>", "&lt;@U123&gt;"},
+		{"ampersand", "Smith & Sons", "Smith &amp; Sons"},
+		{"already escaped entity", "&lt;", "&amp;lt;"},
+		{"plain text", "Jane O'Neil, +971 50 123 4567", "Jane O'Neil, +971 50 123 4567"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := EscapeSlack(tt.input); got != tt.want {
+				t.Errorf("EscapeSlack(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeTeams(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"markdown link", "[click here](https://evil)", `\[click here\](https://evil)`},
+		{"bold and italic", "**free** _offer_", `\*\*free\*\* \_offer\_`},
+		{"backslash", `C:\temp`, `C:\\temp`},
+		{"list item", "- one\n- two", "\\- one\n\\- two"},
+		{"numbered list item", "1. one\n2) two", "1\\. one\n2\\) two"},
+		// Teams has no mentions, quotes, headings or strike-through in card text
+		{"email address", "jane.doe+rfq@example.com", "jane.doe+rfq@example.com"},
+		{"phone number", "+971 (50) 123-4567", "+971 (50) 123-4567"},
+		{"company name", "Smith & Sons (Pvt.) Ltd - Dubai!", "Smith & Sons (Pvt.) Ltd - Dubai!"},
+		{"angle brackets", "<b>hi</b> > 3", "<b>hi</b> > 3"},
+		{"quote and heading markers", "> quoted\n# title", "> quoted\n# title"},
+		{"mention", "@everyone", "@everyone"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeTeams(tt.input); got != tt.want {
+				t.Errorf("escapeTeams(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEscapeDiscord(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  string
+	}{
+		{"markdown link", "[click here](https://evil)", `\[click here\](https://evil)`},
+		{"everyone and here", "@everyone @here", `\@everyone \@here`},
+		{"user mention", "<@123456>", `\<@123456>`},
+		{"role mention", "<@&42>", `\<@&42>`},
+		{"spoiler and code", "||secret|| `code` ~~gone~~", "\\|\\|secret\\|\\| \\`code\\` \\~\\~gone\\~\\~"},
+		{"bold", "**free**", `\*\*free\*\*`},
+		{"heading", "# title\n## sub", "\\# title\n\\## sub"},
+		{"subtext", "-# small", `\-# small`},
+		{"quote", "> quoted\n>>> all of it", "\\> quoted\n\\>>> all of it"},
+		{"list items", "- one\n+ two\n3. three", "\\- one\n\\+ two\n3\\. three"},
+		{"email address", "jane.doe+rfq@example.com", "jane.doe+rfq@example.com"},
+		{"phone number", "+971 (50) 123-4567", "+971 (50) 123-4567"},
+		{"company name", "Smith & Sons (Pvt.) Ltd - Dubai!", "Smith & Sons (Pvt.) Ltd - Dubai!"},
+		{"hash inside a line", "Order #42", "Order #42"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := escapeDiscord(tt.input); got != tt.want {
+				t.Errorf("escapeDiscord(%q) = %q, want %q", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMailtoURL(t *testing.T) {
+	tests := []struct {
+		name  string
+		email string
+		want  string
+	}{
+		{"plain address", "jane@example.com", "mailto:jane@example.com"},
+		{"added recipient", "jane@example.com?cc=boss@example.com", "mailto:jane@example.com%3Fcc=boss@example.com"},
+		{"prefilled body", "jane@example.com?subject=x&body=y", "mailto:jane@example.com%3Fsubject=x&body=y"},
+		{"fragment", "jane@example.com#x", "mailto:jane@example.com%23x"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := mailtoURL(tt.email); got != tt.want {
+				t.Errorf("mailtoURL(%q) = %q, want %q", tt.email, got, tt.want)
+			}
+		})
+	}
+}
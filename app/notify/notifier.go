@@ -0,0 +1,238 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/entities"
+)
+
+var (
+	// ErrUnknownNotifier is returned when a destination does not name a configured notifier
+	ErrUnknownNotifier = errors.New("unknown notifier")
+	// ErrFollowUpNotSupported is returned when a follow-up is sent to a notifier that is not a FollowUpNotifier
+	ErrFollowUpNotSupported = errors.New("notifier does not deliver follow-ups")
+)
+
+// Notification is the message handed to every notifier
+type Notification struct {
+	// Kind is one of the entities.OutboxKind values, empty means a new enquiry
+	Kind    string
+	Enquiry *entities.Enquiry
+	// Resubmission is set for entities.OutboxKindEnquiryResubmitted
+	Resubmission *entities.EnquiryResubmission
+	// StatusChange is set for entities.OutboxKindEnquiryStatusChanged
+	StatusChange *entities.EnquiryStatusChange
+	// Note is set for entities.OutboxKindEnquiryNoteAdded
+	Note *entities.EnquiryNote
+	// Assignment is set for entities.OutboxKindEnquiryAssigned
+	Assignment *entities.EnquiryAssignment
+}
+
+// IsResubmission reports whether the notification is a note about a repeated enquiry
+func (n Notification) IsResubmission() bool {
+	return n.Kind == entities.OutboxKindEnquiryResubmitted && n.Resubmission != nil
+}
+
+// IsFollowUp reports whether the notification is a status change, note or assignment, which only FollowUpNotifiers deliver
+func (n Notification) IsFollowUp() bool {
+	switch n.Kind {
+	case entities.OutboxKindEnquiryStatusChanged, entities.OutboxKindEnquiryNoteAdded, entities.OutboxKindEnquiryAssigned:
+		return true
+	}
+	return false
+}
+
+// resubmissionText describes a resubmission in a single sentence and lists the files sent with it.
+// escape encodes the customer's input for the channel, link formats a signed download link of a file.
+func resubmissionText(enquiry *entities.Enquiry, resubmission *entities.EnquiryResubmission, links *attachment.Links, escape func(string) string, link func(url, name string) string) string {
+	text := fmt.Sprintf("%s resubmitted enquiry %s on %s at %s",
+		escape(enquiry.Name),
+		enquiry.ReferenceNumber,
+		resubmission.Source,
+		resubmission.ReceivedAt.UTC().Format("2006-01-02 15:04 UTC"),
+	)
+	for _, file := range resubmission.Attachments {
+		name := escape(file.FileName)
+		if url := links.URL(enquiry.ID, file); url != "" {
+			name = link(url, name)
+		}
+		text += fmt.Sprintf("\n• %s (%s)", name, attachment.FormatSize(file.Size))
+	}
+	return text
+}
+
+// slackLink formats a link in Slack mrkdwn, name is already escaped
+func slackLink(url, name string) string {
+	return fmt.Sprintf("<%s|%s>", url, name)
+}
+
+// markdownLink formats a link in the Markdown of Teams and Discord, name is already escaped
+func markdownLink(url, name string) string {
+	return fmt.Sprintf("[%s](%s)", name, url)
+}
+
+// plainLink follows the name with the link, for channels without markup
+func plainLink(url, name string) string {
+	return name + ": " + url
+}
+
+// Notifier delivers notifications to a single channel, e.g. one Slack webhook or one mailbox.
+// Each notifier renders the notification in the format of its channel.
+type Notifier interface {
+	// Name identifies the notifier in configuration and logs
+	Name() string
+	// Notify renders and delivers the notification
+	Notify(ctx context.Context, notification Notification) error
+}
+
+// FollowUpNotifier is a notifier that can also keep the sales team posted on an enquiry it notified,
+// e.g. with thread replies about status changes and notes
+type FollowUpNotifier interface {
+	Notifier
+	// FollowUp delivers a notification for which IsFollowUp is true
+	FollowUp(ctx context.Context, notification Notification) error
+}
+
+// Acknowledger is a notifier that writes to the customer who sent the enquiry instead of the sales team.
+// It is never a routed destination, it only gets the OutboxKindEnquiryAcknowledged notification of a new enquiry.
+type Acknowledger interface {
+	Notifier
+	// Acknowledges reports whether the customer of the enquiry gets an acknowledgement, e.g. whether it is turned on for the site
+	Acknowledges(enquiry *entities.Enquiry) bool
+}
+
+// Dispatcher resolves the destinations of an enquiry and delivers to them by name
+type Dispatcher struct {
+	notifiers map[string]Notifier
+	// order keeps the registration order for deterministic delivery
+	order []string
+	// acknowledgers are kept out of order, they are only addressed by AcknowledgementDestinations
+	acknowledgers []string
+	router        *Router
+	logger        *zap.Logger
+}
+
+// NewDispatcher creates a dispatcher for the given notifiers.
+// With a router each enquiry only goes to its routed destinations, without one it goes to every notifier.
+func NewDispatcher(notifiers []Notifier, router *Router, logger *zap.Logger) (*Dispatcher, error) {
+	d := &Dispatcher{
+		notifiers: make(map[string]Notifier, len(notifiers)),
+		router:    router,
+		logger:    logger,
+	}
+	for _, notifier := range notifiers {
+		if _, exists := d.notifiers[notifier.Name()]; exists {
+			return nil, fmt.Errorf("duplicate notifier name: %s", notifier.Name())
+		}
+		d.notifiers[notifier.Name()] = notifier
+		if _, ok := notifier.(Acknowledger); ok {
+			d.acknowledgers = append(d.acknowledgers, notifier.Name())
+			continue
+		}
+		d.order = append(d.order, notifier.Name())
+	}
+
+	if router != nil {
+		for _, destination := range router.AllDestinations() {
+			notifier, ok := d.notifiers[destination]
+			if !ok {
+				return nil, fmt.Errorf("route destination %q is not a configured notifier", destination)
+			}
+			if _, ok := notifier.(Acknowledger); ok {
+				return nil, fmt.Errorf("route destination %q writes to customers and cannot be routed to", destination)
+			}
+		}
+	}
+	return d, nil
+}
+
+// Destinations returns the names of the notifiers the enquiry should be sent to
+func (d *Dispatcher) Destinations(enquiry *entities.Enquiry) []string {
+	if d.router == nil {
+		return d.order
+	}
+
+	destinations := d.router.Destinations(enquiry)
+	if len(destinations) == 0 {
+		d.logger.Warn("No notification route matched", zap.String("enquiry_id", enquiry.ID))
+	}
+	return destinations
+}
+
+// FollowUpDestinations returns the destinations of the enquiry that deliver follow-ups
+func (d *Dispatcher) FollowUpDestinations(enquiry *entities.Enquiry) []string {
+	var destinations []string
+	for _, name := range d.Destinations(enquiry) {
+		if _, ok := d.notifiers[name].(FollowUpNotifier); ok {
+			destinations = append(destinations, name)
+		}
+	}
+	return destinations
+}
+
+// AcknowledgementDestinations returns the acknowledgers that write to the customer of the enquiry
+func (d *Dispatcher) AcknowledgementDestinations(enquiry *entities.Enquiry) []string {
+	var destinations []string
+	for _, name := range d.acknowledgers {
+		if d.notifiers[name].(Acknowledger).Acknowledges(enquiry) {
+			destinations = append(destinations, name)
+		}
+	}
+	return destinations
+}
+
+// NotifyDestination sends the notification to a single named notifier
+func (d *Dispatcher) NotifyDestination(ctx context.Context, name string, notification Notification) error {
+	notifier, ok := d.notifiers[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownNotifier, name)
+	}
+	if notification.IsFollowUp() {
+		followUpNotifier, ok := notifier.(FollowUpNotifier)
+		if !ok {
+			return fmt.Errorf("%w: %s", ErrFollowUpNotSupported, name)
+		}
+		return followUpNotifier.FollowUp(ctx, notification)
+	}
+	return notifier.Notify(ctx, notification)
+}
+
+// postJSON sends the payload as JSON and expects a 2xx response
+func postJSON(ctx context.Context, client *http.Client, url string, payload interface{}, headers map[string]string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("error marshalling JSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for key, value := range headers {
+		req.Header.Set(key, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("error sending message: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("failed to send notification, status code: %d, body: %s", resp.StatusCode, respBody)
+	}
+	// Drain the body so the connection can be reused
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return nil
+}
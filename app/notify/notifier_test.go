@@ -0,0 +1,239 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/entities"
+)
+
+// resubmissionWithFile is a resubmission that brought one file with a hostile name
+func resubmissionWithFile() *entities.EnquiryResubmission {
+	return &entities.EnquiryResubmission{
+		Source:     "SCTGULF",
+		Subject:    "RFQ pumps",
+		ReceivedAt: time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC),
+		Attachments: []entities.EnquiryAttachment{
+			{ID: "file-1", FileName: "rev_2 <b>.pdf", ContentType: "application/pdf", Size: 2048, Key: "enquiries/enquiry-1/file-1"},
+		},
+	}
+}
+
+func TestResubmissionTextLinksFiles(t *testing.T) {
+	links := attachment.NewLinks("https://api.example.com", "secret", time.Hour)
+	link := "https://api.example.com/attachments/enquiry-1/file-1?expires="
+	tests := []struct {
+		name   string
+		escape func(string) string
+		format func(url, name string) string
+		want   string
+	}{
+		{"Slack", EscapeSlack, slackLink, "\n• <" + link},
+		{"Teams", escapeTeams, markdownLink, "\n• [rev\\_2 <b>.pdf](" + link},
+		{"Discord", escapeDiscord, markdownLink, "\n• [rev\\_2 \\<b>.pdf](" + link},
+		{"email", plainText, plainLink, "\n• rev_2 <b>.pdf: " + link},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			text := resubmissionText(hostileEnquiry(), resubmissionWithFile(), links, tt.escape, tt.format)
+			if !strings.Contains(text, tt.want) {
+				t.Errorf("text does not link the file as %q:\n%s", tt.want, text)
+			}
+			if !strings.HasSuffix(text, "(2 KB)") {
+				t.Errorf("text does not end with the file size:\n%s", text)
+			}
+		})
+	}
+
+	// Without download links the files are only named
+	text := resubmissionText(hostileEnquiry(), resubmissionWithFile(), nil, EscapeSlack, slackLink)
+	if !strings.HasSuffix(text, "\n• rev_2 &lt;b&gt;.pdf (2 KB)") {
+		t.Errorf("text without links = %q", text)
+	}
+}
+
+func TestWebhookNotifierLinksResubmittedFiles(t *testing.T) {
+	bodies := make(chan []byte, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		bodies <- body
+	}))
+	defer server.Close()
+
+	links := attachment.NewLinks("https://api.example.com", "secret", time.Hour)
+	notifier := NewWebhookNotifier("crm", server.URL, "", server.Client(), links)
+	err := notifier.Notify(context.Background(), Notification{
+		Kind:         entities.OutboxKindEnquiryResubmitted,
+		Enquiry:      hostileEnquiry(),
+		Resubmission: resubmissionWithFile(),
+	})
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+
+	var payload struct {
+		Event        string `json:"event"`
+		Resubmission struct {
+			Attachments []map[string]interface{} `json:"attachments"`
+		} `json:"resubmission"`
+	}
+	if err := json.Unmarshal(<-bodies, &payload); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if payload.Event != WebhookEventEnquiryResubmitted {
+		t.Errorf("event = %q, want %q", payload.Event, WebhookEventEnquiryResubmitted)
+	}
+	if len(payload.Resubmission.Attachments) != 1 {
+		t.Fatalf("attachments = %v, want the file", payload.Resubmission.Attachments)
+	}
+	file := payload.Resubmission.Attachments[0]
+	if url, _ := file["downloadUrl"].(string); !strings.HasPrefix(url, "https://api.example.com/attachments/enquiry-1/file-1?") {
+		t.Errorf("downloadUrl = %q", url)
+	}
+	if _, ok := file["key"]; ok {
+		t.Error("the payload reveals the storage key")
+	}
+}
+
+// namedNotifier records the notifications it gets, it delivers no follow-ups
+type namedNotifier struct {
+	name  string
+	kinds []string
+}
+
+func (n *namedNotifier) Name() string {
+	return n.name
+}
+
+func (n *namedNotifier) Notify(ctx context.Context, notification Notification) error {
+	n.kinds = append(n.kinds, notification.Kind)
+	return nil
+}
+
+func TestDispatcher(t *testing.T) {
+	if _, err := NewDispatcher([]Notifier{&namedNotifier{name: "sales"}, &namedNotifier{name: "sales"}}, nil, zap.NewNop()); err == nil {
+		t.Error("two notifiers with one name were accepted")
+	}
+
+	sales, crm := &namedNotifier{name: "sales"}, &namedNotifier{name: "crm"}
+	dispatcher, err := NewDispatcher([]Notifier{sales, crm}, nil, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+	enquiry := hostileEnquiry()
+
+	// Without a router every notifier gets every enquiry, in the configured order
+	if got := dispatcher.Destinations(enquiry); !reflect.DeepEqual(got, []string{"sales", "crm"}) {
+		t.Errorf("Destinations = %v, want [sales crm]", got)
+	}
+	if got := dispatcher.FollowUpDestinations(enquiry); len(got) != 0 {
+		t.Errorf("FollowUpDestinations = %v, want none", got)
+	}
+
+	ctx := context.Background()
+	if err := dispatcher.NotifyDestination(ctx, "crm", Notification{Kind: entities.OutboxKindEnquiryCreated, Enquiry: enquiry}); err != nil {
+		t.Errorf("NotifyDestination: %v", err)
+	}
+	if !reflect.DeepEqual(crm.kinds, []string{entities.OutboxKindEnquiryCreated}) || len(sales.kinds) != 0 {
+		t.Errorf("delivered sales %v, crm %v, want only crm notified", sales.kinds, crm.kinds)
+	}
+	if err := dispatcher.NotifyDestination(ctx, "fax", Notification{Enquiry: enquiry}); !errors.Is(err, ErrUnknownNotifier) {
+		t.Errorf("unknown destination: %v, want ErrUnknownNotifier", err)
+	}
+	followUp := Notification{Kind: entities.OutboxKindEnquiryNoteAdded, Enquiry: enquiry, Note: &entities.EnquiryNote{Text: "Called back"}}
+	if err := dispatcher.NotifyDestination(ctx, "crm", followUp); !errors.Is(err, ErrFollowUpNotSupported) {
+		t.Errorf("follow-up to a webhook: %v, want ErrFollowUpNotSupported", err)
+	}
+}
+
+func TestWebhookBackendsPostJSON(t *testing.T) {
+	templates := newTestTemplates(t)
+	tests := []struct {
+		name     string
+		notifier func(url string, client *http.Client) Notifier
+	}{
+		{"Slack", func(url string, client *http.Client) Notifier {
+			return NewSlackNotifier("slack", url, client, templates)
+		}},
+		{"Teams", func(url string, client *http.Client) Notifier {
+			return NewTeamsNotifier("teams", url, client, templates)
+		}},
+		{"Discord", func(url string, client *http.Client) Notifier {
+			return NewDiscordNotifier("discord", url, client, templates)
+		}},
+		{"webhook", func(url string, client *http.Client) Notifier { return NewWebhookNotifier("crm", url, "", client, nil) }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := http.StatusOK
+			var contentType string
+			var body []byte
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				contentType = r.Header.Get("Content-Type")
+				body, _ = io.ReadAll(r.Body)
+				w.WriteHeader(status)
+				_, _ = io.WriteString(w, "channel_not_found")
+			}))
+			defer server.Close()
+			notifier := tt.notifier(server.URL, server.Client())
+
+			if err := notifier.Notify(context.Background(), Notification{Enquiry: hostileEnquiry()}); err != nil {
+				t.Fatalf("Notify: %v", err)
+			}
+			if contentType != "application/json" || !json.Valid(body) {
+				t.Errorf("posted %q: %s, want a JSON document", contentType, body)
+			}
+			if !strings.Contains(string(body), "SCTGULF-261018-ABC123") {
+				t.Errorf("posted body does not name the enquiry: %s", body)
+			}
+
+			// A rejected post is an error, so the outbox retries it
+			status = http.StatusInternalServerError
+			err := notifier.Notify(context.Background(), Notification{Enquiry: hostileEnquiry()})
+			if err == nil || !strings.Contains(err.Error(), "500") || !strings.Contains(err.Error(), "channel_not_found") {
+				t.Errorf("Notify after a 500 = %v, want the status and body", err)
+			}
+		})
+	}
+}
+
+func TestWebhookNotifierSignsBody(t *testing.T) {
+	var signature string
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(WebhookSignatureHeader)
+		body, _ = io.ReadAll(r.Body)
+	}))
+	defer server.Close()
+
+	notifier := NewWebhookNotifier("crm", server.URL, "webhook-secret", server.Client(), nil)
+	if err := notifier.Notify(context.Background(), Notification{Enquiry: hostileEnquiry()}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if want := "sha256=" + SignWebhookBody("webhook-secret", body); signature != want {
+		t.Errorf("%s = %q, want %q", WebhookSignatureHeader, signature, want)
+	}
+	// HMAC-SHA256 of "body" with the key "secret"
+	if got := SignWebhookBody("secret", []byte("body")); got != "dc46983557fea127b43af721467eb9b3fde2338fe3e14f51952aa8478c13d355" {
+		t.Errorf("SignWebhookBody = %s", got)
+	}
+
+	var payload webhookPayload
+	if err := json.Unmarshal(body, &payload); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if payload.Event != WebhookEventEnquiryCreated || payload.Enquiry.ID != "enquiry-1" || payload.Enquiry.Name != "<!channel> @everyone" {
+		t.Errorf("payload = %+v, want the created enquiry as submitted", payload)
+	}
+}
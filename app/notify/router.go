@@ -0,0 +1,96 @@
+package notify
+
+import (
+	"slices"
+	"strings"
+
+	"sct-backend-service/app/entities"
+)
+
+// Route sends matching enquiries to a set of destinations.
+// Empty criteria match everything; a route matches when all non-empty criteria match.
+type Route struct {
+	Name string
+	// Sources lists WebsiteSource values
+	Sources []string
+	// SubjectKeywords are matched case-insensitively anywhere in the subject
+	SubjectKeywords []string
+	// Countries lists ISO 3166-1 alpha-2 codes
+	Countries []string
+	// Destinations are notifier names
+	Destinations []string
+	// Final stops evaluation of the remaining routes when this route matches
+	Final bool
+}
+
+// Matches reports whether the enquiry satisfies every criterion of the route
+func (r Route) Matches(enquiry *entities.Enquiry) bool {
+	if len(r.Sources) > 0 && !slices.Contains(r.Sources, enquiry.Source) {
+		return false
+	}
+	if len(r.Countries) > 0 && !slices.ContainsFunc(r.Countries, func(country string) bool {
+		return strings.EqualFold(country, enquiry.Country)
+	}) {
+		return false
+	}
+	if len(r.SubjectKeywords) > 0 && !slices.ContainsFunc(r.SubjectKeywords, func(keyword string) bool {
+		return containsFold(enquiry.Subject, keyword)
+	}) {
+		return false
+	}
+	return true
+}
+
+// Router picks the destinations of an enquiry from an ordered routing table
+type Router struct {
+	routes   []Route
+	fallback []string
+}
+
+// NewRouter creates a router; fallback is used when no route matches
+func NewRouter(routes []Route, fallback []string) *Router {
+	return &Router{
+		routes:   routes,
+		fallback: fallback,
+	}
+}
+
+// Destinations returns the notifier names the enquiry should be sent to.
+// Routes are evaluated in order and the destinations of all matching routes are combined.
+func (r *Router) Destinations(enquiry *entities.Enquiry) []string {
+	var destinations []string
+	matched := false
+	for _, route := range r.routes {
+		if !route.Matches(enquiry) {
+			continue
+		}
+		matched = true
+		for _, destination := range route.Destinations {
+			if !slices.Contains(destinations, destination) {
+				destinations = append(destinations, destination)
+			}
+		}
+		if route.Final {
+			break
+		}
+	}
+
+	if !matched {
+		return r.fallback
+	}
+	return destinations
+}
+
+// AllDestinations returns every notifier name referenced by the routing table
+func (r *Router) AllDestinations() []string {
+	destinations := slices.Clone(r.fallback)
+	for _, route := range r.routes {
+		destinations = append(destinations, route.Destinations...)
+	}
+	slices.Sort(destinations)
+	return slices.Compact(destinations)
+}
+
+func containsFold(s, substr string) bool {
+	return strings.Contains(strings.ToLower(s), strings.ToLower(substr))
+}
@@ -0,0 +1,64 @@
+package notify
+
+import (
+	"reflect"
+	"testing"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/entities"
+)
+
+func TestRouterDestinations(t *testing.T) {
+	router := NewRouter([]Route{
+		{Name: "spam-words", SubjectKeywords: []string{"casino"}, Destinations: []string{"moderation"}, Final: true},
+		{Name: "gulf", Sources: []string{"SCTGULF"}, Destinations: []string{"gulf-slack", "gulf-email"}},
+		{Name: "saudi", Countries: []string{"sa"}, Destinations: []string{"ksa-teams"}},
+		{Name: "gulf-rfq", Sources: []string{"SCTGULF"}, SubjectKeywords: []string{"rfq", "quote"}, Destinations: []string{"gulf-slack", "crm"}},
+	}, []string{"sales"})
+
+	tests := []struct {
+		name    string
+		source  string
+		subject string
+		country string
+		want    []string
+	}{
+		{"no route matches", "AGEM", "Hello", "IN", []string{"sales"}},
+		{"source", "SCTGULF", "Hello", "AE", []string{"gulf-slack", "gulf-email"}},
+		{"country ignores case", "AGEM", "Hello", "SA", []string{"ksa-teams"}},
+		{"matching routes are combined without repeats", "SCTGULF", "Please QUOTE", "SA", []string{"gulf-slack", "gulf-email", "ksa-teams", "crm"}},
+		{"every criterion of a route must match", "AGEM", "RFQ pumps", "IN", []string{"sales"}},
+		{"final route stops evaluation", "SCTGULF", "Casino RFQ", "SA", []string{"moderation"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enquiry := &entities.Enquiry{Source: tt.source, Subject: tt.subject, Country: tt.country}
+			if got := router.Destinations(enquiry); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Destinations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	want := []string{"crm", "gulf-email", "gulf-slack", "ksa-teams", "moderation", "sales"}
+	if got := router.AllDestinations(); !reflect.DeepEqual(got, want) {
+		t.Errorf("AllDestinations = %v, want %v", got, want)
+	}
+}
+
+func TestDispatcherChecksRouteDestinations(t *testing.T) {
+	notifiers := []Notifier{&namedNotifier{name: "sales"}, &namedNotifier{name: "crm"}}
+	router := NewRouter([]Route{{Sources: []string{"SCTGULF"}, Destinations: []string{"crm"}}}, []string{"sales"})
+	dispatcher, err := NewDispatcher(notifiers, router, zap.NewNop())
+	if err != nil {
+		t.Fatalf("NewDispatcher: %v", err)
+	}
+	if got := dispatcher.Destinations(&entities.Enquiry{Source: "SCTGULF"}); !reflect.DeepEqual(got, []string{"crm"}) {
+		t.Errorf("Destinations = %v, want the routed [crm]", got)
+	}
+
+	typo := NewRouter([]Route{{Destinations: []string{"crm-slack"}}}, []string{"sales"})
+	if _, err := NewDispatcher(notifiers, typo, zap.NewNop()); err == nil {
+		t.Error("a route to a notifier that is not configured was accepted")
+	}
+}
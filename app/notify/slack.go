@@ -0,0 +1,65 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/entities"
+)
+
+// SlackNotifier posts enquiries to a Slack incoming webhook
+type SlackNotifier struct {
+	name       string
+	webhookURL string
+	client     *http.Client
+	templates  *Templates
+}
+
+// NewSlackNotifier creates a notifier for a single Slack incoming webhook
+func NewSlackNotifier(name, webhookURL string, client *http.Client, templates *Templates) *SlackNotifier {
+	return &SlackNotifier{
+		name:       name,
+		webhookURL: webhookURL,
+		client:     client,
+		templates:  templates,
+	}
+}
+
+// Name identifies the notifier
+func (n *SlackNotifier) Name() string {
+	return n.name
+}
+
+// Notify posts the enquiry as a Block Kit message, or a short note for a resubmission
+func (n *SlackNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.IsResubmission() {
+		return postJSON(ctx, n.client, n.webhookURL, renderSlackResubmission(notification.Enquiry, notification.Resubmission, n.templates.links), nil)
+	}
+	rendered, err := n.templates.Render(ChannelSlack, notification.Enquiry)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.client, n.webhookURL, json.RawMessage(rendered.Body), nil)
+}
+
+func renderSlackResubmission(enquiry *entities.Enquiry, resubmission *entities.EnquiryResubmission, links *attachment.Links) map[string]interface{} {
+	return map[string]interface{}{
+		"text": fmt.Sprintf(":repeat: %s", resubmissionText(enquiry, resubmission, links, EscapeSlack, slackLink)),
+	}
+}
+
+// slackPhoneText links the normalized number for calling and, when possible, for WhatsApp
+func slackPhoneText(enquiry *entities.Enquiry) string {
+	telURI := enquiry.TelURI()
+	if telURI == "" {
+		return EscapeSlack(enquiry.PhoneNumber)
+	}
+	text := fmt.Sprintf("<%s|%s>", telURI, enquiry.PhoneE164)
+	if whatsAppURL := enquiry.WhatsAppURL(); whatsAppURL != "" {
+		text += fmt.Sprintf(" · <%s|WhatsApp>", whatsAppURL)
+	}
+	return text
+}
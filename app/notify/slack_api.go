@@ -0,0 +1,218 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/entities"
+)
+
+// SlackMessageStore records the Slack messages posted about enquiries
+type SlackMessageStore interface {
+	SaveSlackMessage(ctx context.Context, id string, message entities.SlackMessage) error
+}
+
+// PendingNotifications tells whether the outbox still has to deliver a notification
+type PendingNotifications interface {
+	HasPending(ctx context.Context, enquiryID, kind, destination string) (bool, error)
+}
+
+// ErrSlackMessagePending is returned for a follow-up of an enquiry whose message is still waiting in the outbox.
+// It is not permanent, the follow-up is retried until the message is out and has a thread.
+var ErrSlackMessagePending = errors.New("slack message of the enquiry is not posted yet")
+
+// SlackAPINotifier posts enquiries to a channel through the Slack Web API with a bot token.
+// Unlike an incoming webhook it remembers the message it posted: resubmissions, status changes
+// and notes become thread replies, and status changes update the message header.
+type SlackAPINotifier struct {
+	name      string
+	channel   string
+	client    *SlackClient
+	templates *Templates
+	messages  SlackMessageStore
+	pending   PendingNotifications
+	logger    *zap.Logger
+}
+
+// NewSlackAPINotifier creates a notifier posting to a single Slack channel
+func NewSlackAPINotifier(name, channel string, client *SlackClient, templates *Templates, messages SlackMessageStore, pending PendingNotifications, logger *zap.Logger) *SlackAPINotifier {
+	return &SlackAPINotifier{
+		name:      name,
+		channel:   channel,
+		client:    client,
+		templates: templates,
+		messages:  messages,
+		pending:   pending,
+		logger:    logger,
+	}
+}
+
+// Name identifies the notifier
+func (n *SlackAPINotifier) Name() string {
+	return n.name
+}
+
+// Notify posts the enquiry, a resubmission is replied in the thread of the original message
+func (n *SlackAPINotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.IsResubmission() {
+		return n.reply(ctx, notification.Enquiry, fmt.Sprintf(":repeat: %s", resubmissionText(notification.Enquiry, notification.Resubmission, n.templates.links, EscapeSlack, slackLink)))
+	}
+	return n.postEnquiry(ctx, notification.Enquiry)
+}
+
+// FollowUp replies to the enquiry's message with the status change, note or assignment.
+// Status changes and assignments also update the message, so it shows the new status and assignee.
+// Enquiries this notifier will never post have no thread and get no follow-ups, while a message
+// that is still waiting in the outbox makes the follow-up wait as well.
+func (n *SlackAPINotifier) FollowUp(ctx context.Context, notification Notification) error {
+	if _, ok := notification.Enquiry.SlackMessage(n.name); !ok {
+		pending, err := n.pending.HasPending(ctx, notification.Enquiry.ID, entities.OutboxKindEnquiryCreated, n.name)
+		if err != nil {
+			return fmt.Errorf("error checking for the enquiry's Slack message: %w", err)
+		}
+		if pending {
+			return ErrSlackMessagePending
+		}
+		n.logger.Debug("No Slack message to follow up",
+			zap.String("enquiry_id", notification.Enquiry.ID),
+			zap.String("notifier", n.name),
+		)
+		return nil
+	}
+
+	switch {
+	case notification.StatusChange != nil:
+		// The update is repeatable, the reply comes last so a retry does not post it twice
+		if err := n.updateEnquiry(ctx, notification.Enquiry); err != nil {
+			return err
+		}
+		return n.reply(ctx, notification.Enquiry, slackStatusChangeText(notification.StatusChange))
+	case notification.Note != nil:
+		return n.reply(ctx, notification.Enquiry, slackNoteText(notification.Note))
+	case notification.Assignment != nil:
+		if err := n.updateEnquiry(ctx, notification.Enquiry); err != nil {
+			return err
+		}
+		return n.reply(ctx, notification.Enquiry, slackAssignmentText(notification.Assignment))
+	}
+	return fmt.Errorf("%w: %s", ErrFollowUpNotSupported, notification.Kind)
+}
+
+// postEnquiry posts the enquiry message and records it for follow-ups
+func (n *SlackAPINotifier) postEnquiry(ctx context.Context, enquiry *entities.Enquiry) error {
+	if _, posted := enquiry.SlackMessage(n.name); posted {
+		// An earlier attempt got the message out, refresh it instead of posting it again
+		return n.updateEnquiry(ctx, enquiry)
+	}
+
+	payload, err := n.enquiryPayload(enquiry)
+	if err != nil {
+		return err
+	}
+	payload["channel"] = n.channel
+
+	channel, ts, err := n.client.PostMessage(ctx, payload)
+	if err != nil {
+		return err
+	}
+
+	// The message is out, failing now would post it again on retry
+	message := entities.SlackMessage{Destination: n.name, Channel: channel, TS: ts}
+	if err := n.messages.SaveSlackMessage(ctx, enquiry.ID, message); err != nil {
+		n.logger.Error("Error saving Slack message, follow-ups will not be threaded",
+			zap.String("enquiry_id", enquiry.ID),
+			zap.String("notifier", n.name),
+			zap.Error(err),
+		)
+	}
+	return nil
+}
+
+// updateEnquiry renders the enquiry again into its message, it does nothing when no message was posted
+func (n *SlackAPINotifier) updateEnquiry(ctx context.Context, enquiry *entities.Enquiry) error {
+	message, ok := enquiry.SlackMessage(n.name)
+	if !ok {
+		return nil
+	}
+
+	payload, err := n.enquiryPayload(enquiry)
+	if err != nil {
+		return err
+	}
+	payload["channel"] = message.Channel
+	payload["ts"] = message.TS
+	return n.client.UpdateMessage(ctx, payload)
+}
+
+// reply posts text in the thread of the enquiry's message, or on its own when there is no message
+func (n *SlackAPINotifier) reply(ctx context.Context, enquiry *entities.Enquiry, text string) error {
+	payload := map[string]interface{}{
+		"channel": n.channel,
+		"text":    text,
+	}
+	if message, ok := enquiry.SlackMessage(n.name); ok {
+		payload["channel"] = message.Channel
+		payload["thread_ts"] = message.TS
+	}
+	_, _, err := n.client.PostMessage(ctx, payload)
+	return err
+}
+
+// enquiryPayload renders the Slack template into a Web API payload with a notification fallback text
+func (n *SlackAPINotifier) enquiryPayload(enquiry *entities.Enquiry) (map[string]interface{}, error) {
+	rendered, err := n.templates.Render(ChannelSlack, enquiry)
+	if err != nil {
+		return nil, err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(rendered.Body), &fields); err != nil {
+		return nil, fmt.Errorf("error decoding slack message: %w", err)
+	}
+	payload := make(map[string]interface{}, len(fields)+3)
+	for key, value := range fields {
+		payload[key] = value
+	}
+	if _, ok := payload["text"]; !ok {
+		payload["text"] = fmt.Sprintf("New Customer Enquiry from %s: %s", enquiry.Source, EscapeSlack(enquiry.Subject))
+	}
+	return payload, nil
+}
+
+// slackStatusChangeText describes a status change for a thread reply
+func slackStatusChangeText(change *entities.EnquiryStatusChange) string {
+	text := fmt.Sprintf(":arrows_counterclockwise: Status changed from %s to %s", change.From, change.To)
+	if change.ChangedBy != "" {
+		text += " by " + EscapeSlack(change.ChangedBy)
+	}
+	return text + ": " + EscapeSlack(change.Reason)
+}
+
+// slackNoteText renders a note for a thread reply
+func slackNoteText(note *entities.EnquiryNote) string {
+	if note.Author != "" {
+		return fmt.Sprintf(":memo: Note from %s: %s", EscapeSlack(note.Author), EscapeSlack(note.Text))
+	}
+	return ":memo: Note: " + EscapeSlack(note.Text)
+}
+
+// slackAssignmentText describes an assignment for a thread reply, mentioning the assignee when their Slack user is known
+func slackAssignmentText(assignment *entities.EnquiryAssignment) string {
+	text := ":bust_in_silhouette: Assigned to " + slackUserText(assignment.Assignee, assignment.SlackUserID)
+	if assignment.AssignedBy != "" {
+		text += " by " + EscapeSlack(assignment.AssignedBy)
+	}
+	return text
+}
+
+// slackUserText mentions the Slack user when their ID is known and shows the escaped name otherwise
+func slackUserText(name, slackUserID string) string {
+	if slackUserID != "" {
+		return "<@" + slackUserID + ">"
+	}
+	return EscapeSlack(name)
+}
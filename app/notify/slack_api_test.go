@@ -0,0 +1,224 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/entities"
+)
+
+// slackCall is a Web API call received by fakeSlackAPI
+type slackCall struct {
+	Method  string
+	Token   string
+	Payload map[string]interface{}
+}
+
+// fakeSlackAPI answers chat.postMessage and chat.update like Slack and records the calls
+type fakeSlackAPI struct {
+	*httptest.Server
+	mu    sync.Mutex
+	calls []slackCall
+	posts int
+}
+
+func newFakeSlackAPI(t *testing.T) *fakeSlackAPI {
+	t.Helper()
+	api := &fakeSlackAPI{}
+	api.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var payload map[string]interface{}
+		if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+			t.Errorf("decoding %s payload: %v", r.URL.Path, err)
+		}
+		method := strings.TrimPrefix(r.URL.Path, "/api/")
+
+		api.mu.Lock()
+		api.calls = append(api.calls, slackCall{Method: method, Token: r.Header.Get("Authorization"), Payload: payload})
+		response := map[string]interface{}{"ok": true, "channel": "C0CHANNEL"}
+		switch method {
+		case "chat.postMessage":
+			api.posts++
+			response["ts"] = fmt.Sprintf("1700000000.%06d", api.posts)
+		case "chat.update":
+			response["ts"] = payload["ts"]
+		default:
+			response = map[string]interface{}{"ok": false, "error": "unknown_method"}
+		}
+		api.mu.Unlock()
+
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	t.Cleanup(api.Close)
+	return api
+}
+
+// Calls returns the calls received so far
+func (a *fakeSlackAPI) Calls() []slackCall {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return append([]slackCall(nil), a.calls...)
+}
+
+// slackFixture wires a notifier to the fake API and in-memory repositories holding one enquiry
+type slackFixture struct {
+	api       *fakeSlackAPI
+	notifier  *SlackAPINotifier
+	enquiries *data.MemoryEnquiryRepository
+	enquiry   *entities.Enquiry
+}
+
+func newSlackFixture(t *testing.T, outbox ...*entities.OutboxMessage) *slackFixture {
+	t.Helper()
+	api := newFakeSlackAPI(t)
+	outboxRepository := data.NewMemoryOutboxRepository()
+	enquiries := data.NewMemoryEnquiryRepository(outboxRepository)
+
+	enquiry := &entities.Enquiry{
+		ID:              "enquiry-1",
+		ReferenceNumber: "SCTGULF-261018-ABC123",
+		Source:          "SCTGULF",
+		Name:            "Jane Doe",
+		Email:           "jane@example.com",
+		Subject:         "RFQ pumps",
+		Message:         "Please quote",
+		Status:          entities.EnquiryStatusNew,
+		CreatedAt:       time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+	}
+	if err := enquiries.Create(context.Background(), enquiry, outbox...); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	client := NewSlackClient(api.URL+"/api/", "xoxb-test", api.Client())
+	notifier := NewSlackAPINotifier("gulf-sales", "C0CHANNEL", client, newTestTemplates(t), enquiries, outboxRepository, zap.NewNop())
+	return &slackFixture{api: api, notifier: notifier, enquiries: enquiries, enquiry: enquiry}
+}
+
+// reload reads the enquiry back like the outbox worker does before every delivery
+func (f *slackFixture) reload(t *testing.T) *entities.Enquiry {
+	t.Helper()
+	enquiry, err := f.enquiries.GetByID(context.Background(), f.enquiry.ID)
+	if err != nil {
+		t.Fatalf("GetByID: %v", err)
+	}
+	return enquiry
+}
+
+func TestSlackAPINotifierThreadsFollowUps(t *testing.T) {
+	f := newSlackFixture(t)
+	ctx := context.Background()
+
+	if err := f.notifier.Notify(ctx, Notification{Enquiry: f.reload(t)}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	calls := f.api.Calls()
+	if len(calls) != 1 || calls[0].Method != "chat.postMessage" {
+		t.Fatalf("calls = %+v, want one chat.postMessage", calls)
+	}
+	if calls[0].Token != "Bearer xoxb-test" {
+		t.Errorf("Authorization = %q, want the bot token", calls[0].Token)
+	}
+	if calls[0].Payload["channel"] != "C0CHANNEL" || calls[0].Payload["blocks"] == nil {
+		t.Errorf("postMessage payload = %v, want the channel and the rendered blocks", calls[0].Payload)
+	}
+
+	enquiry := f.reload(t)
+	message, ok := enquiry.SlackMessage("gulf-sales")
+	if !ok {
+		t.Fatal("the posted message was not saved on the enquiry")
+	}
+	if message.Channel != "C0CHANNEL" || message.TS != "1700000000.000001" {
+		t.Errorf("saved message = %+v, want channel C0CHANNEL and ts 1700000000.000001", message)
+	}
+
+	enquiry.Status = entities.EnquiryStatusContacted
+	change := &entities.EnquiryStatusChange{
+		From:      entities.EnquiryStatusNew,
+		To:        entities.EnquiryStatusContacted,
+		Reason:    "Called back",
+		ChangedBy: "sam",
+	}
+	if err := f.notifier.FollowUp(ctx, Notification{Kind: entities.OutboxKindEnquiryStatusChanged, Enquiry: enquiry, StatusChange: change}); err != nil {
+		t.Fatalf("FollowUp: %v", err)
+	}
+
+	calls = f.api.Calls()[1:]
+	if len(calls) != 2 {
+		t.Fatalf("follow-up made %d calls, want chat.update and a reply: %+v", len(calls), calls)
+	}
+	update, reply := calls[0], calls[1]
+	if update.Method != "chat.update" || update.Payload["channel"] != "C0CHANNEL" || update.Payload["ts"] != message.TS {
+		t.Errorf("update = %+v, want chat.update of the saved message", update)
+	}
+	if reply.Method != "chat.postMessage" || reply.Payload["thread_ts"] != message.TS {
+		t.Errorf("reply = %+v, want chat.postMessage in the thread of the saved message", reply)
+	}
+	if text, _ := reply.Payload["text"].(string); !strings.Contains(text, "from NEW to CONTACTED by sam: Called back") {
+		t.Errorf("reply text = %q, want the status change", text)
+	}
+
+	note := &entities.EnquiryNote{Text: "Sent the <datasheet>", Author: "sam"}
+	if err := f.notifier.FollowUp(ctx, Notification{Kind: entities.OutboxKindEnquiryNoteAdded, Enquiry: enquiry, Note: note}); err != nil {
+		t.Fatalf("FollowUp note: %v", err)
+	}
+	noteReply := f.api.Calls()[3]
+	if noteReply.Method != "chat.postMessage" || noteReply.Payload["thread_ts"] != message.TS {
+		t.Errorf("note reply = %+v, want a threaded chat.postMessage", noteReply)
+	}
+	if text, _ := noteReply.Payload["text"].(string); text != ":memo: Note from sam: Sent the &lt;datasheet&gt;" {
+		t.Errorf("note text = %q", text)
+	}
+}
+
+func TestSlackAPINotifierRetriesFollowUpOfPendingMessage(t *testing.T) {
+	pending := &entities.OutboxMessage{
+		ID:            "outbox-1",
+		EnquiryID:     "enquiry-1",
+		Kind:          entities.OutboxKindEnquiryCreated,
+		Destination:   "gulf-sales",
+		Status:        entities.OutboxStatusPending,
+		NextAttemptAt: time.Now().Add(time.Minute),
+	}
+	f := newSlackFixture(t, pending)
+
+	note := &entities.EnquiryNote{Text: "Called back"}
+	err := f.notifier.FollowUp(context.Background(), Notification{Kind: entities.OutboxKindEnquiryNoteAdded, Enquiry: f.reload(t), Note: note})
+	if !errors.Is(err, ErrSlackMessagePending) {
+		t.Fatalf("FollowUp = %v, want ErrSlackMessagePending", err)
+	}
+	if calls := f.api.Calls(); len(calls) != 0 {
+		t.Errorf("calls = %+v, want none before the message is posted", calls)
+	}
+}
+
+func TestSlackAPINotifierSkipsFollowUpOfEnquiryNeverPosted(t *testing.T) {
+	// Routed to another destination: no message for this notifier, now or later
+	other := &entities.OutboxMessage{
+		ID:            "outbox-1",
+		EnquiryID:     "enquiry-1",
+		Kind:          entities.OutboxKindEnquiryCreated,
+		Destination:   "sales",
+		Status:        entities.OutboxStatusPending,
+		NextAttemptAt: time.Now(),
+	}
+	f := newSlackFixture(t, other)
+
+	note := &entities.EnquiryNote{Text: "Called back"}
+	if err := f.notifier.FollowUp(context.Background(), Notification{Kind: entities.OutboxKindEnquiryNoteAdded, Enquiry: f.reload(t), Note: note}); err != nil {
+		t.Fatalf("FollowUp = %v, want nil", err)
+	}
+	if calls := f.api.Calls(); len(calls) != 0 {
+		t.Errorf("calls = %+v, want none", calls)
+	}
+}
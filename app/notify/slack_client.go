@@ -0,0 +1,140 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// DefaultSlackAPIURL is the base URL of the Slack Web API
+const DefaultSlackAPIURL = "https://slack.com/api/"
+
+// ErrSlackAPI is returned when the Slack Web API answers a call with ok set to false
+var ErrSlackAPI = errors.New("slack API error")
+
+// SlackClient calls the Slack Web API with a bot token
+type SlackClient struct {
+	baseURL string
+	token   string
+	client  *http.Client
+}
+
+// NewSlackClient creates a Web API client. baseURL defaults to DefaultSlackAPIURL,
+// pointing it at a local fake Slack API server makes the client testable.
+func NewSlackClient(baseURL, token string, client *http.Client) *SlackClient {
+	if baseURL == "" {
+		baseURL = DefaultSlackAPIURL
+	}
+	if !strings.HasSuffix(baseURL, "/") {
+		baseURL += "/"
+	}
+	return &SlackClient{
+		baseURL: baseURL,
+		token:   token,
+		client:  client,
+	}
+}
+
+// slackResponse holds the fields of a Web API response the client uses
+type slackResponse struct {
+	OK      bool       `json:"ok"`
+	Error   string     `json:"error"`
+	Channel string     `json:"channel"`
+	TS      string     `json:"ts"`
+	User    *slackUser `json:"user"`
+}
+
+// slackUser holds the names of a users.info response
+type slackUser struct {
+	Name     string `json:"name"`
+	RealName string `json:"real_name"`
+	Profile  struct {
+		DisplayName string `json:"display_name"`
+		RealName    string `json:"real_name"`
+	} `json:"profile"`
+}
+
+// PostMessage calls chat.postMessage and returns the channel ID and timestamp identifying the message
+func (c *SlackClient) PostMessage(ctx context.Context, payload interface{}) (channel, ts string, err error) {
+	resp, err := c.call(ctx, "chat.postMessage", payload)
+	if err != nil {
+		return "", "", err
+	}
+	return resp.Channel, resp.TS, nil
+}
+
+// UpdateMessage calls chat.update, the payload names the message with channel and ts
+func (c *SlackClient) UpdateMessage(ctx context.Context, payload interface{}) error {
+	_, err := c.call(ctx, "chat.update", payload)
+	return err
+}
+
+// UserName calls users.info and returns the name the user shows in Slack: the display name, else the real name,
+// else the username. The bot needs the users:read scope.
+func (c *SlackClient) UserName(ctx context.Context, userID string) (string, error) {
+	resp, err := c.call(ctx, "users.info", url.Values{"user": {userID}})
+	if err != nil {
+		return "", err
+	}
+	if resp.User == nil {
+		return "", fmt.Errorf("%w: users.info: no user in response", ErrSlackAPI)
+	}
+	for _, name := range []string{resp.User.Profile.DisplayName, resp.User.Profile.RealName, resp.User.RealName, resp.User.Name} {
+		if name != "" {
+			return name, nil
+		}
+	}
+	return userID, nil
+}
+
+// call posts the payload to the Web API method, as a form when it is url.Values and as JSON otherwise.
+// Read methods such as users.info do not accept JSON.
+func (c *SlackClient) call(ctx context.Context, method string, payload interface{}) (*slackResponse, error) {
+	var body []byte
+	contentType := "application/json; charset=utf-8"
+	if form, ok := payload.(url.Values); ok {
+		body = []byte(form.Encode())
+		contentType = "application/x-www-form-urlencoded"
+	} else {
+		var err error
+		if body, err = json.Marshal(payload); err != nil {
+			return nil, fmt.Errorf("error marshalling JSON: %w", err)
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+method, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request: %w", err)
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling %s: %w", method, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests {
+		return nil, fmt.Errorf("%s is rate limited, retry after %s seconds", method, resp.Header.Get("Retry-After"))
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return nil, fmt.Errorf("failed to call %s, status code: %d, body: %s", method, resp.StatusCode, respBody)
+	}
+
+	var result slackResponse
+	if err := json.NewDecoder(io.LimitReader(resp.Body, 1<<20)).Decode(&result); err != nil {
+		return nil, fmt.Errorf("error decoding %s response: %w", method, err)
+	}
+	if !result.OK {
+		return nil, fmt.Errorf("%w: %s: %s", ErrSlackAPI, method, result.Error)
+	}
+	return &result, nil
+}
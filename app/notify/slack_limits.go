@@ -0,0 +1,286 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"sct-backend-service/app/utils"
+)
+
+// Block Kit limits in characters. Slack rejects the whole message with a 400 when one is exceeded.
+const (
+	slackMaxBlocks         = 50
+	slackMaxText           = 40000
+	slackMaxSectionText    = 3000
+	slackMaxSectionFields  = 10
+	slackMaxFieldText      = 2000
+	slackMaxHeaderText     = 150
+	slackMaxActionElements = 25
+	slackMaxButtonText     = 75
+)
+
+// ErrSlackLimit is returned when a Slack message breaks a Block Kit limit that truncation cannot fix
+var ErrSlackLimit = errors.New("slack message exceeds Block Kit limits")
+
+// fitSlackMessage makes a rendered Slack payload fit the Block Kit limits.
+// Section text that is too long is split over several sections. When that would take more than
+// 50 blocks the text is cut short and followed by a link to the full enquiry instead.
+// The result is validated, so a message that still breaks a limit is never sent.
+func fitSlackMessage(body []byte, enquiryURL string) ([]byte, error) {
+	var message map[string]interface{}
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	if err := decoder.Decode(&message); err != nil {
+		return nil, fmt.Errorf("error decoding slack message: %w", err)
+	}
+
+	if text, ok := message["text"].(string); ok {
+		message["text"] = truncateText(text, slackMaxText)
+	}
+	if blocks, ok := message["blocks"].([]interface{}); ok {
+		message["blocks"] = fitSlackBlocks(blocks, enquiryURL)
+	}
+
+	if err := validateSlackMessage(message); err != nil {
+		return nil, err
+	}
+	return json.Marshal(message)
+}
+
+// fitSlackBlocks splits long sections and keeps the message within the block limit
+func fitSlackBlocks(blocks []interface{}, enquiryURL string) []interface{} {
+	groups := make([][]interface{}, len(blocks))
+	total := 0
+	for i, block := range blocks {
+		groups[i] = fitSlackBlock(block)
+		total += len(groups[i])
+	}
+	if total <= slackMaxBlocks {
+		return flattenSlackBlocks(groups)
+	}
+
+	// Drop continuation sections from the end, one block is kept free for the notice
+	excess := total - slackMaxBlocks + 1
+	noticeAfter := -1
+	for i := len(groups) - 1; i >= 0 && excess > 0; i-- {
+		cut := min(excess, len(groups[i])-1)
+		if cut == 0 {
+			continue
+		}
+		groups[i] = groups[i][:len(groups[i])-cut]
+		excess -= cut
+		markSlackTruncated(groups[i][len(groups[i])-1])
+		if noticeAfter < 0 {
+			noticeAfter = i
+		}
+	}
+
+	if excess > 0 {
+		// The template itself has too many blocks, the tail is dropped
+		return append(flattenSlackBlocks(groups)[:slackMaxBlocks-1], slackTruncationNotice(enquiryURL))
+	}
+
+	fitted := make([]interface{}, 0, slackMaxBlocks)
+	for i, group := range groups {
+		fitted = append(fitted, group...)
+		if i == noticeAfter {
+			fitted = append(fitted, slackTruncationNotice(enquiryURL))
+		}
+	}
+	return fitted
+}
+
+// fitSlackBlock truncates the texts of a block, a section with too long a text becomes several sections
+func fitSlackBlock(block interface{}) []interface{} {
+	b, ok := block.(map[string]interface{})
+	if !ok {
+		return []interface{}{block}
+	}
+
+	switch b["type"] {
+	case "header":
+		truncateTextObject(b["text"], slackMaxHeaderText)
+	case "actions":
+		if elements, ok := b["elements"].([]interface{}); ok {
+			for _, element := range elements {
+				if e, ok := element.(map[string]interface{}); ok && e["type"] == "button" {
+					truncateTextObject(e["text"], slackMaxButtonText)
+				}
+			}
+		}
+	case "section":
+		if fields, ok := b["fields"].([]interface{}); ok {
+			for _, field := range fields {
+				truncateTextObject(field, slackMaxFieldText)
+			}
+		}
+		text, ok := b["text"].(map[string]interface{})
+		if !ok {
+			break
+		}
+		s, ok := text["text"].(string)
+		if !ok || utf8.RuneCountInString(s) <= slackMaxSectionText {
+			break
+		}
+
+		// One character is kept free for the ellipsis added when the message is cut short
+		chunks := splitSlackText(s, slackMaxSectionText-1)
+		text["text"] = chunks[0]
+		sections := []interface{}{b}
+		for _, chunk := range chunks[1:] {
+			sections = append(sections, map[string]interface{}{
+				"type": "section",
+				"text": map[string]interface{}{
+					"type": text["type"],
+					"text": chunk,
+				},
+			})
+		}
+		return sections
+	}
+	return []interface{}{b}
+}
+
+// splitSlackText splits s into chunks of at most limit characters
+func splitSlackText(s string, limit int) []string {
+	var chunks []string
+	for utf8.RuneCountInString(s) > limit {
+		head := utils.TruncateString(s, limit)
+		cut := slackSplitPoint(head)
+		chunks = append(chunks, head[:cut])
+		s = s[cut:]
+	}
+	return append(chunks, s)
+}
+
+// slackSplitPoint returns the byte offset to end a chunk at: after the last line break, else after
+// the last space, as long as that keeps at least half of the chunk. It never cuts through a
+// <link|text> or an escaped &amp; character.
+func slackSplitPoint(head string) int {
+	cut := len(head)
+	if i := strings.LastIndexByte(head, '\n'); i >= len(head)/2 {
+		cut = i + 1
+	} else if i := strings.LastIndexByte(head, ' '); i >= len(head)/2 {
+		cut = i + 1
+	}
+
+	if open := strings.LastIndexByte(head[:cut], '<'); open > 0 && open > strings.LastIndexByte(head[:cut], '>') {
+		cut = open
+	}
+	if amp := strings.LastIndexByte(head[:cut], '&'); amp > 0 && amp > strings.LastIndexByte(head[:cut], ';') {
+		cut = amp
+	}
+	return cut
+}
+
+// markSlackTruncated ends the text of a section that was cut short with an ellipsis
+func markSlackTruncated(block interface{}) {
+	if b, ok := block.(map[string]interface{}); ok {
+		if text, ok := b["text"].(map[string]interface{}); ok {
+			if s, ok := text["text"].(string); ok {
+				text["text"] = s + "…"
+			}
+		}
+	}
+}
+
+// slackTruncationNotice tells the reader the message was cut short and links to the full enquiry when possible
+func slackTruncationNotice(enquiryURL string) map[string]interface{} {
+	text := "_This enquiry is too long for Slack and was shortened._"
+	if enquiryURL != "" {
+		text += fmt.Sprintf(" <%s|View full enquiry>", enquiryURL)
+	}
+	return map[string]interface{}{
+		"type": "section",
+		"text": map[string]interface{}{
+			"type": "mrkdwn",
+			"text": text,
+		},
+	}
+}
+
+// flattenSlackBlocks joins the blocks of all groups
+func flattenSlackBlocks(groups [][]interface{}) []interface{} {
+	var blocks []interface{}
+	for _, group := range groups {
+		blocks = append(blocks, group...)
+	}
+	return blocks
+}
+
+// truncateText cuts s to limit characters, ending it with an ellipsis when it was cut
+func truncateText(s string, limit int) string {
+	if utf8.RuneCountInString(s) <= limit {
+		return s
+	}
+	return utils.TruncateString(s, limit-1) + "…"
+}
+
+// truncateTextObject truncates the text of a Block Kit text object
+func truncateTextObject(object interface{}, limit int) {
+	if o, ok := object.(map[string]interface{}); ok {
+		if s, ok := o["text"].(string); ok {
+			o["text"] = truncateText(s, limit)
+		}
+	}
+}
+
+// validateSlackMessage checks the limits Slack enforces on a message
+func validateSlackMessage(message map[string]interface{}) error {
+	if text, ok := message["text"].(string); ok && utf8.RuneCountInString(text) > slackMaxText {
+		return fmt.Errorf("%w: text is longer than %d characters", ErrSlackLimit, slackMaxText)
+	}
+
+	blocks, _ := message["blocks"].([]interface{})
+	if len(blocks) > slackMaxBlocks {
+		return fmt.Errorf("%w: %d blocks, at most %d are allowed", ErrSlackLimit, len(blocks), slackMaxBlocks)
+	}
+	for i, block := range blocks {
+		b, ok := block.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%w: block %d is not an object", ErrSlackLimit, i)
+		}
+
+		switch b["type"] {
+		case "header":
+			if textLength(b["text"]) > slackMaxHeaderText {
+				return fmt.Errorf("%w: header block %d is longer than %d characters", ErrSlackLimit, i, slackMaxHeaderText)
+			}
+		case "section":
+			if textLength(b["text"]) > slackMaxSectionText {
+				return fmt.Errorf("%w: section block %d is longer than %d characters", ErrSlackLimit, i, slackMaxSectionText)
+			}
+			fields, _ := b["fields"].([]interface{})
+			if len(fields) > slackMaxSectionFields {
+				return fmt.Errorf("%w: section block %d has %d fields, at most %d are allowed", ErrSlackLimit, i, len(fields), slackMaxSectionFields)
+			}
+			for _, field := range fields {
+				if textLength(field) > slackMaxFieldText {
+					return fmt.Errorf("%w: a field of section block %d is longer than %d characters", ErrSlackLimit, i, slackMaxFieldText)
+				}
+			}
+		case "actions":
+			elements, _ := b["elements"].([]interface{})
+			if len(elements) > slackMaxActionElements {
+				return fmt.Errorf("%w: actions block %d has %d elements, at most %d are allowed", ErrSlackLimit, i, len(elements), slackMaxActionElements)
+			}
+			for _, element := range elements {
+				if e, ok := element.(map[string]interface{}); ok && e["type"] == "button" && textLength(e["text"]) > slackMaxButtonText {
+					return fmt.Errorf("%w: a button of actions block %d is longer than %d characters", ErrSlackLimit, i, slackMaxButtonText)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// textLength returns the length in characters of a Block Kit text object
+func textLength(object interface{}) int {
+	o, _ := object.(map[string]interface{})
+	s, _ := o["text"].(string)
+	return utf8.RuneCountInString(s)
+}
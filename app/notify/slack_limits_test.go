@@ -0,0 +1,210 @@
+package notify
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// renderSlack renders the enquiry for Slack and decodes the payload
+func renderSlack(t *testing.T, message string) map[string]interface{} {
+	t.Helper()
+	enquiry := hostileEnquiry()
+	enquiry.Message = message
+	rendered, err := newTestTemplates(t).Render(ChannelSlack, enquiry)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(rendered.Body), &payload); err != nil {
+		t.Fatalf("rendered body is not JSON: %v", err)
+	}
+	return payload
+}
+
+// checkSlackPayload checks the limits Slack enforces and that no text ends in half a character
+func checkSlackPayload(t *testing.T, payload map[string]interface{}) {
+	t.Helper()
+	if err := validateSlackMessage(payload); err != nil {
+		t.Fatalf("validateSlackMessage: %v", err)
+	}
+	for _, text := range strings.Split(jsonText(t, mustMarshal(t, payload)), "\n") {
+		if !utf8.ValidString(text) || strings.ContainsRune(text, utf8.RuneError) {
+			t.Fatalf("text contains a split character: %q", text)
+		}
+	}
+}
+
+// sectionTexts returns the text of every section block
+func sectionTexts(payload map[string]interface{}) []string {
+	var texts []string
+	blocks, _ := payload["blocks"].([]interface{})
+	for _, block := range blocks {
+		b, _ := block.(map[string]interface{})
+		if b["type"] != "section" {
+			continue
+		}
+		if text, ok := b["text"].(map[string]interface{}); ok {
+			s, _ := text["text"].(string)
+			texts = append(texts, s)
+		}
+	}
+	return texts
+}
+
+func mustMarshal(t *testing.T, v interface{}) string {
+	t.Helper()
+	encoded, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	return string(encoded)
+}
+
+func TestFitSlackMessageSplitsLongArabicMessage(t *testing.T) {
+	tests := []struct {
+		name string
+		word string
+	}{
+		// Split at the spaces between words
+		{"words", "مرحبا بكم "},
+		// No space or line break to split at, the text is cut between characters
+		{"single word", "مرحبابكم"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message := []rune(strings.Repeat(tt.word, 10000))[:10000]
+			payload := renderSlack(t, string(message))
+			checkSlackPayload(t, payload)
+
+			// The message sections are the last ones, the buttons follow in actions blocks
+			texts := sectionTexts(payload)
+			first := len(texts)
+			for i, text := range texts {
+				if strings.HasPrefix(text, "Message: ") {
+					first = i
+				}
+			}
+			chunks := len(texts) - first
+			joined := strings.Join(texts[first:], "")
+			if chunks != 4 {
+				t.Errorf("message was split over %d sections, want 4", chunks)
+			}
+			if got := strings.TrimPrefix(joined, "Message: "); got != string(message) {
+				t.Errorf("joined sections differ from the message (%d of %d characters)", utf8.RuneCountInString(got), len(message))
+			}
+		})
+	}
+}
+
+func TestFitSlackMessageKeepsBlockLimit(t *testing.T) {
+	// 187,500 characters take 63 sections of at most 2,999
+	payload := renderSlack(t, strings.Repeat("سطر طويل جدا 🙂 ", 12500))
+	checkSlackPayload(t, payload)
+
+	blocks := payload["blocks"].([]interface{})
+	if len(blocks) != slackMaxBlocks {
+		t.Errorf("%d blocks, want the message cut to exactly %d", len(blocks), slackMaxBlocks)
+	}
+	texts := sectionTexts(payload)
+	var notice int
+	for i, text := range texts {
+		if strings.HasPrefix(text, "_This enquiry is too long for Slack") {
+			notice = i
+		}
+	}
+	if notice == 0 {
+		t.Fatalf("no truncation notice in %d sections", len(texts))
+	}
+	if !strings.HasSuffix(texts[notice-1], "…") {
+		t.Errorf("last message section %q… does not end with an ellipsis", string([]rune(texts[notice-1])[:20]))
+	}
+	if !strings.Contains(texts[notice], "<https://admin.example.com/enquiries/enquiry-1|View full enquiry>") {
+		t.Errorf("notice %q does not link to the enquiry", texts[notice])
+	}
+	// Blocks after the message, such as the buttons, are kept
+	if last := blocks[len(blocks)-1].(map[string]interface{}); last["type"] != "actions" {
+		t.Errorf("last block is %v, want the actions", last["type"])
+	}
+}
+
+func TestFitSlackMessageTruncatesHeader(t *testing.T) {
+	header := strings.Repeat("عرض سعر 🙂 ", 30)
+	body := mustMarshal(t, map[string]interface{}{
+		"text": strings.Repeat("ن", slackMaxText+10),
+		"blocks": []interface{}{
+			map[string]interface{}{"type": "header", "text": map[string]interface{}{"type": "plain_text", "text": header}},
+			map[string]interface{}{"type": "actions", "elements": []interface{}{
+				map[string]interface{}{"type": "button", "text": map[string]interface{}{"type": "plain_text", "text": strings.Repeat("رد ", 40)}},
+			}},
+		},
+	})
+	fitted, err := fitSlackMessage([]byte(body), "")
+	if err != nil {
+		t.Fatalf("fitSlackMessage: %v", err)
+	}
+	var payload map[string]interface{}
+	if err := json.Unmarshal(fitted, &payload); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	checkSlackPayload(t, payload)
+
+	got := payload["blocks"].([]interface{})[0].(map[string]interface{})["text"].(map[string]interface{})["text"].(string)
+	if utf8.RuneCountInString(got) != slackMaxHeaderText {
+		t.Errorf("header has %d characters, want %d", utf8.RuneCountInString(got), slackMaxHeaderText)
+	}
+	if want := string([]rune(header)[:slackMaxHeaderText-1]) + "…"; got != want {
+		t.Errorf("header = %q, want %q", got, want)
+	}
+	if n := utf8.RuneCountInString(payload["text"].(string)); n != slackMaxText {
+		t.Errorf("fallback text has %d characters, want %d", n, slackMaxText)
+	}
+}
+
+func TestValidateSlackMessageRejectsOversizedHeader(t *testing.T) {
+	message := map[string]interface{}{
+		"blocks": []interface{}{
+			map[string]interface{}{"type": "header", "text": map[string]interface{}{"type": "plain_text", "text": strings.Repeat("ع", slackMaxHeaderText+1)}},
+		},
+	}
+	if err := validateSlackMessage(message); err == nil {
+		t.Error("validateSlackMessage accepted a header over 150 characters")
+	}
+}
+
+func TestSplitSlackText(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		limit int
+		want  []string
+	}{
+		{"short", "hello", 10, []string{"hello"}},
+		{"at a line break", "first line\nsecond", 14, []string{"first line\n", "second"}},
+		{"at a space", "one two three", 10, []string{"one two ", "three"}},
+		{"between Arabic characters", "مرحبامرحبا", 4, []string{"مرحب", "امرح", "با"}},
+		{"between emoji", "🙂🙂🙂", 2, []string{"🙂🙂", "🙂"}},
+		{"before a link", "see you <https://e.com|x>", 20, []string{"see you ", "<https://e.com|x>"}},
+		{"before an entity", "Smith &amp; Sons", 9, []string{"Smith ", "&amp; ", "Sons"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitSlackText(tt.input, tt.limit)
+			if strings.Join(got, "") != tt.input {
+				t.Fatalf("chunks %q do not join back to %q", got, tt.input)
+			}
+			if len(got) != len(tt.want) {
+				t.Fatalf("splitSlackText = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("splitSlackText = %q, want %q", got, tt.want)
+				}
+				if !utf8.ValidString(got[i]) || utf8.RuneCountInString(got[i]) > tt.limit {
+					t.Errorf("chunk %q splits a character or is longer than %d", got[i], tt.limit)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,73 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/entities"
+)
+
+// TeamsNotifier posts enquiries to a Microsoft Teams incoming webhook as an Adaptive Card
+type TeamsNotifier struct {
+	name       string
+	webhookURL string
+	client     *http.Client
+	templates  *Templates
+}
+
+// NewTeamsNotifier creates a notifier for a single Teams incoming webhook
+func NewTeamsNotifier(name, webhookURL string, client *http.Client, templates *Templates) *TeamsNotifier {
+	return &TeamsNotifier{
+		name:       name,
+		webhookURL: webhookURL,
+		client:     client,
+		templates:  templates,
+	}
+}
+
+// Name identifies the notifier
+func (n *TeamsNotifier) Name() string {
+	return n.name
+}
+
+// Notify posts the enquiry as an Adaptive Card message, or a short note for a resubmission
+func (n *TeamsNotifier) Notify(ctx context.Context, notification Notification) error {
+	if notification.IsResubmission() {
+		return postJSON(ctx, n.client, n.webhookURL, renderTeamsResubmission(notification.Enquiry, notification.Resubmission, n.templates.links), nil)
+	}
+	rendered, err := n.templates.Render(ChannelTeams, notification.Enquiry)
+	if err != nil {
+		return err
+	}
+	return postJSON(ctx, n.client, n.webhookURL, json.RawMessage(rendered.Body), nil)
+}
+
+func renderTeamsResubmission(enquiry *entities.Enquiry, resubmission *entities.EnquiryResubmission, links *attachment.Links) map[string]interface{} {
+	return teamsCardMessage(map[string]interface{}{
+		"type":    "AdaptiveCard",
+		"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+		"version": "1.4",
+		"body": []map[string]interface{}{
+			{
+				"type": "TextBlock",
+				"wrap": true,
+				"text": resubmissionText(enquiry, resubmission, links, escapeTeams, markdownLink),
+			},
+		},
+	})
+}
+
+// teamsCardMessage wraps an Adaptive Card in the message envelope expected by incoming webhooks
+func teamsCardMessage(card map[string]interface{}) map[string]interface{} {
+	return map[string]interface{}{
+		"type": "message",
+		"attachments": []map[string]interface{}{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content":     card,
+			},
+		},
+	}
+}
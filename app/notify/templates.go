@@ -0,0 +1,472 @@
+package notify
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+	"time"
+
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/i18n"
+)
+
+// ErrUnknownChannel is returned when a channel has no message templates
+var ErrUnknownChannel = errors.New("unknown notification channel")
+
+// Channel names the message format of a notifier
+type Channel string
+
+const (
+	ChannelSlack   Channel = "slack"
+	ChannelEmail   Channel = "email"
+	ChannelTeams   Channel = "teams"
+	ChannelDiscord Channel = "discord"
+)
+
+// Template file names. JSON templates produce the webhook payload, the email is made of three files.
+const (
+	emailSubjectTemplate = "email.subject.tmpl"
+	emailHTMLTemplate    = "email.html.tmpl"
+	emailTextTemplate    = "email.txt.tmpl"
+)
+
+// Template file names of the acknowledgement email sent to the customer
+const (
+	acknowledgementSubjectTemplate = "acknowledgement.subject.tmpl"
+	acknowledgementHTMLTemplate    = "acknowledgement.html.tmpl"
+	acknowledgementTextTemplate    = "acknowledgement.txt.tmpl"
+)
+
+// jsonTemplate returns the file name of a webhook channel's payload template
+func jsonTemplate(channel Channel) string {
+	return string(channel) + ".json.tmpl"
+}
+
+// defaultTemplateFiles are the built-in templates used when the templates directory has no override
+//
+//go:embed templates/*.tmpl
+var defaultTemplateFiles embed.FS
+
+// defaultTemplates are the parsed built-in templates by file name
+var defaultTemplates = mustParseDefaultTemplates()
+
+// htmlTemplateFuncs are available to HTML email templates
+var htmlTemplateFuncs = htmltemplate.FuncMap{
+	// trustedURL marks a link the service built itself, html/template would otherwise reject tel: links
+	"trustedURL": func(url string) htmltemplate.URL {
+		return htmltemplate.URL(url)
+	},
+}
+
+// templateExecutor is implemented by both text and HTML templates
+type templateExecutor interface {
+	Execute(w io.Writer, data interface{}) error
+}
+
+// loadedTemplate is a template file parsed from the templates directory
+type loadedTemplate struct {
+	modTime  time.Time
+	size     int64
+	template templateExecutor
+}
+
+// Rendered is a new enquiry notification rendered for one channel
+type Rendered struct {
+	// Subject is only set for email
+	Subject string
+	// Body is the JSON payload of a webhook channel or the HTML body of an email
+	Body string
+	// Text is the plain text body of an email
+	Text string
+	// Language is the locale of a customer acknowledgement, empty for notifications
+	Language string
+}
+
+// TemplateData is what the templates of a new enquiry see.
+// Visitor input is already encoded for the channel: mrkdwn or Markdown escaped and then JSON string
+// escaped for webhook channels, so JSON templates place the fields between quotes as they are.
+// Email templates get plain text, html/template escapes the HTML body itself.
+type TemplateData struct {
+	// ID identifies the enquiry in interactive Slack buttons
+	ID              string
+	Source          string
+	ReferenceNumber string
+	Name            string
+	Email           string
+	// Phone is the number as the channel shows it, linked for calling and WhatsApp in Slack
+	Phone       string
+	CompanyName string
+	Subject     string
+	Message     string
+	Country     string
+	// ReplyURL is a mailto: link to the customer
+	ReplyURL string
+	// EnquiryURL links to the enquiry in the admin UI, empty when no admin URL is configured
+	EnquiryURL string
+	Status     string
+	// StatusSummary describes the status and who set it, e.g. "✅ Contacted by Priya", empty for NEW enquiries.
+	// Slack Web API messages are updated when the status changes.
+	StatusSummary string
+	// Assignee is the salesperson handling the enquiry, empty while unassigned. Slack mentions them when their user is known.
+	Assignee string
+	// TelURL and WhatsAppURL are empty when the number could not be normalized or cannot use WhatsApp
+	TelURL      string
+	WhatsAppURL string
+	// Attachments link the files sent with the enquiry, the links are signed when the message is rendered
+	Attachments []AttachmentData
+	CreatedAt   time.Time
+}
+
+// AttachmentData is a file sent with the enquiry as templates see it, encoded like the other fields
+type AttachmentData struct {
+	Name string
+	// Size is written for people, e.g. 1.2 MB
+	Size string
+	// URL downloads the file until the link expires, empty when downloads are not configured
+	URL string
+}
+
+// AcknowledgementData is what the acknowledgement templates see: the enquiry as email templates see it
+// and the branding of the site it was sent from
+type AcknowledgementData struct {
+	TemplateData
+	SiteName string
+	// LogoURL is the absolute URL of the site's logo, empty when the site has none
+	LogoURL string
+	// Signature closes the email, it may span several lines
+	Signature string
+	// Locale is the language of the enquiry, Direction is rtl for languages written right to left
+	Locale    string
+	Direction string
+}
+
+// T translates the format into the enquiry's language and formats it like fmt.Sprintf,
+// e.g. {{.T "Dear %s," .Name}}. Text without a translation stays in English.
+func (d AcknowledgementData) T(format string, args ...interface{}) string {
+	return i18n.Sprintf(d.Locale, format, args...)
+}
+
+// Templates renders new enquiry notifications from template files.
+// For an enquiry from source SCTGULF a file in <dir>/SCTGULF/ is used first, then one in <dir>/,
+// then the built-in default. Files are parsed again when they change, so the wording and layout
+// can be edited without a restart.
+type Templates struct {
+	dir        string
+	enquiryURL string
+	links      *attachment.Links
+	mu         sync.Mutex
+	cache      map[string]*loadedTemplate
+}
+
+// NewTemplates creates the renderer for the given templates directory, empty means the built-in templates only.
+// enquiryURL is the admin UI link of an enquiry in which {id} is replaced by the enquiry ID, it may be empty.
+// links signs the download links of attachments, it is nil when attachments are disabled.
+// Every template in the directory is parsed once so mistakes show up at startup.
+func NewTemplates(dir, enquiryURL string, links *attachment.Links) (*Templates, error) {
+	t := &Templates{
+		dir:        dir,
+		enquiryURL: enquiryURL,
+		links:      links,
+		cache:      make(map[string]*loadedTemplate),
+	}
+	if dir == "" {
+		return t, nil
+	}
+
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(path, ".tmpl") {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return err
+		}
+		_, err = t.load(path, info)
+		return err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error loading notification templates: %w", err)
+	}
+	return t, nil
+}
+
+// Render renders a new enquiry for the channel
+func (t *Templates) Render(channel Channel, enquiry *entities.Enquiry) (*Rendered, error) {
+	switch channel {
+	case ChannelSlack, ChannelTeams, ChannelDiscord:
+		body, err := t.execute(enquiry.Source, jsonTemplate(channel), t.newTemplateData(channel, enquiry))
+		if err != nil {
+			return nil, err
+		}
+		if !json.Valid(body) {
+			return nil, fmt.Errorf("%s template for %s did not produce valid JSON", channel, enquiry.Source)
+		}
+		if channel == ChannelSlack {
+			if body, err = fitSlackMessage(body, t.EnquiryURL(enquiry.ID)); err != nil {
+				return nil, err
+			}
+		}
+		return &Rendered{Body: string(body)}, nil
+	case ChannelEmail:
+		data := t.newTemplateData(channel, enquiry)
+		subject, err := t.execute(enquiry.Source, emailSubjectTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+		html, err := t.execute(enquiry.Source, emailHTMLTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+		text, err := t.execute(enquiry.Source, emailTextTemplate, data)
+		if err != nil {
+			return nil, err
+		}
+		return &Rendered{
+			Subject: strings.TrimSpace(string(subject)),
+			Body:    string(html),
+			Text:    string(text),
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownChannel, channel)
+}
+
+// RenderAcknowledgement renders the email telling the customer the enquiry arrived, in the branding
+// of its site and the language the enquiry was sent in
+func (t *Templates) RenderAcknowledgement(enquiry *entities.Enquiry, site AcknowledgementSite) (*Rendered, error) {
+	locale := enquiry.Locale
+	if !i18n.IsSupported(locale) {
+		locale = i18n.DefaultLocale
+	}
+	data := AcknowledgementData{
+		TemplateData: t.newTemplateData(ChannelEmail, enquiry),
+		SiteName:     site.SiteName,
+		LogoURL:      site.LogoURL,
+		Signature:    site.Signature,
+		Locale:       locale,
+		Direction:    i18n.Direction(locale),
+	}
+	if localized, ok := site.Localized[locale]; ok {
+		if localized.SiteName != "" {
+			data.SiteName = localized.SiteName
+		}
+		if localized.Signature != "" {
+			data.Signature = localized.Signature
+		}
+	}
+	subject, err := t.execute(enquiry.Source, acknowledgementSubjectTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	html, err := t.execute(enquiry.Source, acknowledgementHTMLTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	text, err := t.execute(enquiry.Source, acknowledgementTextTemplate, data)
+	if err != nil {
+		return nil, err
+	}
+	return &Rendered{
+		Subject:  strings.TrimSpace(string(subject)),
+		Body:     string(html),
+		Text:     string(text),
+		Language: locale,
+	}, nil
+}
+
+// EnquiryURL returns the admin UI link of the enquiry with the ID, empty when no admin URL is configured
+func (t *Templates) EnquiryURL(id string) string {
+	if t.enquiryURL == "" {
+		return ""
+	}
+	return strings.ReplaceAll(t.enquiryURL, "{id}", url.PathEscape(id))
+}
+
+// execute renders the named template of the source
+func (t *Templates) execute(source, name string, data interface{}) ([]byte, error) {
+	tmpl, err := t.lookup(source, name)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		return nil, fmt.Errorf("error rendering template %s: %w", name, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// lookup finds the most specific template file for the source, the file is stat'ed on every
+// call and parsed again when its modification time or size changed
+func (t *Templates) lookup(source, name string) (templateExecutor, error) {
+	if t.dir != "" {
+		for _, path := range []string{filepath.Join(t.dir, source, name), filepath.Join(t.dir, name)} {
+			info, err := os.Stat(path)
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			if err != nil {
+				return nil, fmt.Errorf("error reading template: %w", err)
+			}
+			return t.load(path, info)
+		}
+	}
+
+	tmpl, ok := defaultTemplates[name]
+	if !ok {
+		return nil, fmt.Errorf("no template named %s", name)
+	}
+	return tmpl, nil
+}
+
+// load returns the cached template of the file, parsing it when it is new or changed
+func (t *Templates) load(path string, info fs.FileInfo) (templateExecutor, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if cached, ok := t.cache[path]; ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.template, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading template: %w", err)
+	}
+	tmpl, err := parseTemplate(filepath.Base(path), string(content))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing template %s: %w", path, err)
+	}
+
+	t.cache[path] = &loadedTemplate{
+		modTime:  info.ModTime(),
+		size:     info.Size(),
+		template: tmpl,
+	}
+	return tmpl, nil
+}
+
+// parseTemplate parses HTML templates with html/template and everything else with text/template
+func parseTemplate(name, content string) (templateExecutor, error) {
+	if strings.HasSuffix(name, ".html.tmpl") {
+		return htmltemplate.New(name).Funcs(htmlTemplateFuncs).Parse(content)
+	}
+	return texttemplate.New(name).Parse(content)
+}
+
+func mustParseDefaultTemplates() map[string]templateExecutor {
+	entries, err := defaultTemplateFiles.ReadDir("templates")
+	if err != nil {
+		panic(err)
+	}
+
+	templates := make(map[string]templateExecutor, len(entries))
+	for _, entry := range entries {
+		content, err := defaultTemplateFiles.ReadFile("templates/" + entry.Name())
+		if err != nil {
+			panic(err)
+		}
+		tmpl, err := parseTemplate(entry.Name(), string(content))
+		if err != nil {
+			panic(err)
+		}
+		templates[entry.Name()] = tmpl
+	}
+	return templates
+}
+
+// newTemplateData encodes the enquiry for the channel's templates
+func (t *Templates) newTemplateData(channel Channel, enquiry *entities.Enquiry) TemplateData {
+	escape, encode := plainText, plainText
+	phone := enquiry.DisplayPhoneNumber()
+	switch channel {
+	case ChannelSlack:
+		escape, encode = EscapeSlack, jsonString
+		phone = slackPhoneText(enquiry)
+	case ChannelTeams:
+		escape, encode = escapeTeams, jsonString
+		phone = escapeTeams(phone)
+	case ChannelDiscord:
+		escape, encode = escapeDiscord, jsonString
+		phone = escapeDiscord(phone)
+	}
+	text := func(s string) string {
+		return encode(escape(s))
+	}
+
+	assignee := text(enquiry.Assignee)
+	if channel == ChannelSlack && enquiry.Assignee != "" {
+		assignee = encode(slackUserText(enquiry.Assignee, enquiry.AssigneeSlackID))
+	}
+
+	attachments := make([]AttachmentData, 0, len(enquiry.Attachments))
+	for _, file := range enquiry.Attachments {
+		attachments = append(attachments, AttachmentData{
+			Name: text(file.FileName),
+			Size: encode(attachment.FormatSize(file.Size)),
+			URL:  encode(t.links.URL(enquiry.ID, file)),
+		})
+	}
+
+	return TemplateData{
+		ID:              encode(enquiry.ID),
+		Source:          encode(enquiry.Source),
+		ReferenceNumber: encode(enquiry.ReferenceNumber),
+		Name:            text(enquiry.Name),
+		Email:           text(enquiry.Email),
+		Phone:           encode(phone),
+		CompanyName:     text(enquiry.CompanyName),
+		Subject:         text(enquiry.Subject),
+		Message:         text(enquiry.Message),
+		Country:         text(enquiry.Country),
+		ReplyURL:        encode(mailtoURL(enquiry.Email)),
+		EnquiryURL:      encode(t.EnquiryURL(enquiry.ID)),
+		Status:          encode(enquiry.Status),
+		StatusSummary:   text(statusSummary(enquiry)),
+		Assignee:        assignee,
+		TelURL:          encode(enquiry.TelURI()),
+		WhatsAppURL:     encode(enquiry.WhatsAppURL()),
+		Attachments:     attachments,
+		CreatedAt:       enquiry.CreatedAt,
+	}
+}
+
+// statusSummaries describe the statuses an enquiry moves on to after NEW
+var statusSummaries = map[string]string{
+	entities.EnquiryStatusContacted: "✅ Contacted",
+	entities.EnquiryStatusQualified: "⭐ Qualified",
+	entities.EnquiryStatusWon:       "🏆 Won",
+	entities.EnquiryStatusLost:      "❌ Lost",
+	entities.EnquiryStatusSpam:      "🚫 Marked as spam",
+}
+
+// statusSummary describes the current status and who set it, it is empty for NEW enquiries
+func statusSummary(enquiry *entities.Enquiry) string {
+	summary, ok := statusSummaries[enquiry.Status]
+	if !ok {
+		return ""
+	}
+	if n := len(enquiry.StatusHistory); n > 0 && enquiry.StatusHistory[n-1].ChangedBy != "" {
+		summary += " by " + enquiry.StatusHistory[n-1].ChangedBy
+	}
+	return summary
+}
+
+// jsonString encodes s for use between the quotes of a JSON string
+func jsonString(s string) string {
+	encoded, _ := json.Marshal(s)
+	return string(encoded[1 : len(encoded)-1])
+}
@@ -0,0 +1,102 @@
+package notify
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// writeTemplate writes a template file below dir, creating the source directory
+func writeTemplate(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("creating template directory: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("writing template: %v", err)
+	}
+	return path
+}
+
+func TestTemplatesPreferSourceOverrides(t *testing.T) {
+	dir := t.TempDir()
+	writeTemplate(t, dir, emailSubjectTemplate, "Enquiry {{.ReferenceNumber}}")
+	writeTemplate(t, dir, filepath.Join("SCTGULF", emailSubjectTemplate), "Gulf enquiry {{.ReferenceNumber}}")
+	templates, err := NewTemplates(dir, "", nil)
+	if err != nil {
+		t.Fatalf("NewTemplates: %v", err)
+	}
+
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{"SCTGULF", "Gulf enquiry SCTGULF-261018-ABC123"},
+		{"AGEM", "Enquiry SCTGULF-261018-ABC123"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			enquiry := hostileEnquiry()
+			enquiry.Source = tt.source
+			rendered, err := templates.Render(ChannelEmail, enquiry)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if rendered.Subject != tt.want {
+				t.Errorf("subject = %q, want %q", rendered.Subject, tt.want)
+			}
+			// Files without an override use the built-in templates
+			if !strings.Contains(rendered.Text, "SCTGULF-261018-ABC123") {
+				t.Errorf("text body does not come from the built-in template:\n%s", rendered.Text)
+			}
+		})
+	}
+}
+
+func TestTemplatesReloadChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	path := writeTemplate(t, dir, jsonTemplate(ChannelTeams), `{"text":"first {{.ReferenceNumber}}"}`)
+	templates, err := NewTemplates(dir, "", nil)
+	if err != nil {
+		t.Fatalf("NewTemplates: %v", err)
+	}
+	if rendered, err := templates.Render(ChannelTeams, hostileEnquiry()); err != nil || !strings.Contains(rendered.Body, "first") {
+		t.Fatalf("Render = %v, %v, want the first template", rendered, err)
+	}
+
+	writeTemplate(t, dir, jsonTemplate(ChannelTeams), `{"text":"second {{.ReferenceNumber}}"}`)
+	// Some file systems keep modification times in whole seconds
+	later := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatalf("Chtimes: %v", err)
+	}
+	if rendered, err := templates.Render(ChannelTeams, hostileEnquiry()); err != nil || !strings.Contains(rendered.Body, "second") {
+		t.Errorf("Render = %v, %v, want the edited template", rendered, err)
+	}
+}
+
+func TestTemplatesRejectBrokenTemplates(t *testing.T) {
+	broken := t.TempDir()
+	writeTemplate(t, broken, filepath.Join("SCTGULF", jsonTemplate(ChannelSlack)), `{"text":"{{.ReferenceNumber"}`)
+	if _, err := NewTemplates(broken, "", nil); err == nil {
+		t.Error("a template that does not parse was accepted at startup")
+	}
+
+	invalidJSON := t.TempDir()
+	writeTemplate(t, invalidJSON, jsonTemplate(ChannelDiscord), `{"content":"{{.ReferenceNumber}}",}`)
+	templates, err := NewTemplates(invalidJSON, "", nil)
+	if err != nil {
+		t.Fatalf("NewTemplates: %v", err)
+	}
+	if _, err := templates.Render(ChannelDiscord, hostileEnquiry()); err == nil {
+		t.Error("a template producing invalid JSON was rendered")
+	}
+
+	if _, err := templates.Render(Channel("sms"), hostileEnquiry()); !errors.Is(err, ErrUnknownChannel) {
+		t.Errorf("Render sms = %v, want ErrUnknownChannel", err)
+	}
+}
@@ -0,0 +1,247 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	netmail "net/mail"
+	"strings"
+	"testing"
+	"time"
+
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/mail"
+)
+
+// hostileEnquiry fills every visitor field with markup of the channels
+func hostileEnquiry() *entities.Enquiry {
+	return &entities.Enquiry{
+		ID:              "enquiry-1",
+		ReferenceNumber: "SCTGULF-261018-ABC123",
+		Source:          "SCTGULF",
+		Name:            "<!channel> @everyone",
+		Email:           "jane@example.com?cc=boss@example.com",
+		PhoneNumber:     "+971 50 123 4567",
+		CompanyName:     "Smith & Sons <@U123>",
+		Subject:         "Quote\r\nBcc: victim@example.com",
+		Message:         "<https://evil|click here> [click here](https://evil) **free** @here",
+		Status:          entities.EnquiryStatusNew,
+		CreatedAt:       time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC),
+	}
+}
+
+// jsonText joins every string of a JSON document, so checks see the text after JSON decoding
+func jsonText(t *testing.T, body string) string {
+	t.Helper()
+	var document interface{}
+	if err := json.Unmarshal([]byte(body), &document); err != nil {
+		t.Fatalf("rendered body is not JSON: %v\n%s", err, body)
+	}
+	var texts []string
+	var walk func(value interface{})
+	walk = func(value interface{}) {
+		switch value := value.(type) {
+		case string:
+			texts = append(texts, value)
+		case []interface{}:
+			for _, item := range value {
+				walk(item)
+			}
+		case map[string]interface{}:
+			for _, item := range value {
+				walk(item)
+			}
+		}
+	}
+	walk(document)
+	return strings.Join(texts, "\n")
+}
+
+func newTestTemplates(t *testing.T) *Templates {
+	t.Helper()
+	templates, err := NewTemplates("", "https://admin.example.com/enquiries/{id}", nil)
+	if err != nil {
+		t.Fatalf("NewTemplates: %v", err)
+	}
+	return templates
+}
+
+func TestRenderEscapesHostileInput(t *testing.T) {
+	tests := []struct {
+		channel Channel
+		want    []string
+		reject  []string
+	}{
+		{
+			channel: ChannelSlack,
+			want: []string{
+				"&lt;!channel&gt; @everyone",
+				"Smith &amp; Sons &lt;@U123&gt;",
+				"&lt;https://evil|click here&gt;",
+				"mailto:jane@example.com%3Fcc=boss@example.com",
+			},
+			reject: []string{"<!channel>", "<@U123>", "<https://evil|", "mailto:jane@example.com?"},
+		},
+		{
+			channel: ChannelTeams,
+			want: []string{
+				`\[click here\](https://evil)`,
+				`\*\*free\*\* @here`,
+				"Smith & Sons <@U123>",
+				"mailto:jane@example.com%3Fcc=boss@example.com",
+			},
+			reject: []string{"[click here](", "**free**", "mailto:jane@example.com?", `\@`, `\&`},
+		},
+		{
+			channel: ChannelDiscord,
+			want: []string{
+				`\<!channel> \@everyone`,
+				`\[click here\](https://evil)`,
+				`\*\*free\*\* \@here`,
+				`Smith & Sons \<@U123>`,
+			},
+			reject: []string{"[click here](", "**free**", " @everyone", " @here", " <@U123>"},
+		},
+	}
+	templates := newTestTemplates(t)
+	for _, tt := range tests {
+		t.Run(string(tt.channel), func(t *testing.T) {
+			rendered, err := templates.Render(tt.channel, hostileEnquiry())
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			text := jsonText(t, rendered.Body)
+			for _, want := range tt.want {
+				if !strings.Contains(text, want) {
+					t.Errorf("rendered text does not contain %q:\n%s", want, text)
+				}
+			}
+			for _, reject := range tt.reject {
+				if strings.Contains(text, reject) {
+					t.Errorf("rendered text contains %q:\n%s", reject, text)
+				}
+			}
+		})
+	}
+}
+
+func TestRenderDiscordDisablesMentions(t *testing.T) {
+	rendered, err := newTestTemplates(t).Render(ChannelDiscord, hostileEnquiry())
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	var payload struct {
+		AllowedMentions *struct {
+			Parse []string `json:"parse"`
+		} `json:"allowed_mentions"`
+	}
+	if err := json.Unmarshal([]byte(rendered.Body), &payload); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	if payload.AllowedMentions == nil || len(payload.AllowedMentions.Parse) != 0 {
+		t.Errorf("allowed_mentions = %+v, want an empty parse list", payload.AllowedMentions)
+	}
+}
+
+// captureSender keeps the sent messages instead of sending them
+type captureSender struct {
+	messages []*mail.Message
+}
+
+func (s *captureSender) Send(_ context.Context, msg *mail.Message) error {
+	s.messages = append(s.messages, msg)
+	return nil
+}
+
+func TestEmailNotifierEscapesHostileInput(t *testing.T) {
+	sender := &captureSender{}
+	notifier := NewEmailNotifier("sales", "SCT <notify@example.com>", []string{"sales@example.com"}, sender, newTestTemplates(t))
+	enquiry := hostileEnquiry()
+	enquiry.Email = "jane@example.com\r\nBcc: victim@example.com"
+	if err := notifier.Notify(context.Background(), Notification{Enquiry: enquiry}); err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	if len(sender.messages) != 1 {
+		t.Fatalf("sent %d messages, want 1", len(sender.messages))
+	}
+
+	raw, err := sender.messages[0].Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	msg, err := netmail.ReadMessage(strings.NewReader(string(raw)))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	if bcc := msg.Header.Get("Bcc"); bcc != "" {
+		t.Errorf("visitor input added a Bcc header: %q", bcc)
+	}
+	if got, want := msg.Header.Get("Reply-To"), "jane@example.com  Bcc: victim@example.com"; got != want {
+		t.Errorf("Reply-To = %q, want %q", got, want)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("DecodeHeader: %v", err)
+	}
+	if want := "[SCTGULF-261018-ABC123] New Customer Enquiry from SCTGULF: Quote  Bcc: victim@example.com"; subject != want {
+		t.Errorf("Subject = %q, want %q", subject, want)
+	}
+
+	text, html := emailBodies(t, msg)
+	for _, want := range []string{"<!channel> @everyone", "<https://evil|click here>"} {
+		if !strings.Contains(text, want) {
+			t.Errorf("text body does not contain %q:\n%s", want, text)
+		}
+	}
+	for _, want := range []string{"&lt;!channel&gt;", "Smith &amp; Sons &lt;@U123&gt;", "&lt;https://evil|click here&gt;"} {
+		if !strings.Contains(html, want) {
+			t.Errorf("HTML body does not contain %q:\n%s", want, html)
+		}
+	}
+	for _, reject := range []string{"<!channel>", "<@U123>", "<https://evil"} {
+		if strings.Contains(html, reject) {
+			t.Errorf("HTML body contains %q:\n%s", reject, html)
+		}
+	}
+}
+
+func TestEmailReplyLinkCannotAddRecipients(t *testing.T) {
+	rendered, err := newTestTemplates(t).Render(ChannelEmail, hostileEnquiry())
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	if !strings.Contains(rendered.Body, `href="mailto:jane@example.com%3Fcc=boss@example.com"`) {
+		t.Errorf("reply link is not escaped:\n%s", rendered.Body)
+	}
+}
+
+// emailBodies decodes the plain text and HTML parts of a multipart/alternative message
+func emailBodies(t *testing.T, msg *netmail.Message) (text, html string) {
+	t.Helper()
+	_, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("ParseMediaType: %v", err)
+	}
+	reader := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		part, err := reader.NextRawPart()
+		if err == io.EOF {
+			return text, html
+		}
+		if err != nil {
+			t.Fatalf("NextRawPart: %v", err)
+		}
+		body, err := io.ReadAll(quotedprintable.NewReader(part))
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		if strings.HasPrefix(part.Header.Get("Content-Type"), "text/html") {
+			html = string(body)
+		} else {
+			text = string(body)
+		}
+	}
+}
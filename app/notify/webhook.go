@@ -0,0 +1,152 @@
+package notify
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/entities"
+)
+
+const (
+	// WebhookEventEnquiryCreated is the event name sent for new enquiries
+	WebhookEventEnquiryCreated = "enquiry.created"
+	// WebhookEventEnquiryResubmitted is the event name sent when a duplicate was linked to an enquiry
+	WebhookEventEnquiryResubmitted = "enquiry.resubmitted"
+	// WebhookSignatureHeader carries the hex encoded HMAC-SHA256 of the request body
+	WebhookSignatureHeader = "X-SCT-Signature"
+)
+
+// WebhookNotifier posts enquiries as plain JSON to any HTTP endpoint.
+// When a secret is configured the body is signed so the receiver can verify it.
+type WebhookNotifier struct {
+	name   string
+	url    string
+	secret string
+	client *http.Client
+	links  *attachment.Links
+}
+
+// NewWebhookNotifier creates a notifier for a generic JSON webhook.
+// links signs the download links of resubmitted files, it is nil when attachments are disabled.
+func NewWebhookNotifier(name, url, secret string, client *http.Client, links *attachment.Links) *WebhookNotifier {
+	return &WebhookNotifier{
+		name:   name,
+		url:    url,
+		secret: secret,
+		client: client,
+		links:  links,
+	}
+}
+
+// Name identifies the notifier
+func (n *WebhookNotifier) Name() string {
+	return n.name
+}
+
+// webhookPayload is the JSON document sent to generic webhooks
+type webhookPayload struct {
+	Event        string               `json:"event"`
+	SentAt       time.Time            `json:"sentAt"`
+	Enquiry      webhookEnquiry       `json:"enquiry"`
+	Resubmission *webhookResubmission `json:"resubmission,omitempty"`
+}
+
+type webhookResubmission struct {
+	Source      string              `json:"source"`
+	Subject     string              `json:"subject"`
+	ReceivedAt  time.Time           `json:"receivedAt"`
+	Attachments []webhookAttachment `json:"attachments,omitempty"`
+}
+
+type webhookAttachment struct {
+	FileName    string `json:"fileName"`
+	ContentType string `json:"contentType"`
+	Size        int64  `json:"size"`
+	DownloadURL string `json:"downloadUrl,omitempty"`
+}
+
+type webhookEnquiry struct {
+	ID              string    `json:"id"`
+	ReferenceNumber string    `json:"referenceNumber"`
+	Source          string    `json:"source"`
+	Status          string    `json:"status"`
+	Name            string    `json:"name"`
+	Email           string    `json:"email"`
+	PhoneNumber     string    `json:"phoneNumber"`
+	CompanyName     string    `json:"companyName"`
+	Subject         string    `json:"subject"`
+	Message         string    `json:"message"`
+	CreatedAt       time.Time `json:"createdAt"`
+}
+
+// Notify posts the enquiry as JSON
+func (n *WebhookNotifier) Notify(ctx context.Context, notification Notification) error {
+	payload := renderWebhookPayload(notification.Enquiry)
+	if notification.IsResubmission() {
+		payload.Event = WebhookEventEnquiryResubmitted
+		payload.Resubmission = n.renderResubmission(notification.Enquiry, notification.Resubmission)
+	}
+
+	headers := map[string]string{}
+	if n.secret != "" {
+		body, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("error marshalling JSON: %w", err)
+		}
+		headers[WebhookSignatureHeader] = "sha256=" + SignWebhookBody(n.secret, body)
+		return postJSON(ctx, n.client, n.url, json.RawMessage(body), headers)
+	}
+	return postJSON(ctx, n.client, n.url, payload, headers)
+}
+
+// SignWebhookBody returns the hex encoded HMAC-SHA256 of the body
+func SignWebhookBody(secret string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func renderWebhookPayload(enquiry *entities.Enquiry) webhookPayload {
+	return webhookPayload{
+		Event:  WebhookEventEnquiryCreated,
+		SentAt: time.Now().UTC(),
+		Enquiry: webhookEnquiry{
+			ID:              enquiry.ID,
+			ReferenceNumber: enquiry.ReferenceNumber,
+			Source:          enquiry.Source,
+			Status:          enquiry.Status,
+			Name:            enquiry.Name,
+			Email:           enquiry.Email,
+			PhoneNumber:     enquiry.PhoneNumber,
+			CompanyName:     enquiry.CompanyName,
+			Subject:         enquiry.Subject,
+			Message:         enquiry.Message,
+			CreatedAt:       enquiry.CreatedAt,
+		},
+	}
+}
+
+// renderResubmission describes the resubmission with a signed download link of every file sent with it
+func (n *WebhookNotifier) renderResubmission(enquiry *entities.Enquiry, resubmission *entities.EnquiryResubmission) *webhookResubmission {
+	rendered := &webhookResubmission{
+		Source:     resubmission.Source,
+		Subject:    resubmission.Subject,
+		ReceivedAt: resubmission.ReceivedAt,
+	}
+	for _, file := range resubmission.Attachments {
+		rendered.Attachments = append(rendered.Attachments, webhookAttachment{
+			FileName:    file.FileName,
+			ContentType: file.ContentType,
+			Size:        file.Size,
+			DownloadURL: n.links.URL(enquiry.ID, file),
+		})
+	}
+	return rendered
+}
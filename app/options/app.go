@@ -3,10 +3,15 @@ package options
 import (
 	"go.uber.org/fx"
 
+	"sct-backend-service/app/options/attachment"
 	"sct-backend-service/app/options/config"
 	"sct-backend-service/app/options/data"
 	"sct-backend-service/app/options/http"
+	"sct-backend-service/app/options/notify"
+	"sct-backend-service/app/options/ratelimit"
 	"sct-backend-service/app/options/service"
+	"sct-backend-service/app/options/slack"
+	"sct-backend-service/app/options/spam"
 )
 
 // CreateApplication creates the fx application with all dependencies
@@ -15,8 +20,15 @@ func CreateApplication(configFilePath string) *fx.App {
 		config.ConfigFxOption(configFilePath),
 		config.LoggerFxOption(),
 		data.QueryFxOption(),
+		data.RepositoryFxOption(),
+		attachment.AttachmentFxOption(),
+		notify.NotifierFxOption(),
+		notify.OutboxWorkerFxOption(),
+		spam.SpamFxOption(),
+		ratelimit.RateLimitFxOption(),
 		service.ControllerFxOption(),
 		service.WorkflowFxOption(),
+		slack.SlackFxOption(),
 		http.HttpFxOption(),
 	)
 }
@@ -0,0 +1,129 @@
+package attachment
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	appattachment "sct-backend-service/app/attachment"
+	"sct-backend-service/app/blob"
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/app/options/config"
+	"sct-backend-service/internal/middleware"
+)
+
+// blobTimeout bounds a single request to the S3 backend, uploads of large files included
+const blobTimeout = 2 * time.Minute
+
+// multipartOverhead leaves room for the operations and map parts of a multipart request next to the files
+const multipartOverhead = 1 << 20
+
+// Attachments holds what the HTTP servers need of attachments
+type Attachments struct {
+	// Downloads serves the signed download links, nil when attachments are disabled.
+	// Requests with the admin token are authenticated, so expired links can be signed again.
+	Downloads http.Handler
+	// MaxUploadSize bounds the body of a multipart GraphQL request, zero keeps the gqlgen default
+	MaxUploadSize int64
+}
+
+// AttachmentFxOption provides the attachment limits, storage and download links via fx
+func AttachmentFxOption() fx.Option {
+	return fx.Provide(
+		NewLimits,
+		NewBlobStore,
+		NewLinks,
+		NewAttachments,
+	)
+}
+
+// NewLimits creates the limits the workflow checks uploads against, a disabled feature allows no files
+func NewLimits(cfg *config.Config) (appattachment.Limits, error) {
+	attachments := cfg.Attachments
+	if !attachments.Enabled {
+		return appattachment.Limits{}, nil
+	}
+	if attachments.MaxFiles < 1 || attachments.MaxFileSize < 1 {
+		return appattachment.Limits{}, fmt.Errorf("attachments need a positive maxFiles and maxFileSize")
+	}
+	if attachments.MaxTotalSize < attachments.MaxFileSize {
+		return appattachment.Limits{}, fmt.Errorf("attachment maxTotalSize must be at least maxFileSize")
+	}
+	allowedTypes := attachments.AllowedTypes
+	if len(allowedTypes) == 0 {
+		allowedTypes = appattachment.DefaultAllowedTypes
+	}
+	return appattachment.Limits{
+		MaxFiles:     attachments.MaxFiles,
+		MaxFileSize:  attachments.MaxFileSize,
+		MaxTotalSize: attachments.MaxTotalSize,
+		AllowedTypes: allowedTypes,
+	}, nil
+}
+
+// NewBlobStore creates the configured storage backend, or returns nil when attachments are disabled
+func NewBlobStore(cfg *config.Config, logger *zap.Logger) (blob.Store, error) {
+	if !cfg.Attachments.Enabled {
+		return nil, nil
+	}
+
+	storage := cfg.Attachments.Storage
+	switch storage.Backend {
+	case keys.BlobStorageDisk:
+		logger.Info("Attachments are stored on disk", zap.String("dir", storage.Dir))
+		return blob.NewDiskStore(storage.Dir)
+	case keys.BlobStorageS3:
+		logger.Info("Attachments are stored in S3", zap.String("bucket", storage.S3.Bucket))
+		return blob.NewS3Store(blob.S3Config{
+			Endpoint:        storage.S3.Endpoint,
+			Region:          storage.S3.Region,
+			Bucket:          storage.S3.Bucket,
+			Prefix:          storage.S3.Prefix,
+			PathStyle:       storage.S3.PathStyle,
+			AccessKeyID:     storage.S3.AccessKeyID,
+			SecretAccessKey: storage.S3.SecretAccessKey,
+		}, &http.Client{Timeout: blobTimeout})
+	default:
+		return nil, fmt.Errorf("unknown attachment storage backend %q", storage.Backend)
+	}
+}
+
+// NewLinks creates the signer of download links, or returns nil when attachments are disabled
+func NewLinks(cfg *config.Config) (*appattachment.Links, error) {
+	attachments := cfg.Attachments
+	if !attachments.Enabled {
+		return nil, nil
+	}
+	if publicURL, err := url.Parse(attachments.PublicURL); err != nil || !publicURL.IsAbs() {
+		return nil, fmt.Errorf("attachments need the absolute public URL of the service, set %s", keys.AttachmentPublicURLEnvKey)
+	}
+	if attachments.SigningSecret == "" {
+		return nil, fmt.Errorf("attachments need a link signing secret, set %s", keys.AttachmentSigningSecretEnvKey)
+	}
+	if attachments.LinkExpiry.Duration <= 0 {
+		return nil, fmt.Errorf("attachment linkExpiry must be positive")
+	}
+	return appattachment.NewLinks(attachments.PublicURL, attachments.SigningSecret, attachments.LinkExpiry.Duration), nil
+}
+
+// NewAttachments creates the download endpoint and sizes multipart requests to fit the files of a whole request
+func NewAttachments(
+	limits appattachment.Limits,
+	store blob.Store,
+	links *appattachment.Links,
+	enquiryRepository data.EnquiryRepository,
+	logger *zap.Logger,
+) *Attachments {
+	if store == nil || links == nil {
+		return &Attachments{}
+	}
+	return &Attachments{
+		Downloads:     middleware.AuthMiddleware(appattachment.NewHandler(enquiryRepository, store, links, logger)),
+		MaxUploadSize: limits.MaxTotalSize + multipartOverhead,
+	}
+}
@@ -1,15 +1,56 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
 	"go.uber.org/fx"
 	"go.uber.org/zap"
+
+	"sct-backend-service/app/keys"
 )
 
 // Config holds application configuration
 type Config struct {
-	Server ServerConfig
-	DB     DBConfig
-	Log    LogConfig
+	Server      ServerConfig
+	DB          DBConfig
+	Log         LogConfig
+	Notify      NotifyConfig
+	Routing     RoutingConfig
+	Outbox      OutboxConfig
+	Submission  SubmissionConfig
+	Idempotency IdempotencyConfig
+	Spam        SpamConfig
+	RateLimit   RateLimitConfig
+	Phone       PhoneConfig
+	Slack       SlackConfig
+	Attachments AttachmentsConfig
+	// Captcha maps a WebsiteSource to its captcha provider, sources without an entry need no captcha
+	Captcha map[string]CaptchaConfig
+}
+
+// Duration is a time.Duration that is written as a string like "30s" in the config file
+type Duration struct {
+	time.Duration
+}
+
+// UnmarshalJSON parses a duration string such as "1m30s"
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	d.Duration = parsed
+	return nil
 }
 
 // ServerConfig holds server configuration
@@ -21,11 +62,269 @@ type ServerConfig struct {
 
 // DBConfig holds database configuration
 type DBConfig struct {
+	// Driver selects the enquiry store, see keys.DBDriverMemory and keys.DBDriverPostgres
+	Driver   string
+	URL      string
 	Host     string
 	Port     int
 	Name     string
 	User     string
 	Password string
+	SSLMode  string
+}
+
+// DSN returns the connection string for the configured database
+func (c DBConfig) DSN() string {
+	if c.URL != "" {
+		return c.URL
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     url.UserPassword(c.User, c.Password),
+		Host:     fmt.Sprintf("%s:%d", c.Host, c.Port),
+		Path:     c.Name,
+		RawQuery: url.Values{"sslmode": []string{c.SSLMode}}.Encode(),
+	}
+	return dsn.String()
+}
+
+// NotifyConfig holds notification backend configuration.
+// Every entry becomes a separate notifier, so one enquiry can fan out to several channels.
+type NotifyConfig struct {
+	Slack   []SlackNotifierConfig
+	Email   []EmailNotifierConfig
+	Teams   []WebhookNotifierConfig
+	Discord []WebhookNotifierConfig
+	Webhook []WebhookNotifierConfig
+	// TemplatesDir overrides the built-in message templates, see notify.Templates for the layout
+	TemplatesDir string
+	// EnquiryURL links an enquiry in the admin UI, {id} is replaced by the enquiry ID
+	EnquiryURL string
+	// Acknowledgement is the email customers get after submitting the form
+	Acknowledgement AcknowledgementConfig
+}
+
+// SlackNotifierConfig holds the settings of a Slack incoming webhook, or of a bot posting through the Web API.
+// A bot token makes the notifier use the Web API, which threads follow-ups and updates the posted message.
+type SlackNotifierConfig struct {
+	Name       string
+	WebhookURL string
+	// BotToken authenticates Web API calls, it is read from SLACK_BOT_TOKEN_<NAME>
+	BotToken string
+	// Channel is the ID of the channel the bot posts to
+	Channel string
+	// APIURL overrides the Web API base URL, e.g. to talk to a fake Slack API in tests
+	APIURL string
+}
+
+// EmailNotifierConfig holds the settings of an email notifier
+type EmailNotifierConfig struct {
+	Name string
+	SMTP SMTPConfig
+	From string
+	To   []string
+}
+
+// SMTPConfig holds the settings of an SMTP relay
+type SMTPConfig struct {
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	ImplicitTLS bool
+}
+
+// AcknowledgementConfig holds the settings of the acknowledgement email sent to customers
+type AcknowledgementConfig struct {
+	// SMTP is the relay the emails go through, the SMTP_* variables are used when it has no host.
+	// ACKNOWLEDGEMENT_SMTP_PASSWORD overrides the password.
+	SMTP SMTPConfig
+	// Sites maps a WebsiteSource to its branding, sources without an enabled entry send no acknowledgement
+	Sites map[string]AcknowledgementSiteConfig
+}
+
+// AcknowledgementSiteConfig holds the branding of one site's acknowledgement email
+type AcknowledgementSiteConfig struct {
+	// Enabled turns the acknowledgement on for the site
+	Enabled bool
+	// From is the sender, e.g. "SCT Gulf <no-reply@sctgulf.com>"
+	From string
+	// ReplyTo receives the customer's replies, e.g. the sales mailbox
+	ReplyTo string
+	// Name is how the email refers to the site, the source is used when empty
+	Name string
+	// LogoURL is the absolute URL of the logo shown above the email
+	LogoURL string
+	// Signature closes the email, line breaks are kept
+	Signature string
+	// Localized holds the name and signature for enquiries in another language, keyed by locale, e.g. "ar"
+	Localized map[string]AcknowledgementLocaleConfig
+}
+
+// AcknowledgementLocaleConfig is the branding of a site's acknowledgement in one language
+type AcknowledgementLocaleConfig struct {
+	Name      string
+	Signature string
+}
+
+// WebhookNotifierConfig holds the settings of a webhook based notifier (Teams, Discord or generic JSON)
+type WebhookNotifierConfig struct {
+	Name string
+	URL  string
+	// Secret signs the request body, only used by generic webhooks
+	Secret string
+}
+
+// RoutingConfig maps enquiries to notifiers.
+// When no routes and no fallback are configured every enquiry goes to every notifier.
+type RoutingConfig struct {
+	Routes []RouteConfig
+	// Fallback lists the notifiers used when no route matches
+	Fallback []string
+}
+
+// RouteConfig holds a single routing rule, see notify.Route
+type RouteConfig struct {
+	Name            string
+	Sources         []string
+	SubjectKeywords []string
+	Countries       []string
+	Destinations    []string
+	Final           bool
+}
+
+// OutboxConfig holds the notification delivery settings
+type OutboxConfig struct {
+	// PollInterval is how often the worker looks for due messages
+	PollInterval Duration
+	// BatchSize is the number of messages claimed per poll
+	BatchSize int
+	// MaxAttempts is the number of delivery attempts before a message is dead-lettered
+	MaxAttempts int
+	// InitialBackoff is the delay after the first failed attempt, doubled after every further failure
+	InitialBackoff Duration
+	// MaxBackoff caps the delay between attempts
+	MaxBackoff Duration
+	// Lease is how long a claimed message stays locked to one worker
+	Lease Duration
+}
+
+// SubmissionConfig holds the settings for processing sendContactInfo requests
+type SubmissionConfig struct {
+	// MaxConcurrency is the number of contacts of one request processed in parallel
+	MaxConcurrency int
+	// DuplicateWindow is how long an identical submission is linked to the original enquiry, "0s" disables it
+	DuplicateWindow Duration
+}
+
+// IdempotencyConfig holds the settings for replaying requests made with an idempotency key
+type IdempotencyConfig struct {
+	// Window is how long the first response of a key is replayed
+	Window Duration
+	// LockTimeout is how long a request may hold its key before a repeat may run it again
+	LockTimeout Duration
+	// WaitTimeout bounds how long a repeat waits for the first request to finish
+	WaitTimeout Duration
+}
+
+// SpamConfig holds the spam filter settings
+type SpamConfig struct {
+	// Threshold is the score at which an enquiry is quarantined as SPAM, zero disables the filter
+	Threshold float64
+	// MaxLinks is the number of links a message may contain before it scores
+	MaxLinks int
+	// Keywords are phrases typical for spam, matched case-insensitively
+	Keywords []string
+	// BlockedEmailDomains also block their subdomains
+	BlockedEmailDomains []string
+	// BlockedIPs lists IP addresses and CIDR networks with a bad reputation
+	BlockedIPs []string
+}
+
+// RateLimitConfig holds the settings of the @rateLimit directive, the limits themselves are in the schema
+type RateLimitConfig struct {
+	// Enabled turns rate limiting on
+	Enabled bool
+	// Store selects where buckets are kept, see keys.RateLimitStoreMemory and keys.RateLimitStoreRedis
+	Store string
+	// RedisURL is the connection URL of the Redis store
+	RedisURL string
+}
+
+// PhoneConfig holds the phone number parsing settings
+type PhoneConfig struct {
+	// DefaultRegions maps a WebsiteSource to the ISO 3166-1 alpha-2 region of numbers entered without a country code
+	DefaultRegions map[string]string
+}
+
+// CaptchaConfig holds the captcha provider of a single source
+type CaptchaConfig struct {
+	// Provider is one of turnstile, hcaptcha, recaptcha or fake
+	Provider string
+	// Secret is the provider secret key, or the accepted token for the fake provider.
+	// CAPTCHA_SECRET_<SOURCE> overrides it.
+	Secret string
+	// VerifyURL overrides the provider's verification endpoint
+	VerifyURL string
+	// MinScore is the lowest accepted reCAPTCHA v3 score
+	MinScore float64
+	// Action is the expected reCAPTCHA v3 action, not checked when empty
+	Action string
+}
+
+// SlackConfig holds the settings of the Slack app that receives button clicks from enquiry messages
+type SlackConfig struct {
+	// SigningSecret verifies requests from Slack, SLACK_SIGNING_SECRET overrides it.
+	// The Slack endpoints are not served without it.
+	SigningSecret string
+}
+
+// AttachmentsConfig holds the settings of files sent with enquiries
+type AttachmentsConfig struct {
+	// Enabled accepts attachments, contacts with files are rejected otherwise
+	Enabled bool
+	// MaxFiles is the number of files one contact may attach
+	MaxFiles int
+	// MaxFileSize is the largest accepted file in bytes
+	MaxFileSize int64
+	// MaxTotalSize is the largest total of the files of all contacts of one request in bytes
+	MaxTotalSize int64
+	// AllowedTypes lists the accepted MIME types, a type/* entry accepts every subtype.
+	// attachment.DefaultAllowedTypes is used when it is empty.
+	AllowedTypes []string
+	// PublicURL is where this service is reachable, download links are built on it.
+	// ATTACHMENT_PUBLIC_URL overrides it.
+	PublicURL string
+	// LinkExpiry is how long a download link works without the admin token
+	LinkExpiry Duration
+	// SigningSecret signs download links, ATTACHMENT_SIGNING_SECRET overrides it
+	SigningSecret string
+	// Storage is where the files are kept
+	Storage BlobStorageConfig
+}
+
+// BlobStorageConfig selects the blob storage backend of attachments
+type BlobStorageConfig struct {
+	// Backend is disk or s3, see keys.BlobStorageDisk and keys.BlobStorageS3
+	Backend string
+	// Dir is the directory of the disk backend
+	Dir string
+	S3  S3Config
+}
+
+// S3Config holds the bucket of the s3 backend, any S3 compatible service works
+type S3Config struct {
+	// Endpoint is the service URL, e.g. https://s3.eu-west-1.amazonaws.com or http://localhost:9000 for MinIO
+	Endpoint string
+	Region   string
+	Bucket   string
+	// Prefix is put in front of every object key
+	Prefix string
+	// PathStyle puts the bucket in the path instead of the host name, most S3 compatible services need it
+	PathStyle bool
+	// AccessKeyID and SecretAccessKey are overridden by ATTACHMENT_S3_ACCESS_KEY_ID and ATTACHMENT_S3_SECRET_ACCESS_KEY
+	AccessKeyID     string
+	SecretAccessKey string
 }
 
 // LogConfig holds logging configuration
@@ -37,28 +336,239 @@ type LogConfig struct {
 // ConfigFxOption provides configuration via fx
 func ConfigFxOption(configFilePath string) fx.Option {
 	return fx.Provide(func() (*Config, error) {
-		// TODO: Load configuration from file
-		// For now, return default configuration
-		return &Config{
+		cfg := &Config{
 			Server: ServerConfig{
 				Port:     8080,
 				Host:     "0.0.0.0",
 				LogLevel: "info",
 			},
 			DB: DBConfig{
-				Host: "localhost",
-				Port: 5432,
-				Name: "sct_db",
-				User: "postgres",
+				Driver:  keys.DBDriverMemory,
+				Host:    "localhost",
+				Port:    5432,
+				Name:    "sct_db",
+				User:    "postgres",
+				SSLMode: "disable",
 			},
 			Log: LogConfig{
 				Level:  "info",
 				Format: "json",
 			},
-		}, nil
+			Outbox: OutboxConfig{
+				PollInterval:   Duration{2 * time.Second},
+				BatchSize:      20,
+				MaxAttempts:    10,
+				InitialBackoff: Duration{5 * time.Second},
+				MaxBackoff:     Duration{15 * time.Minute},
+				Lease:          Duration{2 * time.Minute},
+			},
+			Submission: SubmissionConfig{
+				MaxConcurrency:  4,
+				DuplicateWindow: Duration{30 * time.Minute},
+			},
+			Spam: SpamConfig{
+				Threshold: 5,
+				MaxLinks:  2,
+				Keywords:  []string{"casino", "viagra", "cialis", "backlinks", "seo services", "forex", "crypto investment", "loan offer"},
+			},
+			RateLimit: RateLimitConfig{
+				Enabled: true,
+				Store:   keys.RateLimitStoreMemory,
+			},
+			Phone: PhoneConfig{
+				DefaultRegions: map[string]string{
+					"SCTSPL":  "IN",
+					"SCTGULF": "AE",
+					"AGEM":    "IN",
+				},
+			},
+			Attachments: AttachmentsConfig{
+				MaxFiles:     5,
+				MaxFileSize:  10 << 20,
+				MaxTotalSize: 50 << 20,
+				LinkExpiry:   Duration{7 * 24 * time.Hour},
+				Storage: BlobStorageConfig{
+					Backend: keys.BlobStorageDisk,
+					Dir:     "./data/attachments",
+				},
+			},
+			Idempotency: IdempotencyConfig{
+				Window:      Duration{24 * time.Hour},
+				LockTimeout: Duration{time.Minute},
+				WaitTimeout: Duration{30 * time.Second},
+			},
+		}
+
+		if configFilePath == "" {
+			configFilePath = os.Getenv(keys.ConfigFileEnvKey)
+		}
+		if configFilePath != "" {
+			if err := loadConfigFile(configFilePath, cfg); err != nil {
+				return nil, err
+			}
+		}
+
+		applyEnvOverrides(cfg)
+		return cfg, nil
 	})
 }
 
+// loadConfigFile reads a JSON configuration file over the defaults.
+// Keys match the field names case-insensitively, unknown keys are rejected to catch typos.
+func loadConfigFile(path string, cfg *Config) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("failed to open config file: %w", err)
+	}
+	defer file.Close()
+
+	decoder := json.NewDecoder(file)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(cfg); err != nil {
+		return fmt.Errorf("failed to parse config file %s: %w", path, err)
+	}
+	return nil
+}
+
+// applyEnvOverrides overrides configuration values with environment variables.
+// Secrets are only ever read from the environment.
+func applyEnvOverrides(cfg *Config) {
+	if driver := os.Getenv(keys.DBDriverEnvKey); driver != "" {
+		cfg.DB.Driver = driver
+	}
+	if dbURL := os.Getenv(keys.DatabaseURLEnvKey); dbURL != "" {
+		cfg.DB.URL = dbURL
+		if os.Getenv(keys.DBDriverEnvKey) == "" {
+			cfg.DB.Driver = keys.DBDriverPostgres
+		}
+	}
+	if password := os.Getenv(keys.DBPasswordEnvKey); password != "" {
+		cfg.DB.Password = password
+	}
+
+	if templatesDir := os.Getenv(keys.NotifyTemplatesDirEnvKey); templatesDir != "" {
+		cfg.Notify.TemplatesDir = templatesDir
+	}
+	if enquiryURL := os.Getenv(keys.NotifyEnquiryURLEnvKey); enquiryURL != "" {
+		cfg.Notify.EnquiryURL = enquiryURL
+	}
+
+	if secret := os.Getenv(keys.SlackSigningSecretEnvKey); secret != "" {
+		cfg.Slack.SigningSecret = secret
+	}
+
+	if secret := os.Getenv(keys.AttachmentSigningSecretEnvKey); secret != "" {
+		cfg.Attachments.SigningSecret = secret
+	}
+	if publicURL := os.Getenv(keys.AttachmentPublicURLEnvKey); publicURL != "" {
+		cfg.Attachments.PublicURL = publicURL
+	}
+	if accessKeyID := os.Getenv(keys.AttachmentS3AccessKeyIDEnvKey); accessKeyID != "" {
+		cfg.Attachments.Storage.S3.AccessKeyID = accessKeyID
+	}
+	if secretAccessKey := os.Getenv(keys.AttachmentS3SecretAccessKeyEnvKey); secretAccessKey != "" {
+		cfg.Attachments.Storage.S3.SecretAccessKey = secretAccessKey
+	}
+
+	if store := os.Getenv(keys.RateLimitStoreEnvKey); store != "" {
+		cfg.RateLimit.Store = store
+	}
+	if redisURL := os.Getenv(keys.RedisURLEnvKey); redisURL != "" {
+		cfg.RateLimit.RedisURL = redisURL
+		if os.Getenv(keys.RateLimitStoreEnvKey) == "" {
+			cfg.RateLimit.Store = keys.RateLimitStoreRedis
+		}
+	}
+
+	for i, slack := range cfg.Notify.Slack {
+		if token := os.Getenv(keys.SlackBotTokenEnvKeyPrefix + EnvKeySuffix(slack.Name)); token != "" {
+			cfg.Notify.Slack[i].BotToken = token
+		}
+	}
+	webhookURL, botToken := os.Getenv(keys.SlackWebhookEnvKey), os.Getenv(keys.SlackBotTokenEnvKey)
+	if webhookURL != "" || botToken != "" {
+		cfg.Notify.Slack = append(cfg.Notify.Slack, SlackNotifierConfig{
+			Name:       keys.NotifierSlack,
+			WebhookURL: webhookURL,
+			BotToken:   botToken,
+			Channel:    os.Getenv(keys.SlackChannelEnvKey),
+			APIURL:     os.Getenv(keys.SlackAPIURLEnvKey),
+		})
+	}
+	if webhookURL := os.Getenv(keys.TeamsWebhookEnvKey); webhookURL != "" {
+		cfg.Notify.Teams = append(cfg.Notify.Teams, WebhookNotifierConfig{
+			Name: keys.NotifierTeams,
+			URL:  webhookURL,
+		})
+	}
+	if webhookURL := os.Getenv(keys.DiscordWebhookEnvKey); webhookURL != "" {
+		cfg.Notify.Discord = append(cfg.Notify.Discord, WebhookNotifierConfig{
+			Name: keys.NotifierDiscord,
+			URL:  webhookURL,
+		})
+	}
+	if webhookURL := os.Getenv(keys.NotifyWebhookEnvKey); webhookURL != "" {
+		cfg.Notify.Webhook = append(cfg.Notify.Webhook, WebhookNotifierConfig{
+			Name:   keys.NotifierWebhook,
+			URL:    webhookURL,
+			Secret: os.Getenv(keys.NotifyWebhookSecretEnvKey),
+		})
+	}
+	if host, to := os.Getenv(keys.SMTPHostEnvKey), os.Getenv(keys.NotifyEmailToEnvKey); host != "" && to != "" {
+		cfg.Notify.Email = append(cfg.Notify.Email, EmailNotifierConfig{
+			Name: keys.NotifierEmail,
+			SMTP: smtpConfigFromEnv(),
+			From: os.Getenv(keys.SMTPFromEnvKey),
+			To:   splitList(to),
+		})
+	}
+
+	if cfg.Notify.Acknowledgement.SMTP.Host == "" && os.Getenv(keys.SMTPHostEnvKey) != "" {
+		cfg.Notify.Acknowledgement.SMTP = smtpConfigFromEnv()
+	}
+	if password := os.Getenv(keys.AcknowledgementSMTPPasswordEnvKey); password != "" {
+		cfg.Notify.Acknowledgement.SMTP.Password = password
+	}
+
+	for source, captcha := range cfg.Captcha {
+		if secret := os.Getenv(keys.CaptchaSecretEnvKeyPrefix + strings.ToUpper(source)); secret != "" {
+			captcha.Secret = secret
+			cfg.Captcha[source] = captcha
+		}
+	}
+}
+
+// EnvKeySuffix turns a configured name into the suffix of an environment variable name, e.g. slack-gulf into SLACK_GULF
+func EnvKeySuffix(name string) string {
+	return strings.ToUpper(strings.ReplaceAll(name, "-", "_"))
+}
+
+// smtpConfigFromEnv reads the SMTP relay settings, defaulting to the submission port
+func smtpConfigFromEnv() SMTPConfig {
+	smtp := SMTPConfig{
+		Host:     os.Getenv(keys.SMTPHostEnvKey),
+		Port:     587,
+		Username: os.Getenv(keys.SMTPUsernameEnvKey),
+		Password: os.Getenv(keys.SMTPPasswordEnvKey),
+	}
+	if port, err := strconv.Atoi(os.Getenv(keys.SMTPPortEnvKey)); err == nil {
+		smtp.Port = port
+	}
+	smtp.ImplicitTLS = smtp.Port == 465
+	return smtp
+}
+
+// splitList splits a comma separated list, dropping empty entries
+func splitList(s string) []string {
+	var items []string
+	for _, item := range strings.Split(s, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
 // LoggerFxOption provides logger via fx
 func LoggerFxOption() fx.Option {
 	return fx.Provide(func(config *Config) (*zap.Logger, error) {
@@ -1,14 +1,90 @@
 package data
 
 import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	_ "github.com/lib/pq"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 
+	appdata "sct-backend-service/app/data"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/app/options/config"
 	"sct-backend-service/app/query"
 )
 
+// Repositories holds every repository backed by the configured store
+type Repositories struct {
+	fx.Out
+	EnquiryRepository     appdata.EnquiryRepository
+	OutboxRepository      appdata.OutboxRepository
+	IdempotencyRepository appdata.IdempotencyRepository
+}
+
 // QueryFxOption provides query builder dependencies via fx
 func QueryFxOption() fx.Option {
 	return fx.Options(
 		fx.Provide(query.NewQueryBuilder),
 	)
 }
+
+// RepositoryFxOption provides repository dependencies via fx
+func RepositoryFxOption() fx.Option {
+	return fx.Options(
+		fx.Provide(NewRepositories),
+	)
+}
+
+// NewRepositories creates the repositories for the store selected by the DB driver
+func NewRepositories(
+	lc fx.Lifecycle,
+	cfg *config.Config,
+	logger *zap.Logger,
+	queryBuilder *query.QueryBuilder,
+) (Repositories, error) {
+	switch cfg.DB.Driver {
+	case "", keys.DBDriverMemory:
+		logger.Warn("Using in-memory store, enquiries will not survive a restart")
+		outbox := appdata.NewMemoryOutboxRepository()
+		return Repositories{
+			EnquiryRepository:     appdata.NewMemoryEnquiryRepository(outbox),
+			OutboxRepository:      outbox,
+			IdempotencyRepository: appdata.NewMemoryIdempotencyRepository(),
+		}, nil
+	case keys.DBDriverPostgres:
+		db, err := sql.Open("postgres", cfg.DB.DSN())
+		if err != nil {
+			return Repositories{}, fmt.Errorf("failed to open database: %w", err)
+		}
+
+		enquiries := appdata.NewSQLEnquiryRepository(db, queryBuilder)
+		outbox := appdata.NewSQLOutboxRepository(db, queryBuilder)
+		idempotency := appdata.NewSQLIdempotencyRepository(db, queryBuilder)
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				if err := db.PingContext(ctx); err != nil {
+					return fmt.Errorf("failed to connect to database: %w", err)
+				}
+				if err := enquiries.EnsureSchema(ctx); err != nil {
+					return err
+				}
+				if err := outbox.EnsureSchema(ctx); err != nil {
+					return err
+				}
+				return idempotency.EnsureSchema(ctx)
+			},
+			OnStop: func(ctx context.Context) error {
+				return db.Close()
+			},
+		})
+		return Repositories{
+			EnquiryRepository:     enquiries,
+			OutboxRepository:      outbox,
+			IdempotencyRepository: idempotency,
+		}, nil
+	default:
+		return Repositories{}, fmt.Errorf("unsupported db driver: %s", cfg.DB.Driver)
+	}
+}
@@ -10,9 +10,13 @@ import (
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 
+	"sct-backend-service/app/options/attachment"
 	"sct-backend-service/app/options/config"
+	"sct-backend-service/app/options/slack"
+	"sct-backend-service/app/ratelimit"
 	"sct-backend-service/app/workflow"
 	"sct-backend-service/graph"
+	"sct-backend-service/internal/directives"
 	"sct-backend-service/internal/server"
 )
 
@@ -27,6 +31,9 @@ func NewHTTPServer(
 	cfg *config.Config,
 	logger *zap.Logger,
 	workflowService workflow.WorkflowGraphQLService,
+	limiter *ratelimit.Limiter,
+	slackHandlers *slack.Handlers,
+	attachments *attachment.Attachments,
 ) (*HTTPServer, error) {
 	// Create resolver
 	resolver := &graph.Resolver{
@@ -44,6 +51,11 @@ func NewHTTPServer(
 		WithPlaygroundPath("/").
 		WithGraphQLPath("/query").
 		WithResolvers(resolver).
+		WithDirectives(directives.NewDirectiveRoot(directives.Deps{Limiter: limiter})).
+		WithSlackInteractions(slackHandlers.Interactions).
+		WithSlackCommands(slackHandlers.Commands).
+		WithAttachments(attachments.Downloads).
+		WithMaxUploadSize(attachments.MaxUploadSize).
 		Build()
 
 	if err != nil {
@@ -67,7 +79,10 @@ func NewHTTPServer(
 		OnStop: func(ctx context.Context) error {
 			shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
 			defer cancel()
-			return srv.Shutdown(shutdownCtx)
+			err := srv.Shutdown(shutdownCtx)
+			// Acknowledged Slack interactions are still applied
+			slackHandlers.Wait()
+			return err
 		},
 	})
 
@@ -0,0 +1,261 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/i18n"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/app/mail"
+	appnotify "sct-backend-service/app/notify"
+	"sct-backend-service/app/options/config"
+	"sct-backend-service/app/outbox"
+	"sct-backend-service/graph/model"
+)
+
+// notifierGroup is the fx value group every notification backend registers into
+const notifierGroup = `group:"notifiers,flatten"`
+
+// DispatcherParams collects all registered notifiers
+type DispatcherParams struct {
+	fx.In
+	Config    *config.Config
+	Logger    *zap.Logger
+	Notifiers []appnotify.Notifier `group:"notifiers"`
+}
+
+// NotifierFxOption provides notifier dependencies via fx
+func NotifierFxOption() fx.Option {
+	return fx.Options(
+		fx.Provide(NewNotifierHTTPClient),
+		fx.Provide(NewTemplates),
+		fx.Provide(
+			fx.Annotate(NewSlackNotifiers, fx.ResultTags(notifierGroup)),
+			fx.Annotate(NewEmailNotifiers, fx.ResultTags(notifierGroup)),
+			fx.Annotate(NewTeamsNotifiers, fx.ResultTags(notifierGroup)),
+			fx.Annotate(NewDiscordNotifiers, fx.ResultTags(notifierGroup)),
+			fx.Annotate(NewWebhookNotifiers, fx.ResultTags(notifierGroup)),
+			fx.Annotate(NewAcknowledgementNotifiers, fx.ResultTags(notifierGroup)),
+		),
+		fx.Provide(NewDispatcher),
+		fx.Provide(NewOutboxWorker),
+	)
+}
+
+// OutboxWorkerFxOption runs the outbox worker for the lifetime of the application.
+// Serverless deployments leave it out and drain the outbox with Worker.RunOnce instead.
+func OutboxWorkerFxOption() fx.Option {
+	return fx.Invoke(func(lc fx.Lifecycle, worker *outbox.Worker) {
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				worker.Start()
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				return worker.Stop(ctx)
+			},
+		})
+	})
+}
+
+// NewOutboxWorker creates the worker that delivers queued notifications
+func NewOutboxWorker(
+	cfg *config.Config,
+	outboxRepository data.OutboxRepository,
+	enquiryRepository data.EnquiryRepository,
+	dispatcher *appnotify.Dispatcher,
+	logger *zap.Logger,
+) *outbox.Worker {
+	return outbox.NewWorker(outbox.Config{
+		PollInterval:   cfg.Outbox.PollInterval.Duration,
+		BatchSize:      cfg.Outbox.BatchSize,
+		MaxAttempts:    cfg.Outbox.MaxAttempts,
+		InitialBackoff: cfg.Outbox.InitialBackoff.Duration,
+		MaxBackoff:     cfg.Outbox.MaxBackoff.Duration,
+		Lease:          cfg.Outbox.Lease.Duration,
+	}, outboxRepository, enquiryRepository, dispatcher, logger)
+}
+
+// NotifierHTTPClient is the HTTP client shared by webhook based notifiers
+type NotifierHTTPClient struct {
+	*http.Client
+}
+
+// NewNotifierHTTPClient creates the HTTP client used by webhook based notifiers
+func NewNotifierHTTPClient() *NotifierHTTPClient {
+	return &NotifierHTTPClient{
+		Client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// NewTemplates creates the message templates shared by the notifiers and the preview query
+func NewTemplates(cfg *config.Config, links *attachment.Links) (*appnotify.Templates, error) {
+	return appnotify.NewTemplates(cfg.Notify.TemplatesDir, cfg.Notify.EnquiryURL, links)
+}
+
+// NewDispatcher creates the dispatcher that delivers to the routed backends
+func NewDispatcher(params DispatcherParams) (*appnotify.Dispatcher, error) {
+	for _, notifier := range params.Notifiers {
+		params.Logger.Info("Notifier registered", zap.String("notifier", notifier.Name()))
+	}
+	return appnotify.NewDispatcher(params.Notifiers, NewRouter(params.Config), params.Logger)
+}
+
+// NewRouter creates the notification router from config, or nil when routing is not configured
+func NewRouter(cfg *config.Config) *appnotify.Router {
+	if len(cfg.Routing.Routes) == 0 && len(cfg.Routing.Fallback) == 0 {
+		return nil
+	}
+
+	routes := make([]appnotify.Route, 0, len(cfg.Routing.Routes))
+	for _, route := range cfg.Routing.Routes {
+		routes = append(routes, appnotify.Route{
+			Name:            route.Name,
+			Sources:         route.Sources,
+			SubjectKeywords: route.SubjectKeywords,
+			Countries:       route.Countries,
+			Destinations:    route.Destinations,
+			Final:           route.Final,
+		})
+	}
+	return appnotify.NewRouter(routes, cfg.Routing.Fallback)
+}
+
+// NewSlackNotifiers creates a notifier for every configured Slack webhook or bot.
+// Bots store the messages they post on the enquiry, so follow-ups can be threaded.
+func NewSlackNotifiers(
+	cfg *config.Config,
+	client *NotifierHTTPClient,
+	templates *appnotify.Templates,
+	enquiryRepository data.EnquiryRepository,
+	outboxRepository data.OutboxRepository,
+	logger *zap.Logger,
+) ([]appnotify.Notifier, error) {
+	notifiers := make([]appnotify.Notifier, 0, len(cfg.Notify.Slack))
+	for _, slack := range cfg.Notify.Slack {
+		if slack.BotToken == "" && slack.Channel != "" {
+			return nil, fmt.Errorf("slack notifier %s has a channel but no bot token, set %s", slack.Name, keys.SlackBotTokenEnvKeyPrefix+config.EnvKeySuffix(slack.Name))
+		}
+		if slack.BotToken == "" {
+			notifiers = append(notifiers, appnotify.NewSlackNotifier(slack.Name, slack.WebhookURL, client.Client, templates))
+			continue
+		}
+		if slack.Channel == "" {
+			return nil, fmt.Errorf("slack notifier %s has a bot token but no channel", slack.Name)
+		}
+		api := appnotify.NewSlackClient(slack.APIURL, slack.BotToken, client.Client)
+		notifiers = append(notifiers, appnotify.NewSlackAPINotifier(slack.Name, slack.Channel, api, templates, enquiryRepository, outboxRepository, logger))
+	}
+	return notifiers, nil
+}
+
+// NewEmailNotifiers creates a notifier for every configured mailbox
+func NewEmailNotifiers(cfg *config.Config, templates *appnotify.Templates) []appnotify.Notifier {
+	notifiers := make([]appnotify.Notifier, 0, len(cfg.Notify.Email))
+	for _, email := range cfg.Notify.Email {
+		sender := newSMTPSender(email.SMTP)
+		notifiers = append(notifiers, appnotify.NewEmailNotifier(email.Name, email.From, email.To, sender, templates))
+	}
+	return notifiers
+}
+
+// NewAcknowledgementNotifiers creates the notifier that emails customers, or none when no site has it enabled
+func NewAcknowledgementNotifiers(cfg *config.Config, templates *appnotify.Templates) ([]appnotify.Notifier, error) {
+	acknowledgement := cfg.Notify.Acknowledgement
+	sites := make(map[string]appnotify.AcknowledgementSite, len(acknowledgement.Sites))
+	for source, site := range acknowledgement.Sites {
+		source = strings.ToUpper(source)
+		if !model.WebsiteSource(source).IsValid() {
+			return nil, fmt.Errorf("acknowledgement configured for unknown source %q", source)
+		}
+		if !site.Enabled {
+			continue
+		}
+		if site.From == "" {
+			return nil, fmt.Errorf("acknowledgement for %s has no from address", source)
+		}
+		if site.LogoURL != "" {
+			if logo, err := url.Parse(site.LogoURL); err != nil || !logo.IsAbs() {
+				return nil, fmt.Errorf("acknowledgement logo of %s must be an absolute URL", source)
+			}
+		}
+
+		localized := make(map[string]appnotify.LocalizedBranding, len(site.Localized))
+		for locale, branding := range site.Localized {
+			if !i18n.IsSupported(locale) {
+				return nil, fmt.Errorf("acknowledgement of %s is localized for unsupported locale %q", source, locale)
+			}
+			localized[locale] = appnotify.LocalizedBranding{SiteName: branding.Name, Signature: branding.Signature}
+		}
+
+		name := site.Name
+		if name == "" {
+			name = source
+		}
+		sites[source] = appnotify.AcknowledgementSite{
+			From:      site.From,
+			ReplyTo:   site.ReplyTo,
+			SiteName:  name,
+			LogoURL:   site.LogoURL,
+			Signature: site.Signature,
+			Localized: localized,
+		}
+	}
+
+	if len(sites) == 0 {
+		return nil, nil
+	}
+	if acknowledgement.SMTP.Host == "" {
+		return nil, fmt.Errorf("acknowledgement emails are enabled but no SMTP host is configured")
+	}
+	return []appnotify.Notifier{
+		appnotify.NewAcknowledgementNotifier(sites, newSMTPSender(acknowledgement.SMTP), templates),
+	}, nil
+}
+
+// newSMTPSender creates a sender for the configured relay
+func newSMTPSender(smtp config.SMTPConfig) *mail.SMTPSender {
+	return mail.NewSMTPSender(mail.SMTPConfig{
+		Host:        smtp.Host,
+		Port:        smtp.Port,
+		Username:    smtp.Username,
+		Password:    smtp.Password,
+		ImplicitTLS: smtp.ImplicitTLS,
+	})
+}
+
+// NewTeamsNotifiers creates a notifier for every configured Teams webhook
+func NewTeamsNotifiers(cfg *config.Config, client *NotifierHTTPClient, templates *appnotify.Templates) []appnotify.Notifier {
+	notifiers := make([]appnotify.Notifier, 0, len(cfg.Notify.Teams))
+	for _, teams := range cfg.Notify.Teams {
+		notifiers = append(notifiers, appnotify.NewTeamsNotifier(teams.Name, teams.URL, client.Client, templates))
+	}
+	return notifiers
+}
+
+// NewDiscordNotifiers creates a notifier for every configured Discord webhook
+func NewDiscordNotifiers(cfg *config.Config, client *NotifierHTTPClient, templates *appnotify.Templates) []appnotify.Notifier {
+	notifiers := make([]appnotify.Notifier, 0, len(cfg.Notify.Discord))
+	for _, discord := range cfg.Notify.Discord {
+		notifiers = append(notifiers, appnotify.NewDiscordNotifier(discord.Name, discord.URL, client.Client, templates))
+	}
+	return notifiers
+}
+
+// NewWebhookNotifiers creates a notifier for every configured generic webhook
+func NewWebhookNotifiers(cfg *config.Config, client *NotifierHTTPClient, links *attachment.Links) []appnotify.Notifier {
+	notifiers := make([]appnotify.Notifier, 0, len(cfg.Notify.Webhook))
+	for _, webhook := range cfg.Notify.Webhook {
+		notifiers = append(notifiers, appnotify.NewWebhookNotifier(webhook.Name, webhook.URL, webhook.Secret, client.Client, links))
+	}
+	return notifiers
+}
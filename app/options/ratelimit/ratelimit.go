@@ -0,0 +1,60 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/redis/go-redis/v9"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/keys"
+	"sct-backend-service/app/options/config"
+	appratelimit "sct-backend-service/app/ratelimit"
+)
+
+// RateLimitFxOption provides the rate limiter used by @rateLimit via fx
+func RateLimitFxOption() fx.Option {
+	return fx.Options(
+		fx.Provide(NewLimiter),
+	)
+}
+
+// NewLimiter creates the limiter on the configured bucket store
+func NewLimiter(lc fx.Lifecycle, cfg *config.Config, logger *zap.Logger) (*appratelimit.Limiter, error) {
+	if !cfg.RateLimit.Enabled {
+		logger.Warn("Rate limiting is disabled")
+		return appratelimit.NewLimiter(nil, logger), nil
+	}
+
+	switch cfg.RateLimit.Store {
+	case "", keys.RateLimitStoreMemory:
+		logger.Warn("Using in-memory rate limit store, limits are not shared between instances")
+		return appratelimit.NewLimiter(appratelimit.NewMemoryStore(), logger), nil
+	case keys.RateLimitStoreRedis:
+		if cfg.RateLimit.RedisURL == "" {
+			return nil, fmt.Errorf("redis rate limit store needs %s", keys.RedisURLEnvKey)
+		}
+		options, err := redis.ParseURL(cfg.RateLimit.RedisURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid redis URL: %w", err)
+		}
+
+		client := redis.NewClient(options)
+		lc.Append(fx.Hook{
+			OnStart: func(ctx context.Context) error {
+				// Only logged, the limiter lets calls through while Redis is down
+				if err := client.Ping(ctx).Err(); err != nil {
+					logger.Warn("Failed to connect to redis rate limit store", zap.Error(err))
+				}
+				return nil
+			},
+			OnStop: func(ctx context.Context) error {
+				return client.Close()
+			},
+		})
+		return appratelimit.NewLimiter(appratelimit.NewRedisStore(client), logger), nil
+	default:
+		return nil, fmt.Errorf("unknown rate limit store %q", cfg.RateLimit.Store)
+	}
+}
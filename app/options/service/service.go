@@ -1,17 +1,34 @@
 package service
 
 import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/blob"
+	"sct-backend-service/app/captcha"
 	"sct-backend-service/app/controllers"
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/idempotency"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/app/notify"
+	"sct-backend-service/app/options/config"
+	"sct-backend-service/app/outbox"
+	"sct-backend-service/app/phone"
 	"sct-backend-service/app/query"
 	"sct-backend-service/app/workflow"
+	"sct-backend-service/graph/model"
 )
 
 // ControllerFxOption provides controller dependencies via fx
 func ControllerFxOption() fx.Option {
 	return fx.Options(
+		fx.Provide(NewPhoneParser),
 		fx.Provide(NewGraphQLController),
 	)
 }
@@ -19,19 +36,95 @@ func ControllerFxOption() fx.Option {
 // WorkflowFxOption provides workflow dependencies via fx
 func WorkflowFxOption() fx.Option {
 	return fx.Options(
+		fx.Provide(NewIdempotencyStore),
+		fx.Provide(NewCaptchaRegistry),
 		fx.Provide(workflow.CreateWorkflowGraphQLService),
 	)
 }
 
 // NewGraphQLController creates a new GraphQL controller with dependencies
 func NewGraphQLController(
+	cfg *config.Config,
 	logger *zap.Logger,
 	queryBuilder *query.QueryBuilder,
+	enquiryRepository data.EnquiryRepository,
+	dispatcher *notify.Dispatcher,
+	outboxWorker *outbox.Worker,
+	phoneParser *phone.Parser,
+	templates *notify.Templates,
+	blobs blob.Store,
+	attachmentLinks *attachment.Links,
 ) controllers.GraphQLController {
 	deps := controllers.ControllerDeps{
-		Logger:       logger,
-		QueryBuilder: queryBuilder,
+		Logger:            logger,
+		QueryBuilder:      queryBuilder,
+		EnquiryRepository: enquiryRepository,
+		Dispatcher:        dispatcher,
+		OutboxWorker:      outboxWorker,
+		PhoneParser:       phoneParser,
+		Templates:         templates,
+		Blobs:             blobs,
+		AttachmentLinks:   attachmentLinks,
+
+		SubmissionConcurrency: cfg.Submission.MaxConcurrency,
+		DuplicateWindow:       cfg.Submission.DuplicateWindow.Duration,
 	}
 
 	return controllers.CreateGraphQLController(deps)
 }
+
+// NewPhoneParser creates the phone number parser with the default region of every source
+func NewPhoneParser(cfg *config.Config) (*phone.Parser, error) {
+	for source := range cfg.Phone.DefaultRegions {
+		if !model.WebsiteSource(strings.ToUpper(source)).IsValid() {
+			return nil, fmt.Errorf("phone region configured for unknown source %q", source)
+		}
+	}
+	return phone.NewParser(cfg.Phone.DefaultRegions)
+}
+
+// NewIdempotencyStore creates the store that replays requests made with an idempotency key
+func NewIdempotencyStore(
+	cfg *config.Config,
+	logger *zap.Logger,
+	idempotencyRepository data.IdempotencyRepository,
+) *idempotency.Store {
+	return idempotency.NewStore(idempotency.Config{
+		Window:      cfg.Idempotency.Window.Duration,
+		LockTimeout: cfg.Idempotency.LockTimeout.Duration,
+		WaitTimeout: cfg.Idempotency.WaitTimeout.Duration,
+	}, idempotencyRepository, logger)
+}
+
+// captchaTimeout bounds a single captcha verification request
+const captchaTimeout = 10 * time.Second
+
+// NewCaptchaRegistry creates the captcha verifiers of every configured source
+func NewCaptchaRegistry(cfg *config.Config, logger *zap.Logger) (*captcha.Registry, error) {
+	client := &http.Client{Timeout: captchaTimeout}
+	verifiers := make(map[string]captcha.Verifier, len(cfg.Captcha))
+	for source, captchaConfig := range cfg.Captcha {
+		source = strings.ToUpper(source)
+		if !model.WebsiteSource(source).IsValid() {
+			return nil, fmt.Errorf("captcha configured for unknown source %q", source)
+		}
+		if captchaConfig.Secret == "" {
+			return nil, fmt.Errorf("captcha secret for %s is not set", source)
+		}
+
+		switch captchaConfig.Provider {
+		case keys.CaptchaProviderTurnstile:
+			verifiers[source] = captcha.NewTurnstileVerifier(captchaConfig.Secret, captchaConfig.VerifyURL, client)
+		case keys.CaptchaProviderHCaptcha:
+			verifiers[source] = captcha.NewHCaptchaVerifier(captchaConfig.Secret, captchaConfig.VerifyURL, client)
+		case keys.CaptchaProviderReCaptcha:
+			verifiers[source] = captcha.NewReCaptchaV3Verifier(captchaConfig.Secret, captchaConfig.VerifyURL, captchaConfig.MinScore, captchaConfig.Action, client)
+		case keys.CaptchaProviderFake:
+			logger.Warn("Using fake captcha verifier", zap.String("source", source))
+			verifiers[source] = captcha.NewFakeVerifier(captchaConfig.Secret)
+		default:
+			return nil, fmt.Errorf("unknown captcha provider %q for %s", captchaConfig.Provider, source)
+		}
+	}
+	return captcha.NewRegistry(verifiers), nil
+}
@@ -0,0 +1,68 @@
+package slack
+
+import (
+	"net/http"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	appnotify "sct-backend-service/app/notify"
+	"sct-backend-service/app/options/config"
+	"sct-backend-service/app/options/notify"
+	appslack "sct-backend-service/app/slack"
+	"sct-backend-service/app/workflow"
+)
+
+// Handlers holds the HTTP handlers of the Slack app.
+// They are nil when no signing secret is configured, the endpoints are then not served.
+type Handlers struct {
+	Interactions http.Handler
+	Commands     http.Handler
+
+	interactions *appslack.InteractionHandler
+}
+
+// Wait blocks until the acknowledged Slack interactions have been applied
+func (h *Handlers) Wait() {
+	if h != nil && h.interactions != nil {
+		h.interactions.Wait()
+	}
+}
+
+// SlackFxOption provides the Slack app endpoints via fx
+func SlackFxOption() fx.Option {
+	return fx.Provide(NewHandlers)
+}
+
+// NewHandlers creates the Slack endpoints, every request is verified with the signing secret first
+func NewHandlers(
+	cfg *config.Config,
+	workflowService workflow.WorkflowGraphQLService,
+	client *notify.NotifierHTTPClient,
+	templates *appnotify.Templates,
+	logger *zap.Logger,
+) *Handlers {
+	if cfg.Slack.SigningSecret == "" {
+		logger.Info("Slack endpoints are disabled, no signing secret is configured")
+		return &Handlers{}
+	}
+
+	verifier := appslack.NewVerifier(cfg.Slack.SigningSecret)
+	interactions := appslack.NewInteractionHandler(workflowService, NewUserDirectory(cfg, client), client.Client, logger)
+	commands := appslack.NewCommandHandler(workflowService, templates, logger)
+	return &Handlers{
+		Interactions: verifier.Middleware(interactions),
+		Commands:     verifier.Middleware(commands),
+		interactions: interactions,
+	}
+}
+
+// NewUserDirectory looks Slack users up with the token of the first Slack bot, or returns nil when there is none
+func NewUserDirectory(cfg *config.Config, client *notify.NotifierHTTPClient) appslack.UserDirectory {
+	for _, slack := range cfg.Notify.Slack {
+		if slack.BotToken != "" {
+			return appnotify.NewSlackClient(slack.APIURL, slack.BotToken, client.Client)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+package spam
+
+import (
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/options/config"
+	appspam "sct-backend-service/app/spam"
+)
+
+// checkGroup is the fx value group every spam check registers into
+const checkGroup = `group:"spam_checks"`
+
+// ScorerParams collects all registered spam checks
+type ScorerParams struct {
+	fx.In
+	Config *config.Config
+	Logger *zap.Logger
+	Checks []appspam.Check `group:"spam_checks"`
+}
+
+// SpamFxOption provides the spam filter via fx
+func SpamFxOption() fx.Option {
+	return fx.Options(
+		fx.Provide(
+			fx.Annotate(NewHoneypotCheck, fx.ResultTags(checkGroup)),
+			fx.Annotate(NewLinkDensityCheck, fx.ResultTags(checkGroup)),
+			fx.Annotate(NewKeywordCheck, fx.ResultTags(checkGroup)),
+			fx.Annotate(NewEmailDomainCheck, fx.ResultTags(checkGroup)),
+			fx.Annotate(NewGibberishCheck, fx.ResultTags(checkGroup)),
+			fx.Annotate(NewIPReputationCheck, fx.ResultTags(checkGroup)),
+		),
+		fx.Provide(NewScorer),
+	)
+}
+
+// NewScorer creates the scorer from the registered checks.
+// A threshold of zero turns the filter off.
+func NewScorer(params ScorerParams) *appspam.Scorer {
+	if params.Config.Spam.Threshold <= 0 {
+		params.Logger.Warn("Spam filter is disabled")
+		return appspam.NewScorer(nil, 0)
+	}
+	return appspam.NewScorer(params.Checks, params.Config.Spam.Threshold)
+}
+
+// NewHoneypotCheck creates the honeypot field check
+func NewHoneypotCheck() appspam.Check {
+	return appspam.HoneypotCheck{}
+}
+
+// NewLinkDensityCheck creates the link count check
+func NewLinkDensityCheck(cfg *config.Config) appspam.Check {
+	return appspam.NewLinkDensityCheck(cfg.Spam.MaxLinks)
+}
+
+// NewKeywordCheck creates the spam keyword check
+func NewKeywordCheck(cfg *config.Config) appspam.Check {
+	return appspam.NewKeywordCheck(cfg.Spam.Keywords)
+}
+
+// NewEmailDomainCheck creates the blocked email domain check
+func NewEmailDomainCheck(cfg *config.Config) appspam.Check {
+	return appspam.NewEmailDomainCheck(cfg.Spam.BlockedEmailDomains)
+}
+
+// NewGibberishCheck creates the gibberish check
+func NewGibberishCheck() appspam.Check {
+	return appspam.GibberishCheck{}
+}
+
+// NewIPReputationCheck creates the blocked IP check, failing startup on malformed entries
+func NewIPReputationCheck(cfg *config.Config) (appspam.Check, error) {
+	return appspam.NewIPReputationCheck(cfg.Spam.BlockedIPs)
+}
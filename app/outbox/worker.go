@@ -0,0 +1,271 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/notify"
+)
+
+// Config holds the worker settings, see config.OutboxConfig
+type Config struct {
+	PollInterval   time.Duration
+	BatchSize      int
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	Lease          time.Duration
+}
+
+// Worker delivers outbox messages in the background.
+// Failed deliveries are retried with capped exponential backoff and jitter
+// until MaxAttempts is reached, after which the message is dead-lettered.
+type Worker struct {
+	config            Config
+	outboxRepository  data.OutboxRepository
+	enquiryRepository data.EnquiryRepository
+	dispatcher        *notify.Dispatcher
+	logger            *zap.Logger
+
+	cancel context.CancelFunc
+	done   chan struct{}
+	wake   chan struct{}
+	mu     sync.Mutex
+}
+
+// NewWorker creates an outbox worker
+func NewWorker(
+	config Config,
+	outboxRepository data.OutboxRepository,
+	enquiryRepository data.EnquiryRepository,
+	dispatcher *notify.Dispatcher,
+	logger *zap.Logger,
+) *Worker {
+	return &Worker{
+		config:            config,
+		outboxRepository:  outboxRepository,
+		enquiryRepository: enquiryRepository,
+		dispatcher:        dispatcher,
+		logger:            logger,
+		wake:              make(chan struct{}, 1),
+	}
+}
+
+// Start runs the polling loop in a goroutine until Stop is called
+func (w *Worker) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+		w.run(ctx)
+	}()
+}
+
+// Stop stops the polling loop and waits for the current batch to finish or ctx to expire
+func (w *Worker) Stop(ctx context.Context) error {
+	w.mu.Lock()
+	cancel, done := w.cancel, w.done
+	w.cancel = nil
+	w.mu.Unlock()
+
+	if cancel == nil {
+		return nil
+	}
+
+	cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Wake asks the worker to poll now instead of waiting for the next interval
+func (w *Worker) Wake() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *Worker) run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		// Keep going while full batches come back, there may be more due messages
+		for {
+			processed, err := w.RunOnce(ctx)
+			if err != nil && ctx.Err() == nil {
+				w.logger.Error("Outbox poll failed", zap.Error(err))
+			}
+			if err != nil || processed < w.config.BatchSize {
+				break
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-w.wake:
+		}
+	}
+}
+
+// RunOnce claims one batch of due messages and attempts to deliver them.
+// It returns the number of messages processed.
+func (w *Worker) RunOnce(ctx context.Context) (int, error) {
+	messages, err := w.outboxRepository.ClaimDue(ctx, time.Now().UTC(), w.config.BatchSize, w.config.Lease)
+	if err != nil {
+		return 0, fmt.Errorf("error claiming outbox messages: %w", err)
+	}
+
+	for _, message := range messages {
+		w.deliver(ctx, message)
+	}
+	return len(messages), nil
+}
+
+// deliver attempts a single message and records the outcome
+func (w *Worker) deliver(ctx context.Context, message *entities.OutboxMessage) {
+	logger := w.logger.With(
+		zap.String("outbox_id", message.ID),
+		zap.String("enquiry_id", message.EnquiryID),
+		zap.String("destination", message.Destination),
+		zap.Int("attempt", message.Attempts+1),
+	)
+
+	err := w.send(ctx, message)
+	now := time.Now().UTC()
+
+	// Record the outcome even if the worker is stopping, the lease would otherwise hide the message
+	recordCtx := context.WithoutCancel(ctx)
+
+	if err == nil {
+		if err := w.outboxRepository.MarkDelivered(recordCtx, message.ID, now); err != nil {
+			logger.Error("Error marking outbox message delivered", zap.Error(err))
+		}
+		logger.Info("Notification delivered")
+		return
+	}
+
+	if message.Attempts+1 >= w.config.MaxAttempts || isPermanent(err) {
+		logger.Error("Notification dead-lettered", zap.Error(err))
+		if err := w.outboxRepository.MarkDead(recordCtx, message.ID, err.Error(), now); err != nil {
+			logger.Error("Error dead-lettering outbox message", zap.Error(err))
+		}
+		return
+	}
+
+	nextAttemptAt := now.Add(w.backoff(message.Attempts + 1))
+	logger.Warn("Notification failed, retrying",
+		zap.Time("next_attempt_at", nextAttemptAt),
+		zap.Error(err),
+	)
+	if err := w.outboxRepository.Reschedule(recordCtx, message.ID, err.Error(), nextAttemptAt, now); err != nil {
+		logger.Error("Error rescheduling outbox message", zap.Error(err))
+	}
+}
+
+func (w *Worker) send(ctx context.Context, message *entities.OutboxMessage) error {
+	enquiry, err := w.enquiryRepository.GetByID(ctx, message.EnquiryID)
+	if err != nil {
+		return fmt.Errorf("error loading enquiry: %w", err)
+	}
+
+	switch message.Kind {
+	case entities.OutboxKindEnquiryCreated, entities.OutboxKindEnquiryAcknowledged:
+		return w.dispatcher.NotifyDestination(ctx, message.Destination, notify.Notification{
+			Kind:    message.Kind,
+			Enquiry: enquiry,
+		})
+	case entities.OutboxKindEnquiryResubmitted:
+		var resubmission entities.EnquiryResubmission
+		if err := json.Unmarshal(message.Payload, &resubmission); err != nil {
+			return fmt.Errorf("%w: invalid resubmission payload: %v", errPermanent, err)
+		}
+		return w.dispatcher.NotifyDestination(ctx, message.Destination, notify.Notification{
+			Kind:         message.Kind,
+			Enquiry:      enquiry,
+			Resubmission: &resubmission,
+		})
+	case entities.OutboxKindEnquiryStatusChanged:
+		var change entities.EnquiryStatusChange
+		if err := json.Unmarshal(message.Payload, &change); err != nil {
+			return fmt.Errorf("%w: invalid status change payload: %v", errPermanent, err)
+		}
+		return w.dispatcher.NotifyDestination(ctx, message.Destination, notify.Notification{
+			Kind:         message.Kind,
+			Enquiry:      enquiry,
+			StatusChange: &change,
+		})
+	case entities.OutboxKindEnquiryNoteAdded:
+		var note entities.EnquiryNote
+		if err := json.Unmarshal(message.Payload, &note); err != nil {
+			return fmt.Errorf("%w: invalid note payload: %v", errPermanent, err)
+		}
+		return w.dispatcher.NotifyDestination(ctx, message.Destination, notify.Notification{
+			Kind:    message.Kind,
+			Enquiry: enquiry,
+			Note:    &note,
+		})
+	case entities.OutboxKindEnquiryAssigned:
+		var assignment entities.EnquiryAssignment
+		if err := json.Unmarshal(message.Payload, &assignment); err != nil {
+			return fmt.Errorf("%w: invalid assignment payload: %v", errPermanent, err)
+		}
+		return w.dispatcher.NotifyDestination(ctx, message.Destination, notify.Notification{
+			Kind:       message.Kind,
+			Enquiry:    enquiry,
+			Assignment: &assignment,
+		})
+	default:
+		return fmt.Errorf("%w: unknown outbox message kind %s", errPermanent, message.Kind)
+	}
+}
+
+// backoff returns the delay before the next attempt after the given number of failures.
+// The delay doubles per failure up to MaxBackoff, then a random jitter in [delay/2, delay) spreads retries out.
+func (w *Worker) backoff(failures int) time.Duration {
+	delay := w.config.InitialBackoff
+	for i := 1; i < failures && delay < w.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	delay = min(delay, w.config.MaxBackoff)
+
+	half := delay / 2
+	if half <= 0 {
+		return delay
+	}
+	return half + rand.N(half)
+}
+
+// errPermanent marks failures that retrying cannot fix
+var errPermanent = errors.New("permanent failure")
+
+func isPermanent(err error) bool {
+	return errors.Is(err, errPermanent) ||
+		errors.Is(err, data.ErrEnquiryNotFound) ||
+		errors.Is(err, notify.ErrUnknownNotifier) ||
+		errors.Is(err, notify.ErrFollowUpNotSupported)
+}
@@ -0,0 +1,175 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/entities"
+	"sct-backend-service/app/notify"
+)
+
+// recordingOutbox hands out one message and records what the worker did with it
+type recordingOutbox struct {
+	message       *entities.OutboxMessage
+	outcome       string
+	lastError     string
+	nextAttemptAt time.Time
+}
+
+func (r *recordingOutbox) ClaimDue(ctx context.Context, now time.Time, limit int, lease time.Duration) ([]*entities.OutboxMessage, error) {
+	if r.message == nil {
+		return nil, nil
+	}
+	message := r.message
+	r.message = nil
+	return []*entities.OutboxMessage{message}, nil
+}
+
+func (r *recordingOutbox) MarkDelivered(ctx context.Context, id string, at time.Time) error {
+	r.outcome = entities.OutboxStatusDelivered
+	return nil
+}
+
+func (r *recordingOutbox) Reschedule(ctx context.Context, id string, lastError string, nextAttemptAt time.Time, at time.Time) error {
+	r.outcome, r.lastError, r.nextAttemptAt = entities.OutboxStatusPending, lastError, nextAttemptAt
+	return nil
+}
+
+func (r *recordingOutbox) MarkDead(ctx context.Context, id string, lastError string, at time.Time) error {
+	r.outcome, r.lastError = entities.OutboxStatusDead, lastError
+	return nil
+}
+
+func (r *recordingOutbox) HasPending(ctx context.Context, enquiryID, kind, destination string) (bool, error) {
+	return false, nil
+}
+
+// failingNotifier fails every delivery with err, or succeeds when it is nil
+type failingNotifier struct {
+	err error
+}
+
+func (n failingNotifier) Name() string {
+	return "crm"
+}
+
+func (n failingNotifier) Notify(ctx context.Context, notification notify.Notification) error {
+	return n.err
+}
+
+var testConfig = Config{
+	BatchSize:      10,
+	MaxAttempts:    3,
+	InitialBackoff: time.Second,
+	MaxBackoff:     time.Minute,
+	Lease:          time.Minute,
+}
+
+func TestWorkerDeliver(t *testing.T) {
+	tests := []struct {
+		name        string
+		notifyErr   error
+		kind        string
+		destination string
+		enquiryID   string
+		attempts    int
+		wantOutcome string
+	}{
+		{name: "delivered", wantOutcome: entities.OutboxStatusDelivered},
+		{name: "transient failure is retried", notifyErr: errors.New("connection reset"), wantOutcome: entities.OutboxStatusPending},
+		{name: "failure before the last attempt is retried", notifyErr: errors.New("connection reset"), attempts: 1, wantOutcome: entities.OutboxStatusPending},
+		{name: "last attempt is dead-lettered", notifyErr: errors.New("connection reset"), attempts: 2, wantOutcome: entities.OutboxStatusDead},
+		{name: "pending slack message is retried", notifyErr: fmt.Errorf("follow-up: %w", notify.ErrSlackMessagePending), wantOutcome: entities.OutboxStatusPending},
+		{name: "unknown kind is dead-lettered at once", kind: "ENQUIRY_ARCHIVED", wantOutcome: entities.OutboxStatusDead},
+		{name: "invalid payload is dead-lettered at once", kind: entities.OutboxKindEnquiryNoteAdded, wantOutcome: entities.OutboxStatusDead},
+		{name: "unknown destination is dead-lettered at once", destination: "fax", wantOutcome: entities.OutboxStatusDead},
+		{name: "missing enquiry is dead-lettered at once", enquiryID: "deleted", wantOutcome: entities.OutboxStatusDead},
+		{name: "unsupported follow-up is dead-lettered at once", notifyErr: notify.ErrFollowUpNotSupported, wantOutcome: entities.OutboxStatusDead},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			enquiries := data.NewMemoryEnquiryRepository(data.NewMemoryOutboxRepository())
+			enquiry := &entities.Enquiry{ID: "enquiry-1", Status: entities.EnquiryStatusNew, CreatedAt: time.Now().UTC()}
+			if err := enquiries.Create(context.Background(), enquiry); err != nil {
+				t.Fatalf("Create: %v", err)
+			}
+			dispatcher, err := notify.NewDispatcher([]notify.Notifier{failingNotifier{tt.notifyErr}}, nil, zap.NewNop())
+			if err != nil {
+				t.Fatalf("NewDispatcher: %v", err)
+			}
+
+			message := &entities.OutboxMessage{
+				ID:          "message-1",
+				EnquiryID:   enquiry.ID,
+				Kind:        entities.OutboxKindEnquiryCreated,
+				Destination: "crm",
+				Payload:     []byte("{"),
+				Attempts:    tt.attempts,
+			}
+			if tt.kind != "" {
+				message.Kind = tt.kind
+			}
+			if tt.destination != "" {
+				message.Destination = tt.destination
+			}
+			if tt.enquiryID != "" {
+				message.EnquiryID = tt.enquiryID
+			}
+			outbox := &recordingOutbox{message: message}
+			worker := NewWorker(testConfig, outbox, enquiries, dispatcher, zap.NewNop())
+
+			before := time.Now().UTC()
+			processed, err := worker.RunOnce(context.Background())
+			if err != nil || processed != 1 {
+				t.Fatalf("RunOnce = %d, %v, want one message processed", processed, err)
+			}
+			if outbox.outcome != tt.wantOutcome {
+				t.Fatalf("outcome = %q (%s), want %q", outbox.outcome, outbox.lastError, tt.wantOutcome)
+			}
+			if tt.wantOutcome != entities.OutboxStatusDelivered && outbox.lastError == "" {
+				t.Error("the failure was not recorded")
+			}
+			if tt.wantOutcome == entities.OutboxStatusPending {
+				delay := outbox.nextAttemptAt.Sub(before)
+				if delay <= 0 || delay > time.Duration(tt.attempts+1)*2*time.Second {
+					t.Errorf("next attempt in %s, want a backoff", delay)
+				}
+			}
+		})
+	}
+}
+
+func TestWorkerBackoff(t *testing.T) {
+	worker := NewWorker(testConfig, nil, nil, nil, zap.NewNop())
+	tests := []struct {
+		failures int
+		min, max time.Duration
+	}{
+		{1, 500 * time.Millisecond, time.Second},
+		{2, time.Second, 2 * time.Second},
+		{3, 2 * time.Second, 4 * time.Second},
+		{6, 16 * time.Second, 32 * time.Second},
+		{7, 30 * time.Second, time.Minute},
+		{50, 30 * time.Second, time.Minute},
+	}
+	for _, tt := range tests {
+		for range 100 {
+			if delay := worker.backoff(tt.failures); delay < tt.min || delay >= tt.max {
+				t.Fatalf("backoff(%d) = %s, want within [%s, %s)", tt.failures, delay, tt.min, tt.max)
+			}
+		}
+	}
+
+	// Delays too short to halve are used as they are
+	tiny := NewWorker(Config{InitialBackoff: time.Nanosecond, MaxBackoff: time.Nanosecond}, nil, nil, nil, zap.NewNop())
+	if delay := tiny.backoff(3); delay != time.Nanosecond {
+		t.Errorf("backoff = %s, want 1ns", delay)
+	}
+}
@@ -0,0 +1,110 @@
+package phone
+
+// numberType describes the national significant numbers of one line type in a region
+type numberType struct {
+	lineType LineType
+	prefixes []string
+	lengths  []int
+}
+
+// region holds the numbering plan of a country. Regions without types accept any
+// national significant number of a plausible length, with an unknown line type.
+type region struct {
+	callingCode string
+	// trunkPrefix is dialled before national numbers within the country, e.g. the 0 of 050 123 4567
+	trunkPrefix string
+	// internationalPrefix is dialled before the calling code of another country
+	internationalPrefix string
+	// types are tried in order, the first match decides the line type
+	types []numberType
+}
+
+// regions holds the numbering plans we know in detail, mostly the markets our sites serve.
+// Other countries are recognised by their calling code only, see callingCodes.
+var regions = map[string]region{
+	"AE": {callingCode: "971", trunkPrefix: "0", internationalPrefix: "00", types: []numberType{
+		{LineTypeMobile, []string{"50", "52", "54", "55", "56", "58"}, []int{9}},
+		{LineTypeTollFree, []string{"800"}, []int{5, 6, 7, 8, 9, 10, 11, 12}},
+		{LineTypeFixedLine, []string{"2", "3", "4", "6", "7", "9"}, []int{8}},
+	}},
+	"SA": {callingCode: "966", trunkPrefix: "0", internationalPrefix: "00", types: []numberType{
+		{LineTypeMobile, []string{"5"}, []int{9}},
+		{LineTypeTollFree, []string{"800"}, []int{10}},
+		{LineTypeFixedLine, []string{"11", "12", "13", "14", "16", "17"}, []int{9}},
+	}},
+	"QA": {callingCode: "974", internationalPrefix: "00", types: []numberType{
+		{LineTypeMobile, []string{"3", "5", "6", "7"}, []int{8}},
+		{LineTypeTollFree, []string{"800"}, []int{7}},
+		{LineTypeFixedLine, []string{"4"}, []int{8}},
+	}},
+	"OM": {callingCode: "968", internationalPrefix: "00", types: []numberType{
+		{LineTypeMobile, []string{"7", "9"}, []int{8}},
+		{LineTypeTollFree, []string{"800"}, []int{7, 8}},
+		{LineTypeFixedLine, []string{"2"}, []int{8}},
+	}},
+	"KW": {callingCode: "965", internationalPrefix: "00", types: []numberType{
+		{LineTypeMobile, []string{"4", "5", "6", "9"}, []int{8}},
+		{LineTypeFixedLine, []string{"2"}, []int{8}},
+	}},
+	"BH": {callingCode: "973", internationalPrefix: "00", types: []numberType{
+		{LineTypeMobile, []string{"3", "66"}, []int{8}},
+		{LineTypeTollFree, []string{"80"}, []int{8}},
+		{LineTypeFixedLine, []string{"1", "7"}, []int{8}},
+	}},
+	"IN": {callingCode: "91", trunkPrefix: "0", internationalPrefix: "00", types: []numberType{
+		{LineTypeMobile, []string{"6", "7", "8", "9"}, []int{10}},
+		{LineTypeTollFree, []string{"1800"}, []int{10, 11}},
+		{LineTypeFixedLine, []string{"1", "2", "3", "4", "5"}, []int{10}},
+	}},
+	"PK": {callingCode: "92", trunkPrefix: "0", internationalPrefix: "00", types: []numberType{
+		{LineTypeMobile, []string{"3"}, []int{10}},
+		{LineTypeTollFree, []string{"800"}, []int{8}},
+		{LineTypeFixedLine, []string{"2", "4", "5", "6", "7", "8", "9"}, []int{9, 10}},
+	}},
+	"EG": {callingCode: "20", trunkPrefix: "0", internationalPrefix: "00", types: []numberType{
+		{LineTypeMobile, []string{"10", "11", "12", "15"}, []int{10}},
+		{LineTypeTollFree, []string{"800"}, []int{10}},
+		{LineTypeFixedLine, []string{"2", "3", "4", "5", "6", "8", "9"}, []int{8, 9}},
+	}},
+	"GB": {callingCode: "44", trunkPrefix: "0", internationalPrefix: "00", types: []numberType{
+		{LineTypeMobile, []string{"7"}, []int{10}},
+		{LineTypeTollFree, []string{"800", "808"}, []int{9, 10}},
+		{LineTypeFixedLine, []string{"1", "2", "3"}, []int{9, 10}},
+	}},
+	"US": {callingCode: "1", trunkPrefix: "1", internationalPrefix: "011", types: []numberType{
+		{LineTypeTollFree, []string{"800", "833", "844", "855", "866", "877", "888"}, []int{10}},
+		// Mobile and fixed numbers share the same ranges in the North American Numbering Plan
+		{LineTypeFixedLineOrMobile, []string{"2", "3", "4", "5", "6", "7", "8", "9"}, []int{10}},
+	}},
+}
+
+// callingCodes maps every country calling code to its main region.
+// Calling codes are prefix free, so a number has at most one matching code.
+var callingCodes = map[string]string{
+	"1": "US", "7": "RU",
+	"20": "EG", "27": "ZA", "30": "GR", "31": "NL", "32": "BE", "33": "FR", "34": "ES", "36": "HU", "39": "IT",
+	"40": "RO", "41": "CH", "43": "AT", "44": "GB", "45": "DK", "46": "SE", "47": "NO", "48": "PL", "49": "DE",
+	"51": "PE", "52": "MX", "53": "CU", "54": "AR", "55": "BR", "56": "CL", "57": "CO", "58": "VE",
+	"60": "MY", "61": "AU", "62": "ID", "63": "PH", "64": "NZ", "65": "SG", "66": "TH",
+	"81": "JP", "82": "KR", "84": "VN", "86": "CN",
+	"90": "TR", "91": "IN", "92": "PK", "93": "AF", "94": "LK", "95": "MM", "98": "IR",
+	"211": "SS", "212": "MA", "213": "DZ", "216": "TN", "218": "LY", "220": "GM", "221": "SN", "222": "MR",
+	"223": "ML", "224": "GN", "225": "CI", "226": "BF", "227": "NE", "228": "TG", "229": "BJ", "230": "MU",
+	"231": "LR", "232": "SL", "233": "GH", "234": "NG", "235": "TD", "236": "CF", "237": "CM", "238": "CV",
+	"239": "ST", "240": "GQ", "241": "GA", "242": "CG", "243": "CD", "244": "AO", "245": "GW", "248": "SC",
+	"249": "SD", "250": "RW", "251": "ET", "252": "SO", "253": "DJ", "254": "KE", "255": "TZ", "256": "UG",
+	"257": "BI", "258": "MZ", "260": "ZM", "261": "MG", "262": "RE", "263": "ZW", "264": "NA", "265": "MW",
+	"266": "LS", "267": "BW", "268": "SZ", "269": "KM", "290": "SH", "291": "ER", "297": "AW", "298": "FO",
+	"299": "GL", "350": "GI", "351": "PT", "352": "LU", "353": "IE", "354": "IS", "355": "AL", "356": "MT",
+	"357": "CY", "358": "FI", "359": "BG", "370": "LT", "371": "LV", "372": "EE", "373": "MD", "374": "AM",
+	"375": "BY", "376": "AD", "377": "MC", "378": "SM", "380": "UA", "381": "RS", "382": "ME", "383": "XK",
+	"385": "HR", "386": "SI", "387": "BA", "389": "MK", "420": "CZ", "421": "SK", "423": "LI",
+	"500": "FK", "501": "BZ", "502": "GT", "503": "SV", "504": "HN", "505": "NI", "506": "CR", "507": "PA",
+	"509": "HT", "591": "BO", "592": "GY", "593": "EC", "595": "PY", "597": "SR", "598": "UY",
+	"670": "TL", "673": "BN", "674": "NR", "675": "PG", "676": "TO", "677": "SB", "678": "VU", "679": "FJ",
+	"680": "PW", "685": "WS", "686": "KI", "687": "NC", "689": "PF", "691": "FM", "692": "MH",
+	"850": "KP", "852": "HK", "853": "MO", "855": "KH", "856": "LA", "880": "BD", "886": "TW",
+	"960": "MV", "961": "LB", "962": "JO", "963": "SY", "964": "IQ", "965": "KW", "966": "SA", "967": "YE",
+	"968": "OM", "970": "PS", "971": "AE", "972": "IL", "973": "BH", "974": "QA", "975": "BT", "976": "MN",
+	"977": "NP", "992": "TJ", "993": "TM", "994": "AZ", "995": "GE", "996": "KG", "998": "UZ",
+}
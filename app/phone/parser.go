@@ -0,0 +1,30 @@
+package phone
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Parser parses the phone numbers of a website with the region its visitors most likely dial from
+type Parser struct {
+	defaultRegions map[string]string
+}
+
+// NewParser creates a parser from a map of WebsiteSource to ISO 3166-1 alpha-2 region code
+func NewParser(defaultRegions map[string]string) (*Parser, error) {
+	regions := make(map[string]string, len(defaultRegions))
+	for source, region := range defaultRegions {
+		region = strings.ToUpper(strings.TrimSpace(region))
+		if !KnownRegion(region) {
+			return nil, fmt.Errorf("%w %q for %s", ErrUnknownRegion, region, source)
+		}
+		regions[strings.ToUpper(source)] = region
+	}
+	return &Parser{defaultRegions: regions}, nil
+}
+
+// Parse parses a number submitted through source. National numbers of sources without
+// a default region are rejected with ErrUnknownRegion.
+func (p *Parser) Parse(source, raw string) (Number, error) {
+	return Parse(raw, p.defaultRegions[strings.ToUpper(source)])
+}
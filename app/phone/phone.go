@@ -0,0 +1,211 @@
+package phone
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// LineType classifies a phone number
+type LineType string
+
+// Line types, North American numbers cannot tell fixed and mobile lines apart
+const (
+	LineTypeMobile            LineType = "MOBILE"
+	LineTypeFixedLine         LineType = "FIXED_LINE"
+	LineTypeFixedLineOrMobile LineType = "FIXED_LINE_OR_MOBILE"
+	LineTypeTollFree          LineType = "TOLL_FREE"
+	LineTypeUnknown           LineType = "UNKNOWN"
+)
+
+var (
+	// ErrInvalidNumber is returned for input that is not a valid phone number
+	ErrInvalidNumber = errors.New("invalid phone number")
+	// ErrUnknownRegion is returned when a national number is given without a known default region
+	ErrUnknownRegion = errors.New("unknown default region")
+)
+
+// maxE164Digits is the longest number E.164 allows, calling code included
+const maxE164Digits = 15
+
+// Number is a parsed phone number
+type Number struct {
+	// E164 is the number in E.164 format, e.g. +971501234567
+	E164 string
+	// Country is the ISO 3166-1 alpha-2 code of the country the number belongs to
+	Country  string
+	LineType LineType
+}
+
+// TelURI returns a tel: link that dials the number
+func TelURI(e164 string) string {
+	if e164 == "" {
+		return ""
+	}
+	return "tel:" + e164
+}
+
+// WhatsAppURL returns a link that opens a WhatsApp chat with the number.
+// It is empty for line types that cannot use WhatsApp, such as fixed lines and toll free numbers.
+func WhatsAppURL(e164 string, lineType LineType) string {
+	if e164 == "" || lineType == LineTypeFixedLine || lineType == LineTypeTollFree {
+		return ""
+	}
+	return "https://wa.me/" + strings.TrimPrefix(e164, "+")
+}
+
+// Parse parses a phone number as a visitor would type it. Numbers starting with + or an
+// international prefix are read as international, anything else as a national number of defaultRegion.
+// Spaces, dots, dashes, slashes and parentheses are ignored, and Arabic-Indic digits are accepted.
+func Parse(raw, defaultRegion string) (Number, error) {
+	digits, international, err := normalizeDigits(raw)
+	if err != nil {
+		return Number{}, err
+	}
+	defaultRegion = strings.ToUpper(strings.TrimSpace(defaultRegion))
+
+	if !international {
+		internationalPrefix := "00"
+		if rg, ok := regions[defaultRegion]; ok && rg.internationalPrefix != "" {
+			internationalPrefix = rg.internationalPrefix
+		}
+		if rest, ok := strings.CutPrefix(digits, internationalPrefix); ok {
+			digits, international = rest, true
+		}
+	}
+	if international {
+		return parseInternational(digits)
+	}
+	return parseNational(digits, defaultRegion)
+}
+
+// normalizeDigits strips formatting from raw and reports whether it started with +
+func normalizeDigits(raw string) (string, bool, error) {
+	var digits strings.Builder
+	international := false
+	for _, r := range strings.TrimSpace(raw) {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r >= '٠' && r <= '٩': // Arabic-Indic digits
+			digits.WriteRune('0' + r - '٠')
+		case r >= '۰' && r <= '۹': // Extended Arabic-Indic digits, used in Persian and Urdu
+			digits.WriteRune('0' + r - '۰')
+		case r >= '०' && r <= '९': // Devanagari digits
+			digits.WriteRune('0' + r - '०')
+		case r == '+' && digits.Len() == 0 && !international:
+			international = true
+		case r == ' ' || r == '\u00a0' || r == '-' || r == '.' || r == '/' || r == '(' || r == ')':
+		default:
+			return "", false, fmt.Errorf("%w: unexpected character %q", ErrInvalidNumber, r)
+		}
+	}
+	if digits.Len() == 0 {
+		return "", false, ErrInvalidNumber
+	}
+	return digits.String(), international, nil
+}
+
+// parseInternational splits the calling code off digits
+func parseInternational(digits string) (Number, error) {
+	for n := 1; n <= 3 && n < len(digits); n++ {
+		if country, ok := callingCodes[digits[:n]]; ok {
+			return newNumber(country, digits[:n], digits[n:], true)
+		}
+	}
+	return Number{}, fmt.Errorf("%w: unknown country calling code", ErrInvalidNumber)
+}
+
+// parseNational reads digits as a number dialled within region
+func parseNational(digits, regionCode string) (Number, error) {
+	rg, known := regions[regionCode]
+	if !known {
+		callingCode, ok := regionCallingCode(regionCode)
+		if !ok {
+			return Number{}, fmt.Errorf("%w %q", ErrUnknownRegion, regionCode)
+		}
+		// Without a numbering plan the common trunk prefix 0 is assumed
+		return newNumber(regionCode, callingCode, strings.TrimPrefix(digits, "0"), false)
+	}
+
+	if rg.trunkPrefix != "" {
+		if nsn, ok := strings.CutPrefix(digits, rg.trunkPrefix); ok {
+			if number, err := newNumber(regionCode, rg.callingCode, nsn, false); err == nil {
+				return number, nil
+			}
+		}
+	}
+	if number, err := newNumber(regionCode, rg.callingCode, digits, false); err == nil {
+		return number, nil
+	}
+	// Visitors often type the calling code without the +, e.g. 971501234567
+	if nsn, ok := strings.CutPrefix(digits, rg.callingCode); ok {
+		return newNumber(regionCode, rg.callingCode, nsn, false)
+	}
+	return Number{}, ErrInvalidNumber
+}
+
+// newNumber validates the national significant number nsn against the numbering plan of country.
+// With allowTrunk a trunk prefix written after the calling code, as in +971 (0)50 123 4567, is dropped.
+func newNumber(country, callingCode, nsn string, allowTrunk bool) (Number, error) {
+	rg, known := regions[country]
+	if !known {
+		if len(nsn) < 4 || len(callingCode)+len(nsn) > maxE164Digits {
+			return Number{}, ErrInvalidNumber
+		}
+		return Number{E164: "+" + callingCode + nsn, Country: country, LineType: LineTypeUnknown}, nil
+	}
+
+	if lineType, ok := rg.match(nsn); ok {
+		return Number{E164: "+" + callingCode + nsn, Country: country, LineType: lineType}, nil
+	}
+	if allowTrunk && rg.trunkPrefix != "" {
+		if trimmed, ok := strings.CutPrefix(nsn, rg.trunkPrefix); ok {
+			return newNumber(country, callingCode, trimmed, false)
+		}
+	}
+	return Number{}, ErrInvalidNumber
+}
+
+// match returns the line type of the first number type nsn belongs to
+func (rg region) match(nsn string) (LineType, bool) {
+	for _, t := range rg.types {
+		if !containsInt(t.lengths, len(nsn)) {
+			continue
+		}
+		for _, prefix := range t.prefixes {
+			if strings.HasPrefix(nsn, prefix) {
+				return t.lineType, true
+			}
+		}
+	}
+	return "", false
+}
+
+// regionCallingCode finds the calling code of a region without a numbering plan
+func regionCallingCode(regionCode string) (string, bool) {
+	if regionCode == "" {
+		return "", false
+	}
+	for code, country := range callingCodes {
+		if country == regionCode {
+			return code, true
+		}
+	}
+	return "", false
+}
+
+// KnownRegion reports whether national numbers can be parsed for the region
+func KnownRegion(regionCode string) bool {
+	_, ok := regionCallingCode(strings.ToUpper(regionCode))
+	return ok
+}
+
+func containsInt(values []int, v int) bool {
+	for _, value := range values {
+		if value == v {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,133 @@
+package phone
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		region string
+		want   Number
+	}{
+		// UAE
+		{"UAE mobile", "+971 50 123 4567", "", Number{"+971501234567", "AE", LineTypeMobile}},
+		{"UAE mobile with trunk prefix", "050 123 4567", "AE", Number{"+971501234567", "AE", LineTypeMobile}},
+		{"UAE trunk prefix after the calling code", "+971 (0)50 123 4567", "", Number{"+971501234567", "AE", LineTypeMobile}},
+		{"UAE calling code without +", "971501234567", "AE", Number{"+971501234567", "AE", LineTypeMobile}},
+		{"UAE fixed line", "04 123 4567", "ae", Number{"+97141234567", "AE", LineTypeFixedLine}},
+		{"UAE toll free", "800 123", "AE", Number{"+971800123", "AE", LineTypeTollFree}},
+		{"Arabic-Indic digits", "٠٥٠ ١٢٣ ٤٥٦٧", "AE", Number{"+971501234567", "AE", LineTypeMobile}},
+		// India
+		{"India mobile", "+91 98765 43210", "", Number{"+919876543210", "IN", LineTypeMobile}},
+		{"India mobile with trunk prefix", "098765-43210", "IN", Number{"+919876543210", "IN", LineTypeMobile}},
+		{"India fixed line", "011 2345 6789", "IN", Number{"+911123456789", "IN", LineTypeFixedLine}},
+		{"India toll free", "1800 123 4567", "IN", Number{"+9118001234567", "IN", LineTypeTollFree}},
+		{"Devanagari digits", "+९१ ९८७६५ ४३२१०", "", Number{"+919876543210", "IN", LineTypeMobile}},
+		// North American Numbering Plan
+		{"NANP", "+1 (415) 555-2671", "", Number{"+14155552671", "US", LineTypeFixedLineOrMobile}},
+		{"NANP national", "(415) 555-2671", "US", Number{"+14155552671", "US", LineTypeFixedLineOrMobile}},
+		{"NANP with trunk prefix", "1 415 555 2671", "US", Number{"+14155552671", "US", LineTypeFixedLineOrMobile}},
+		{"NANP toll free", "+1 800 555 0199", "", Number{"+18005550199", "US", LineTypeTollFree}},
+		// Leading 00 and other international prefixes
+		{"leading 00 without a region", "00971 50 123 4567", "", Number{"+971501234567", "AE", LineTypeMobile}},
+		{"leading 00 with another region", "0091 98765 43210", "AE", Number{"+919876543210", "IN", LineTypeMobile}},
+		{"NANP international prefix", "011 44 20 7946 0958", "US", Number{"+442079460958", "GB", LineTypeFixedLine}},
+		// Countries without a numbering plan
+		{"international without a plan", "+49 30 123456", "", Number{"+4930123456", "DE", LineTypeUnknown}},
+		{"national with a country hint", "030 123456", "DE", Number{"+4930123456", "DE", LineTypeUnknown}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw, tt.region)
+			if err != nil {
+				t.Fatalf("Parse(%q, %q): %v", tt.raw, tt.region, err)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q, %q) = %+v, want %+v", tt.raw, tt.region, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	tests := []struct {
+		name   string
+		raw    string
+		region string
+		want   error
+	}{
+		{"empty", "", "AE", ErrInvalidNumber},
+		{"only formatting", " ( ) - ", "AE", ErrInvalidNumber},
+		{"letters", "call me", "AE", ErrInvalidNumber},
+		{"plus inside the number", "050+1234567", "AE", ErrInvalidNumber},
+		{"extension", "+971 4 123 4567 ext 12", "", ErrInvalidNumber},
+		{"unknown calling code", "+999 123456", "", ErrInvalidNumber},
+		{"UAE number too short", "+971 50 123 456", "", ErrInvalidNumber},
+		{"UAE number with unused prefix", "+971 1 234 5678", "", ErrInvalidNumber},
+		{"NANP number too short", "+1 415 555 267", "", ErrInvalidNumber},
+		{"India mobile too long", "+91 98765 432101", "", ErrInvalidNumber},
+		{"longer than E.164", "+49 1234 5678 9012 3456", "", ErrInvalidNumber},
+		{"national without a region", "050 123 4567", "", ErrUnknownRegion},
+		{"national with an unknown region", "050 123 4567", "ZZ", ErrUnknownRegion},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.raw, tt.region)
+			if !errors.Is(err, tt.want) {
+				t.Errorf("Parse(%q, %q) = %+v, %v, want %v", tt.raw, tt.region, got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestParserUsesSourceRegion(t *testing.T) {
+	parser, err := NewParser(map[string]string{"SCTGULF": "ae", "sctspl": "IN"})
+	if err != nil {
+		t.Fatalf("NewParser: %v", err)
+	}
+	tests := []struct {
+		source string
+		raw    string
+		want   string
+	}{
+		{"SCTGULF", "050 123 4567", "+971501234567"},
+		{"SCTSPL", "098765 43210", "+919876543210"},
+		{"SCTSPL", "+971 50 123 4567", "+971501234567"},
+	}
+	for _, tt := range tests {
+		got, err := parser.Parse(tt.source, tt.raw)
+		if err != nil || got.E164 != tt.want {
+			t.Errorf("Parse(%q, %q) = %+v, %v, want %s", tt.source, tt.raw, got, err, tt.want)
+		}
+	}
+	if _, err := parser.Parse("OTHER", "050 123 4567"); !errors.Is(err, ErrUnknownRegion) {
+		t.Errorf("national number of a source without a region: err = %v, want ErrUnknownRegion", err)
+	}
+
+	if _, err := NewParser(map[string]string{"SCTGULF": "XX"}); !errors.Is(err, ErrUnknownRegion) {
+		t.Errorf("NewParser with an unknown region: err = %v, want ErrUnknownRegion", err)
+	}
+}
+
+func TestWhatsAppURL(t *testing.T) {
+	tests := []struct {
+		e164     string
+		lineType LineType
+		want     string
+	}{
+		{"+971501234567", LineTypeMobile, "https://wa.me/971501234567"},
+		{"+14155552671", LineTypeFixedLineOrMobile, "https://wa.me/14155552671"},
+		{"+4930123456", LineTypeUnknown, "https://wa.me/4930123456"},
+		{"+97141234567", LineTypeFixedLine, ""},
+		{"+971800123", LineTypeTollFree, ""},
+		{"", LineTypeMobile, ""},
+	}
+	for _, tt := range tests {
+		if got := WhatsAppURL(tt.e164, tt.lineType); got != tt.want {
+			t.Errorf("WhatsAppURL(%q, %s) = %q, want %q", tt.e164, tt.lineType, got, tt.want)
+		}
+	}
+}
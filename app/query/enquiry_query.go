@@ -0,0 +1,248 @@
+package query
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// enquiryColumns lists the enquiry columns in the order they are scanned
+const enquiryColumns = "id, reference_number, source, name, email, phone_number, company_name, subject, message, country, raw_payload, status, status_history, created_at, updated_at, fingerprint, resubmissions, spam_score, spam_reasons, phone_e164, phone_country, phone_line_type, notes, slack_messages, assignee, assignee_slack_id, locale, attachments, notified_at"
+
+// EnquirySchema returns the statements that create the enquiry tables.
+// Statements are idempotent and are applied in order on startup.
+func (qb *QueryBuilder) EnquirySchema() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS enquiries (
+			id TEXT PRIMARY KEY,
+			source TEXT NOT NULL,
+			name TEXT NOT NULL,
+			email TEXT NOT NULL,
+			phone_number TEXT NOT NULL,
+			company_name TEXT NOT NULL,
+			subject TEXT NOT NULL,
+			message TEXT NOT NULL,
+			raw_payload JSONB NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS enquiries_created_at_idx ON enquiries (created_at DESC, id DESC)`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS status TEXT NOT NULL DEFAULT 'NEW'`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS status_history JSONB NOT NULL DEFAULT '[]'`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS country TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS reference_number TEXT`,
+		`CREATE UNIQUE INDEX IF NOT EXISTS enquiries_reference_number_idx ON enquiries (reference_number)`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS fingerprint TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS resubmissions JSONB NOT NULL DEFAULT '[]'`,
+		`CREATE INDEX IF NOT EXISTS enquiries_fingerprint_idx ON enquiries (fingerprint, created_at DESC)`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS spam_score DOUBLE PRECISION NOT NULL DEFAULT 0`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS spam_reasons JSONB NOT NULL DEFAULT '[]'`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS phone_e164 TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS phone_country TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS phone_line_type TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS notes JSONB NOT NULL DEFAULT '[]'`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS slack_messages JSONB NOT NULL DEFAULT '[]'`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS assignee TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS assignee_slack_id TEXT NOT NULL DEFAULT ''`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS locale TEXT NOT NULL DEFAULT 'en'`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS attachments JSONB NOT NULL DEFAULT '[]'`,
+		`ALTER TABLE enquiries ADD COLUMN IF NOT EXISTS notified_at TIMESTAMPTZ`,
+		// Enquiries stored before notified_at were notified on arrival, unless the spam filter
+		// quarantined them and they were never released
+		`UPDATE enquiries SET notified_at = created_at WHERE notified_at IS NULL AND ` +
+			`(status_history->0->>'to' IS DISTINCT FROM 'SPAM' OR status_history @> '[{"from": "SPAM", "to": "NEW"}]')`,
+	}
+}
+
+// BuildEnquiryQuery builds a query for enquiry operations
+func (qb *QueryBuilder) BuildEnquiryQuery(ctx context.Context, operation string, params map[string]interface{}) (string, []interface{}, error) {
+	switch operation {
+	case "create_enquiry":
+		return qb.buildCreateEnquiryQuery(params)
+	case "get_enquiry":
+		return qb.buildGetEnquiryQuery(params)
+	case "update_enquiry_status":
+		return qb.buildUpdateEnquiryStatusQuery(params)
+	case "release_enquiry":
+		return qb.buildReleaseEnquiryQuery(params)
+	case "find_duplicate_enquiry":
+		return qb.buildFindDuplicateEnquiryQuery(params)
+	case "lock_enquiry_fingerprint":
+		return "SELECT pg_advisory_lock(hashtextextended($1, 0))", []interface{}{params["fingerprint"]}, nil
+	case "unlock_enquiry_fingerprint":
+		return "SELECT pg_advisory_unlock(hashtextextended($1, 0))", []interface{}{params["fingerprint"]}, nil
+	case "add_enquiry_resubmission":
+		return qb.buildAddEnquiryResubmissionQuery(params)
+	case "add_enquiry_note":
+		return qb.buildAddEnquiryNoteQuery(params)
+	case "assign_enquiry":
+		return qb.buildAssignEnquiryQuery(params)
+	case "save_enquiry_slack_message":
+		return qb.buildSaveEnquirySlackMessageQuery(params)
+	case "list_enquiries":
+		return qb.buildListEnquiriesQuery(params)
+	case "count_enquiries":
+		return qb.buildCountEnquiriesQuery(params)
+	case "enquiry_stats":
+		return qb.buildEnquiryStatsQuery(params)
+	default:
+		return "", nil, fmt.Errorf("unknown operation: %s", operation)
+	}
+}
+
+func (qb *QueryBuilder) buildCreateEnquiryQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "INSERT INTO enquiries (" + enquiryColumns + ") VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23, $24, $25, $26, $27, $28, $29)",
+		[]interface{}{
+			params["id"],
+			params["reference_number"],
+			params["source"],
+			params["name"],
+			params["email"],
+			params["phone_number"],
+			params["company_name"],
+			params["subject"],
+			params["message"],
+			params["country"],
+			params["raw_payload"],
+			params["status"],
+			params["status_history"],
+			params["created_at"],
+			params["updated_at"],
+			params["fingerprint"],
+			params["resubmissions"],
+			params["spam_score"],
+			params["spam_reasons"],
+			params["phone_e164"],
+			params["phone_country"],
+			params["phone_line_type"],
+			params["notes"],
+			params["slack_messages"],
+			params["assignee"],
+			params["assignee_slack_id"],
+			params["locale"],
+			params["attachments"],
+			params["notified_at"],
+		}, nil
+}
+
+func (qb *QueryBuilder) buildGetEnquiryQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "SELECT " + enquiryColumns + " FROM enquiries WHERE id = $1", []interface{}{params["id"]}, nil
+}
+
+func (qb *QueryBuilder) buildUpdateEnquiryStatusQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "UPDATE enquiries SET status = $1, status_history = status_history || $2::jsonb, updated_at = $3 " +
+			"WHERE id = $4 AND status = $5 RETURNING " + enquiryColumns,
+		[]interface{}{params["to_status"], params["history"], params["updated_at"], params["id"], params["from_status"]}, nil
+}
+
+// buildReleaseEnquiryQuery also records when the notifications were queued, only for an enquiry that was never notified
+func (qb *QueryBuilder) buildReleaseEnquiryQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "UPDATE enquiries SET status = $1, status_history = status_history || $2::jsonb, notified_at = $3, updated_at = $3 " +
+			"WHERE id = $4 AND status = $5 AND notified_at IS NULL RETURNING " + enquiryColumns,
+		[]interface{}{params["to_status"], params["history"], params["updated_at"], params["id"], params["from_status"]}, nil
+}
+
+// buildFindDuplicateEnquiryQuery finds the newest enquiry with the fingerprint, ignoring spam
+func (qb *QueryBuilder) buildFindDuplicateEnquiryQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "SELECT " + enquiryColumns + " FROM enquiries WHERE fingerprint = $1 AND created_at >= $2 AND status <> 'SPAM' " +
+			"ORDER BY created_at DESC LIMIT 1",
+		[]interface{}{params["fingerprint"], params["since"]}, nil
+}
+
+func (qb *QueryBuilder) buildAddEnquiryResubmissionQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "UPDATE enquiries SET resubmissions = resubmissions || $1::jsonb, attachments = attachments || $2::jsonb, updated_at = $3 WHERE id = $4 RETURNING " + enquiryColumns,
+		[]interface{}{params["resubmission"], params["attachments"], params["updated_at"], params["id"]}, nil
+}
+
+func (qb *QueryBuilder) buildAddEnquiryNoteQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "UPDATE enquiries SET notes = notes || $1::jsonb, updated_at = $2 WHERE id = $3 RETURNING " + enquiryColumns,
+		[]interface{}{params["note"], params["updated_at"], params["id"]}, nil
+}
+
+func (qb *QueryBuilder) buildAssignEnquiryQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "UPDATE enquiries SET assignee = $1, assignee_slack_id = $2, updated_at = $3 WHERE id = $4 RETURNING " + enquiryColumns,
+		[]interface{}{params["assignee"], params["assignee_slack_id"], params["updated_at"], params["id"]}, nil
+}
+
+// buildSaveEnquirySlackMessageQuery replaces the message of the same destination, if any
+func (qb *QueryBuilder) buildSaveEnquirySlackMessageQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "UPDATE enquiries SET slack_messages = (SELECT COALESCE(jsonb_agg(m), '[]'::jsonb) FROM jsonb_array_elements(slack_messages) m " +
+			"WHERE m->>'destination' <> $1) || $2::jsonb WHERE id = $3",
+		[]interface{}{params["destination"], params["message"], params["id"]}, nil
+}
+
+func (qb *QueryBuilder) buildListEnquiriesQuery(params map[string]interface{}) (string, []interface{}, error) {
+	conditions, args := enquiryFilterConditions(params)
+
+	if createdAt, ok := params["after_created_at"]; ok {
+		args = append(args, createdAt, params["after_id"])
+		conditions = append(conditions, fmt.Sprintf("(created_at, id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+
+	q := "SELECT " + enquiryColumns + " FROM enquiries" + whereClause(conditions) + " ORDER BY created_at DESC, id DESC"
+	if limit, ok := params["limit"]; ok {
+		args = append(args, limit)
+		q += fmt.Sprintf(" LIMIT $%d", len(args))
+	}
+	return q, args, nil
+}
+
+func (qb *QueryBuilder) buildCountEnquiriesQuery(params map[string]interface{}) (string, []interface{}, error) {
+	conditions, args := enquiryFilterConditions(params)
+	return "SELECT COUNT(*) FROM enquiries" + whereClause(conditions), args, nil
+}
+
+func (qb *QueryBuilder) buildEnquiryStatsQuery(params map[string]interface{}) (string, []interface{}, error) {
+	conditions, args := enquiryFilterConditions(params)
+	return "SELECT status, source, COUNT(*) FROM enquiries" + whereClause(conditions) + " GROUP BY status, source", args, nil
+}
+
+// enquiryFilterConditions turns the optional filter params into WHERE conditions
+func enquiryFilterConditions(params map[string]interface{}) ([]string, []interface{}) {
+	var conditions []string
+	var args []interface{}
+
+	add := func(condition string, value interface{}) {
+		args = append(args, value)
+		conditions = append(conditions, fmt.Sprintf(condition, len(args)))
+	}
+
+	if source, ok := params["source"]; ok {
+		add("source = $%d", source)
+	}
+	if status, ok := params["status"]; ok {
+		add("status = $%d", status)
+	}
+	if createdFrom, ok := params["created_from"]; ok {
+		add("created_at >= $%d", createdFrom)
+	}
+	if createdTo, ok := params["created_to"]; ok {
+		add("created_at < $%d", createdTo)
+	}
+	if email, ok := params["email"]; ok {
+		add("LOWER(email) = LOWER($%d)", email)
+	}
+	if company, ok := params["company"]; ok {
+		add("company_name ILIKE $%d", likePattern(company))
+	}
+	if search, ok := params["search"]; ok {
+		args = append(args, likePattern(search))
+		n := len(args)
+		conditions = append(conditions, fmt.Sprintf(
+			"(reference_number ILIKE $%[1]d OR name ILIKE $%[1]d OR email ILIKE $%[1]d OR company_name ILIKE $%[1]d OR subject ILIKE $%[1]d OR message ILIKE $%[1]d)", n))
+	}
+	return conditions, args
+}
+
+func whereClause(conditions []string) string {
+	if len(conditions) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(conditions, " AND ")
+}
+
+// likePattern wraps a value for a substring ILIKE match, escaping wildcards
+func likePattern(value interface{}) string {
+	escaped := strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(fmt.Sprint(value))
+	return "%" + escaped + "%"
+}
@@ -0,0 +1,130 @@
+package query
+
+import (
+	"context"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+var placeholderPattern = regexp.MustCompile(`\$(\d+)`)
+
+// maxPlaceholder returns the highest $n placeholder of a query
+func maxPlaceholder(q string) int {
+	highest := 0
+	for _, match := range placeholderPattern.FindAllStringSubmatch(q, -1) {
+		n, _ := strconv.Atoi(match[1])
+		highest = max(highest, n)
+	}
+	return highest
+}
+
+func TestBuildCreateEnquiryQuery(t *testing.T) {
+	columns := strings.Split(enquiryColumns, ", ")
+	// Every param is named like its column, so the arguments must come back in column order
+	params := make(map[string]interface{}, len(columns))
+	for _, column := range columns {
+		params[column] = column
+	}
+
+	q, args, err := NewQueryBuilder().BuildEnquiryQuery(context.Background(), "create_enquiry", params)
+	if err != nil {
+		t.Fatalf("BuildEnquiryQuery: %v", err)
+	}
+	if n := maxPlaceholder(q); n != len(columns) {
+		t.Errorf("insert has %d placeholders for %d columns", n, len(columns))
+	}
+	if len(args) != len(columns) {
+		t.Fatalf("insert has %d arguments for %d columns", len(args), len(columns))
+	}
+	for i, column := range columns {
+		if args[i] != column {
+			t.Errorf("argument $%d = %v, want the %s param", i+1, args[i], column)
+		}
+	}
+}
+
+func TestBuildEnquiryQueryPlaceholdersMatchArguments(t *testing.T) {
+	operations := []string{
+		"get_enquiry",
+		"update_enquiry_status",
+		"release_enquiry",
+		"find_duplicate_enquiry",
+		"lock_enquiry_fingerprint",
+		"unlock_enquiry_fingerprint",
+		"add_enquiry_resubmission",
+		"add_enquiry_note",
+		"assign_enquiry",
+		"save_enquiry_slack_message",
+	}
+	for _, operation := range operations {
+		q, args, err := NewQueryBuilder().BuildEnquiryQuery(context.Background(), operation, map[string]interface{}{})
+		if err != nil {
+			t.Errorf("%s: %v", operation, err)
+			continue
+		}
+		if n := maxPlaceholder(q); n != len(args) {
+			t.Errorf("%s has %d placeholders and %d arguments", operation, n, len(args))
+		}
+	}
+
+	if _, _, err := NewQueryBuilder().BuildEnquiryQuery(context.Background(), "drop_enquiries", nil); err == nil {
+		t.Error("an unknown operation was built")
+	}
+}
+
+func TestEnquirySchemaIsIdempotent(t *testing.T) {
+	for _, statement := range NewQueryBuilder().EnquirySchema() {
+		switch {
+		case strings.HasPrefix(statement, "CREATE TABLE"), strings.HasPrefix(statement, "CREATE INDEX"), strings.HasPrefix(statement, "CREATE UNIQUE INDEX"):
+			if !strings.Contains(statement, "IF NOT EXISTS") {
+				t.Errorf("statement fails when applied twice: %s", statement)
+			}
+		case strings.HasPrefix(statement, "ALTER TABLE"):
+			if !strings.Contains(statement, "ADD COLUMN IF NOT EXISTS") {
+				t.Errorf("statement fails when applied twice: %s", statement)
+			}
+		}
+	}
+}
+
+func TestBuildListEnquiriesQuery(t *testing.T) {
+	q, args, err := NewQueryBuilder().BuildEnquiryQuery(context.Background(), "list_enquiries", map[string]interface{}{
+		"source":           "SCTGULF",
+		"email":            "jane@example.com",
+		"search":           "50%_off",
+		"after_created_at": "2026-10-18T09:00:00Z",
+		"after_id":         "enquiry-1",
+		"limit":            21,
+	})
+	if err != nil {
+		t.Fatalf("BuildEnquiryQuery: %v", err)
+	}
+
+	for _, part := range []string{
+		"source = $1",
+		"LOWER(email) = LOWER($2)",
+		"subject ILIKE $3",
+		"(created_at, id) < ($4, $5)",
+		"ORDER BY created_at DESC, id DESC LIMIT $6",
+	} {
+		if !strings.Contains(q, part) {
+			t.Errorf("query does not contain %q: %s", part, q)
+		}
+	}
+	want := []interface{}{"SCTGULF", "jane@example.com", `%50\%\_off%`, "2026-10-18T09:00:00Z", "enquiry-1", 21}
+	if len(args) != len(want) {
+		t.Fatalf("args = %v, want %v", args, want)
+	}
+	for i := range want {
+		if args[i] != want[i] {
+			t.Errorf("argument $%d = %v, want %v", i+1, args[i], want[i])
+		}
+	}
+
+	q, args, err = NewQueryBuilder().BuildEnquiryQuery(context.Background(), "count_enquiries", map[string]interface{}{})
+	if err != nil || strings.Contains(q, "WHERE") || len(args) != 0 {
+		t.Errorf("unfiltered count = %s, %v, %v, want no conditions", q, args, err)
+	}
+}
@@ -0,0 +1,66 @@
+package query
+
+import (
+	"context"
+	"fmt"
+)
+
+// idempotencyColumns lists the idempotency columns in the order they are scanned
+const idempotencyColumns = "key, request_hash, status, owner, response, locked_until, expires_at, created_at"
+
+// IdempotencySchema returns the statements that create the idempotency key table
+func (qb *QueryBuilder) IdempotencySchema() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS idempotency_keys (
+			key TEXT PRIMARY KEY,
+			request_hash TEXT NOT NULL,
+			status TEXT NOT NULL,
+			response BYTEA,
+			locked_until TIMESTAMPTZ NOT NULL,
+			expires_at TIMESTAMPTZ NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS idempotency_keys_expires_at_idx ON idempotency_keys (expires_at)`,
+		`ALTER TABLE idempotency_keys ADD COLUMN IF NOT EXISTS owner TEXT NOT NULL DEFAULT ''`,
+	}
+}
+
+// BuildIdempotencyQuery builds a query for idempotency key operations
+func (qb *QueryBuilder) BuildIdempotencyQuery(ctx context.Context, operation string, params map[string]interface{}) (string, []interface{}, error) {
+	switch operation {
+	case "reserve_idempotency_key":
+		return qb.buildReserveIdempotencyKeyQuery(params)
+	case "complete_idempotency_key":
+		return "UPDATE idempotency_keys SET status = 'COMPLETED', response = $1 WHERE key = $2 AND owner = $3 AND status = 'IN_PROGRESS'",
+			[]interface{}{params["response"], params["key"], params["owner"]}, nil
+	case "release_idempotency_key":
+		return "DELETE FROM idempotency_keys WHERE key = $1 AND owner = $2 AND status = 'IN_PROGRESS'",
+			[]interface{}{params["key"], params["owner"]}, nil
+	case "get_idempotency_key":
+		return "SELECT " + idempotencyColumns + " FROM idempotency_keys WHERE key = $1", []interface{}{params["key"]}, nil
+	case "delete_expired_idempotency_keys":
+		return "DELETE FROM idempotency_keys WHERE expires_at <= $1", []interface{}{params["now"]}, nil
+	default:
+		return "", nil, fmt.Errorf("unknown operation: %s", operation)
+	}
+}
+
+// buildReserveIdempotencyKeyQuery inserts the key, or takes over an expired or abandoned one under a new owner.
+// No row is returned when the key is held by a live record.
+func (qb *QueryBuilder) buildReserveIdempotencyKeyQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "INSERT INTO idempotency_keys (key, request_hash, status, owner, locked_until, expires_at, created_at) " +
+			"VALUES ($1, $2, 'IN_PROGRESS', $7, $3, $4, $5) " +
+			"ON CONFLICT (key) DO UPDATE SET request_hash = EXCLUDED.request_hash, status = 'IN_PROGRESS', owner = EXCLUDED.owner, response = NULL, " +
+			"locked_until = EXCLUDED.locked_until, expires_at = EXCLUDED.expires_at, created_at = EXCLUDED.created_at " +
+			"WHERE idempotency_keys.expires_at <= $6 OR (idempotency_keys.status = 'IN_PROGRESS' AND idempotency_keys.locked_until <= $6) " +
+			"RETURNING key",
+		[]interface{}{
+			params["key"],
+			params["request_hash"],
+			params["locked_until"],
+			params["expires_at"],
+			params["created_at"],
+			params["now"],
+			params["owner"],
+		}, nil
+}
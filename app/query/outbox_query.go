@@ -0,0 +1,100 @@
+package query
+
+import (
+	"context"
+	"fmt"
+)
+
+// outboxColumns lists the outbox columns in the order they are scanned
+const outboxColumns = "id, enquiry_id, kind, destination, status, attempts, last_error, next_attempt_at, locked_until, created_at, updated_at, payload"
+
+// OutboxSchema returns the statements that create the notification outbox.
+// It references the enquiries table, so it must be applied after EnquirySchema.
+func (qb *QueryBuilder) OutboxSchema() []string {
+	return []string{
+		`CREATE TABLE IF NOT EXISTS notification_outbox (
+			id TEXT PRIMARY KEY,
+			enquiry_id TEXT NOT NULL REFERENCES enquiries (id),
+			kind TEXT NOT NULL,
+			destination TEXT NOT NULL,
+			status TEXT NOT NULL,
+			attempts INTEGER NOT NULL DEFAULT 0,
+			last_error TEXT NOT NULL DEFAULT '',
+			next_attempt_at TIMESTAMPTZ NOT NULL,
+			locked_until TIMESTAMPTZ,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		)`,
+		`CREATE INDEX IF NOT EXISTS notification_outbox_due_idx ON notification_outbox (next_attempt_at) WHERE status = 'PENDING'`,
+		`ALTER TABLE notification_outbox ADD COLUMN IF NOT EXISTS payload JSONB`,
+	}
+}
+
+// BuildOutboxQuery builds a query for outbox operations
+func (qb *QueryBuilder) BuildOutboxQuery(ctx context.Context, operation string, params map[string]interface{}) (string, []interface{}, error) {
+	switch operation {
+	case "create_outbox_message":
+		return qb.buildCreateOutboxMessageQuery(params)
+	case "claim_due_outbox_messages":
+		return qb.buildClaimDueOutboxMessagesQuery(params)
+	case "mark_outbox_message_delivered":
+		return qb.buildMarkOutboxMessageDeliveredQuery(params)
+	case "reschedule_outbox_message":
+		return qb.buildRescheduleOutboxMessageQuery(params)
+	case "mark_outbox_message_dead":
+		return qb.buildMarkOutboxMessageDeadQuery(params)
+	case "has_pending_outbox_message":
+		return qb.buildHasPendingOutboxMessageQuery(params)
+	default:
+		return "", nil, fmt.Errorf("unknown operation: %s", operation)
+	}
+}
+
+func (qb *QueryBuilder) buildCreateOutboxMessageQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "INSERT INTO notification_outbox (id, enquiry_id, kind, destination, status, next_attempt_at, created_at, updated_at, payload) " +
+			"VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)",
+		[]interface{}{
+			params["id"],
+			params["enquiry_id"],
+			params["kind"],
+			params["destination"],
+			params["status"],
+			params["next_attempt_at"],
+			params["created_at"],
+			params["updated_at"],
+			params["payload"],
+		}, nil
+}
+
+// buildClaimDueOutboxMessagesQuery locks due rows with SKIP LOCKED so concurrent workers pick different rows
+func (qb *QueryBuilder) buildClaimDueOutboxMessagesQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "UPDATE notification_outbox SET locked_until = $1, updated_at = $2 WHERE id IN (" +
+			"SELECT id FROM notification_outbox WHERE status = 'PENDING' AND next_attempt_at <= $2 " +
+			"AND (locked_until IS NULL OR locked_until <= $2) ORDER BY next_attempt_at LIMIT $3 FOR UPDATE SKIP LOCKED" +
+			") RETURNING " + outboxColumns,
+		[]interface{}{params["locked_until"], params["now"], params["limit"]}, nil
+}
+
+func (qb *QueryBuilder) buildMarkOutboxMessageDeliveredQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "UPDATE notification_outbox SET status = 'DELIVERED', attempts = attempts + 1, last_error = '', " +
+			"locked_until = NULL, updated_at = $1 WHERE id = $2",
+		[]interface{}{params["updated_at"], params["id"]}, nil
+}
+
+func (qb *QueryBuilder) buildRescheduleOutboxMessageQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "UPDATE notification_outbox SET attempts = attempts + 1, last_error = $1, next_attempt_at = $2, " +
+			"locked_until = NULL, updated_at = $3 WHERE id = $4",
+		[]interface{}{params["last_error"], params["next_attempt_at"], params["updated_at"], params["id"]}, nil
+}
+
+func (qb *QueryBuilder) buildMarkOutboxMessageDeadQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "UPDATE notification_outbox SET status = 'DEAD', attempts = attempts + 1, last_error = $1, " +
+			"locked_until = NULL, updated_at = $2 WHERE id = $3",
+		[]interface{}{params["last_error"], params["updated_at"], params["id"]}, nil
+}
+
+func (qb *QueryBuilder) buildHasPendingOutboxMessageQuery(params map[string]interface{}) (string, []interface{}, error) {
+	return "SELECT EXISTS (SELECT 1 FROM notification_outbox WHERE enquiry_id = $1 AND kind = $2 " +
+			"AND destination = $3 AND status = 'PENDING')",
+		[]interface{}{params["enquiry_id"], params["kind"], params["destination"]}, nil
+}
@@ -0,0 +1,63 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memorySweepInterval is how often buckets that refilled completely are dropped
+const memorySweepInterval = time.Minute
+
+// MemoryStore keeps buckets in process memory, limits are per instance
+type MemoryStore struct {
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+// NewMemoryStore creates an empty in-memory bucket store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]*bucket),
+	}
+}
+
+// Take refills the buckets of keys under the store lock and takes a token from each when all have one
+func (s *MemoryStore) Take(ctx context.Context, keys []string, limit int, window time.Duration, now time.Time) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// Full buckets behave like missing ones, so they are dropped lazily to bound memory
+	if now.Sub(s.lastSweep) >= memorySweepInterval {
+		for k, b := range s.buckets {
+			if b.full(now) {
+				delete(s.buckets, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	buckets := make([]*bucket, len(keys))
+	var retryAfter time.Duration
+	for i, key := range keys {
+		b, ok := s.buckets[key]
+		if !ok {
+			b = &bucket{tokens: float64(limit), updated: now, window: window}
+			s.buckets[key] = b
+		}
+		b.refill(limit, window, now)
+		retryAfter = max(retryAfter, b.retryAfter(limit, window))
+		buckets[i] = b
+	}
+	if retryAfter > 0 {
+		return Result{Allowed: false, RetryAfter: retryAfter}, nil
+	}
+
+	remaining := limit
+	for _, b := range buckets {
+		b.tokens--
+		remaining = min(remaining, int(b.tokens))
+	}
+	return Result{Allowed: true, Remaining: remaining}, nil
+}
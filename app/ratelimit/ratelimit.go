@@ -0,0 +1,108 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"math"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ErrInvalidLimit is returned for a limit or window that is not positive
+var ErrInvalidLimit = errors.New("rate limit and window must be positive")
+
+// Result is the outcome of taking a token from buckets
+type Result struct {
+	Allowed bool
+	// Remaining is the number of whole tokens left in the emptiest bucket
+	Remaining int
+	// RetryAfter is how long until every bucket has a token again, zero when allowed
+	RetryAfter time.Duration
+}
+
+// Store keeps token buckets. A bucket holds up to limit tokens and refills evenly over window.
+// Take takes one token from each bucket of the distinct keys, atomically: when one bucket is empty
+// no token is taken from the others, so a rejected call does not use up the limits it passed.
+type Store interface {
+	Take(ctx context.Context, keys []string, limit int, window time.Duration, now time.Time) (Result, error)
+}
+
+// Limiter checks calls against the buckets of a store
+type Limiter struct {
+	store  Store
+	logger *zap.Logger
+	now    func() time.Time
+}
+
+// NewLimiter creates a limiter. A nil store turns limiting off.
+func NewLimiter(store Store, logger *zap.Logger) *Limiter {
+	return &Limiter{
+		store:  store,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// Allow takes a token from the bucket of every key, or from none of them when one is empty.
+// Calls are let through when the store fails, an outage of the limiter must not take the forms down.
+func (l *Limiter) Allow(ctx context.Context, keys []string, limit int, window time.Duration) (Result, error) {
+	if limit <= 0 || window <= 0 {
+		return Result{}, ErrInvalidLimit
+	}
+	keys = distinct(keys)
+	if l == nil || l.store == nil || len(keys) == 0 {
+		return Result{Allowed: true, Remaining: limit}, nil
+	}
+
+	result, err := l.store.Take(ctx, keys, limit, window, l.now())
+	if err != nil {
+		l.logger.Warn("Rate limiter unavailable, allowing call", zap.Strings("keys", keys), zap.Error(err))
+		return Result{Allowed: true, Remaining: limit}, nil
+	}
+	return result, nil
+}
+
+// distinct drops repeated keys, a call takes one token per bucket
+func distinct(keys []string) []string {
+	seen := make(map[string]bool, len(keys))
+	unique := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if !seen[key] {
+			seen[key] = true
+			unique = append(unique, key)
+		}
+	}
+	return unique
+}
+
+// bucket is a token bucket that refills continuously
+type bucket struct {
+	tokens  float64
+	updated time.Time
+	window  time.Duration
+}
+
+// refill adds the tokens that accrued up to now
+func (b *bucket) refill(limit int, window time.Duration, now time.Time) {
+	capacity := float64(limit)
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = math.Min(capacity, b.tokens+capacity*float64(elapsed)/float64(window))
+		b.updated = now
+	}
+	b.window = window
+}
+
+// retryAfter is how long until the bucket has a token, zero when it has one
+func (b *bucket) retryAfter(limit int, window time.Duration) time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	missing := 1 - b.tokens
+	return time.Duration(math.Ceil(missing * float64(window) / float64(limit)))
+}
+
+// full reports whether the bucket has refilled completely by now, so it can be forgotten
+func (b *bucket) full(now time.Time) bool {
+	return now.Sub(b.updated) >= b.window
+}
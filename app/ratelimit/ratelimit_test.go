@@ -0,0 +1,145 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// takeStep takes a token at offset from the start and checks the result
+type takeStep struct {
+	offset     time.Duration
+	allowed    bool
+	remaining  int
+	retryAfter time.Duration
+}
+
+// refillSteps drain a bucket of 3 tokens per 10s, one token refills every 3.33s
+var refillSteps = []takeStep{
+	{offset: 0, allowed: true, remaining: 2},
+	{offset: 0, allowed: true, remaining: 1},
+	{offset: 0, allowed: true, remaining: 0},
+	// A third of 10s is rounded up, a client retrying after RetryAfter never comes back too early
+	{offset: 0, allowed: false, retryAfter: 3333333334 * time.Nanosecond},
+	// 0.3 tokens after 1s
+	{offset: time.Second, allowed: false, retryAfter: 2333333334 * time.Nanosecond},
+	// 1.2 tokens after 4s, one is taken
+	{offset: 4 * time.Second, allowed: true, remaining: 0},
+	{offset: 4 * time.Second, allowed: false, retryAfter: 2666666667 * time.Nanosecond},
+	// Refilling stops at the limit
+	{offset: time.Minute, allowed: true, remaining: 2},
+}
+
+// runTakeSteps takes from store through a limiter whose clock is moved to every step.
+// RetryAfter is expected rounded up to resolution, the precision of the store.
+func runTakeSteps(t *testing.T, store Store, key string, steps []takeStep, resolution time.Duration) {
+	t.Helper()
+	start := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+	now := start
+	limiter := NewLimiter(store, zap.NewNop())
+	limiter.now = func() time.Time { return now }
+
+	for i, step := range steps {
+		now = start.Add(step.offset)
+		got, err := limiter.Allow(context.Background(), []string{key}, 3, 10*time.Second)
+		if err != nil {
+			t.Fatalf("step %d: Allow: %v", i, err)
+		}
+		want := Result{Allowed: step.allowed, Remaining: step.remaining, RetryAfter: roundUp(step.retryAfter, resolution)}
+		if got != want {
+			t.Errorf("step %d at +%s: got %+v, want %+v", i, step.offset, got, want)
+		}
+	}
+}
+
+func roundUp(d, resolution time.Duration) time.Duration {
+	if rounded := d.Truncate(resolution); rounded != d {
+		return rounded + resolution
+	}
+	return d
+}
+
+func TestMemoryStoreRefill(t *testing.T) {
+	runTakeSteps(t, NewMemoryStore(), "sendContactInfo:ip:203.0.113.7", refillSteps, time.Nanosecond)
+}
+
+func TestMemoryStoreKeysAreSeparate(t *testing.T) {
+	store := NewMemoryStore()
+	now := time.Now()
+	for i := 0; i < 2; i++ {
+		if result, _ := store.Take(context.Background(), []string{"a"}, 2, time.Hour, now); !result.Allowed {
+			t.Fatalf("take %d from a was denied", i)
+		}
+	}
+	if result, _ := store.Take(context.Background(), []string{"a"}, 2, time.Hour, now); result.Allowed {
+		t.Error("a third take from a was allowed")
+	}
+	if result, _ := store.Take(context.Background(), []string{"b"}, 2, time.Hour, now); !result.Allowed {
+		t.Error("take from b was denied by the bucket of a")
+	}
+}
+
+func TestMemoryStoreTakesAllOrNone(t *testing.T) {
+	runTakeAllOrNone(t, NewMemoryStore(), "a", "b", time.Nanosecond)
+}
+
+// runTakeAllOrNone checks that a take from several buckets is rejected as a whole by an empty one,
+// without using up the others. The keys must be unused.
+func runTakeAllOrNone(t *testing.T, store Store, a, b string, resolution time.Duration) {
+	t.Helper()
+	ctx := context.Background()
+	now := time.Date(2026, 10, 18, 9, 0, 0, 0, time.UTC)
+
+	if result, err := store.Take(ctx, []string{a}, 2, time.Hour, now); err != nil || !result.Allowed {
+		t.Fatalf("take from a = %+v, %v", result, err)
+	}
+	result, err := store.Take(ctx, []string{a, b}, 2, time.Hour, now)
+	if err != nil || result != (Result{Allowed: true, Remaining: 0}) {
+		t.Fatalf("take from a and b = %+v, %v, want allowed with the 0 tokens left in a", result, err)
+	}
+
+	// a is empty, so b keeps its token
+	result, err = store.Take(ctx, []string{b, a}, 2, time.Hour, now)
+	if err != nil || result.Allowed || result.RetryAfter != roundUp(30*time.Minute, resolution) {
+		t.Fatalf("take from b and empty a = %+v, %v, want it rejected until a refills", result, err)
+	}
+	if result, err := store.Take(ctx, []string{b}, 2, time.Hour, now); err != nil || !result.Allowed {
+		t.Errorf("take from b = %+v, %v, want the token the rejected call left", result, err)
+	}
+}
+
+func TestLimiterAllow(t *testing.T) {
+	if _, err := NewLimiter(NewMemoryStore(), zap.NewNop()).Allow(context.Background(), []string{"k"}, 0, time.Minute); !errors.Is(err, ErrInvalidLimit) {
+		t.Errorf("zero limit: err = %v, want ErrInvalidLimit", err)
+	}
+	if _, err := NewLimiter(NewMemoryStore(), zap.NewNop()).Allow(context.Background(), []string{"k"}, 1, 0); !errors.Is(err, ErrInvalidLimit) {
+		t.Errorf("zero window: err = %v, want ErrInvalidLimit", err)
+	}
+
+	result, err := NewLimiter(nil, zap.NewNop()).Allow(context.Background(), []string{"k"}, 3, time.Minute)
+	if err != nil || result != (Result{Allowed: true, Remaining: 3}) {
+		t.Errorf("without a store: got %+v, %v, want an allowed call", result, err)
+	}
+
+	result, err = NewLimiter(failingStore{}, zap.NewNop()).Allow(context.Background(), []string{"k"}, 3, time.Minute)
+	if err != nil || result != (Result{Allowed: true, Remaining: 3}) {
+		t.Errorf("store down: got %+v, %v, want an allowed call", result, err)
+	}
+
+	// A repeated key takes one token from its bucket
+	limiter := NewLimiter(NewMemoryStore(), zap.NewNop())
+	result, err = limiter.Allow(context.Background(), []string{"k", "k"}, 3, time.Minute)
+	if err != nil || result != (Result{Allowed: true, Remaining: 2}) {
+		t.Errorf("repeated key: got %+v, %v, want one token taken", result, err)
+	}
+}
+
+// failingStore fails every call like an unreachable Redis
+type failingStore struct{}
+
+func (failingStore) Take(context.Context, []string, int, time.Duration, time.Time) (Result, error) {
+	return Result{}, errors.New("connection refused")
+}
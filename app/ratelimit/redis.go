@@ -0,0 +1,91 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisKeyPrefix namespaces the bucket keys
+const redisKeyPrefix = "ratelimit:"
+
+// takeScript refills the buckets of KEYS and takes a token from each, or from none when one is empty.
+// Buckets are hashes of the token count and the last update in milliseconds,
+// and expire once they would have refilled completely.
+var takeScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local tokens = {}
+local updated = {}
+local retry = 0
+for i, key in ipairs(KEYS) do
+  local state = redis.call('HMGET', key, 'tokens', 'updated')
+  tokens[i] = tonumber(state[1])
+  updated[i] = tonumber(state[2])
+  if tokens[i] == nil or updated[i] == nil then
+    tokens[i] = capacity
+    updated[i] = now
+  end
+  if now > updated[i] then
+    tokens[i] = math.min(capacity, tokens[i] + capacity * (now - updated[i]) / window)
+    updated[i] = now
+  end
+  if tokens[i] < 1 then
+    retry = math.max(retry, math.ceil((1 - tokens[i]) * window / capacity))
+  end
+end
+
+local allowed = 0
+local remaining = 0
+if retry == 0 then
+  allowed = 1
+  remaining = capacity
+  for i = 1, #KEYS do
+    tokens[i] = tokens[i] - 1
+    remaining = math.min(remaining, math.floor(tokens[i]))
+  end
+end
+
+for i, key in ipairs(KEYS) do
+  redis.call('HSET', key, 'tokens', tostring(tokens[i]), 'updated', tostring(updated[i]))
+  redis.call('PEXPIRE', key, window)
+end
+return {allowed, remaining, retry}
+`)
+
+// RedisStore keeps buckets in Redis or a Redis compatible server, so limits hold across instances
+type RedisStore struct {
+	client redis.UniversalClient
+}
+
+// NewRedisStore creates a bucket store on the given client
+func NewRedisStore(client redis.UniversalClient) *RedisStore {
+	return &RedisStore{client: client}
+}
+
+// Take takes a token from the buckets of keys with one server side script, so no other call
+// sees them half taken. The keys of a call must hash to one slot on a Redis Cluster.
+func (s *RedisStore) Take(ctx context.Context, keys []string, limit int, window time.Duration, now time.Time) (Result, error) {
+	redisKeys := make([]string, len(keys))
+	for i, key := range keys {
+		redisKeys[i] = redisKeyPrefix + key
+	}
+	values, err := takeScript.Run(ctx, s.client, redisKeys,
+		limit, window.Milliseconds(), now.UnixMilli()).Int64Slice()
+	if err != nil {
+		return Result{}, fmt.Errorf("error taking rate limit token: %w", err)
+	}
+	if len(values) != 3 {
+		return Result{}, fmt.Errorf("unexpected rate limit script result %v", values)
+	}
+
+	return Result{
+		Allowed:    values[0] == 1,
+		Remaining:  int(values[1]),
+		RetryAfter: time.Duration(values[2]) * time.Millisecond,
+	}, nil
+}
@@ -0,0 +1,50 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// testRedisURLEnvKey points the Redis tests at a server, they are skipped without it
+const testRedisURLEnvKey = "RATELIMIT_TEST_REDIS_URL"
+
+func newTestRedisStore(t *testing.T) *RedisStore {
+	t.Helper()
+	url := os.Getenv(testRedisURLEnvKey)
+	if url == "" {
+		t.Skipf("%s is not set", testRedisURLEnvKey)
+	}
+	options, err := redis.ParseURL(url)
+	if err != nil {
+		t.Fatalf("ParseURL: %v", err)
+	}
+	client := redis.NewClient(options)
+	t.Cleanup(func() { _ = client.Close() })
+	if err := client.Ping(context.Background()).Err(); err != nil {
+		t.Fatalf("Ping: %v", err)
+	}
+	return NewRedisStore(client)
+}
+
+func TestRedisStoreRefill(t *testing.T) {
+	store := newTestRedisStore(t)
+	key := fmt.Sprintf("test:%s:%d", t.Name(), time.Now().UnixNano())
+	t.Cleanup(func() { store.client.Del(context.Background(), redisKeyPrefix+key) })
+
+	// The script counts in milliseconds
+	runTakeSteps(t, store, key, refillSteps, time.Millisecond)
+}
+
+func TestRedisStoreTakesAllOrNone(t *testing.T) {
+	store := newTestRedisStore(t)
+	a := fmt.Sprintf("test:%s:a:%d", t.Name(), time.Now().UnixNano())
+	b := fmt.Sprintf("test:%s:b:%d", t.Name(), time.Now().UnixNano())
+	t.Cleanup(func() { store.client.Del(context.Background(), redisKeyPrefix+a, redisKeyPrefix+b) })
+
+	runTakeAllOrNone(t, store, a, b, time.Millisecond)
+}
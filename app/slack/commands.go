@@ -0,0 +1,248 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/notify"
+	"sct-backend-service/app/utils"
+	"sct-backend-service/app/workflow"
+	"sct-backend-service/graph/model"
+)
+
+// commandResultLimit is the number of enquiries listed in a search reply
+const commandResultLimit = 10
+
+// commandSubjectLength caps the subject shown for each enquiry
+const commandSubjectLength = 150
+
+// statsPeriods are the periods of `stats`, by name
+var statsPeriods = map[string]struct {
+	title string
+	since func(now time.Time) time.Time
+}{
+	"today": {"today", func(now time.Time) time.Time { return now.Truncate(24 * time.Hour) }},
+	"week":  {"in the last 7 days", func(now time.Time) time.Time { return now.AddDate(0, 0, -7) }},
+	"month": {"in the last 30 days", func(now time.Time) time.Time { return now.AddDate(0, 0, -30) }},
+}
+
+// commandUsage explains the command, it is the reply to `help` and to text that cannot be parsed
+const commandUsage = "*Search enquiries*\n" +
+	"`%[1]s acme corp` finds enquiries whose reference, name, email, company, subject or message contains the text\n" +
+	"`%[1]s status:new source:SCTGULF` filters by status and source, and can be combined with text\n" +
+	"`%[1]s stats week` counts enquiries by status and source, also `today` and `month`"
+
+// CommandHandler answers the slash command for looking up enquiries, e.g. /enquiries.
+// Replies are ephemeral, only the user who typed the command sees them.
+// Requests must be verified before they reach the handler.
+type CommandHandler struct {
+	workflow  workflow.WorkflowGraphQLService
+	templates *notify.Templates
+	logger    *zap.Logger
+	now       func() time.Time
+}
+
+// NewCommandHandler creates the handler, templates provide the admin UI links of enquiries
+func NewCommandHandler(workflow workflow.WorkflowGraphQLService, templates *notify.Templates, logger *zap.Logger) *CommandHandler {
+	return &CommandHandler{
+		workflow:  workflow,
+		templates: templates,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// commandReply is an ephemeral slash command response
+type commandReply struct {
+	ResponseType string                   `json:"response_type"`
+	Text         string                   `json:"text"`
+	Blocks       []map[string]interface{} `json:"blocks,omitempty"`
+}
+
+// ServeHTTP answers the command within the request, Slack shows the response body to the user
+func (h *CommandHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	command := r.PostFormValue("command")
+	text := strings.TrimSpace(r.PostFormValue("text"))
+
+	h.logger.Info("Slack command received",
+		zap.String("command", command),
+		zap.String("text", text),
+		zap.String("slack_user", r.PostFormValue("user_id")),
+	)
+
+	reply, err := h.reply(r.Context(), command, text)
+	if err != nil {
+		h.logger.Error("Error answering Slack command", zap.String("text", text), zap.Error(err))
+		reply = textReply(":warning: Enquiries could not be loaded, please try again.")
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(reply)
+}
+
+// reply runs the command: help, stats or a search
+func (h *CommandHandler) reply(ctx context.Context, command, text string) (*commandReply, error) {
+	words := strings.Fields(text)
+	if len(words) == 0 || strings.EqualFold(words[0], "help") {
+		return textReply(fmt.Sprintf(commandUsage, command)), nil
+	}
+	if strings.EqualFold(words[0], "stats") {
+		return h.stats(ctx, command, words[1:])
+	}
+
+	filter, err := parseSearch(words)
+	if err != nil {
+		return textReply(":warning: " + err.Error() + "\n\n" + fmt.Sprintf(commandUsage, command)), nil
+	}
+	return h.search(ctx, text, filter)
+}
+
+// parseSearch turns status: and source: words into filters and searches for the other words
+func parseSearch(words []string) (*model.EnquiryFilter, error) {
+	filter := &model.EnquiryFilter{}
+	var search []string
+	for _, word := range words {
+		key, value, ok := strings.Cut(word, ":")
+		switch {
+		case ok && strings.EqualFold(key, "status"):
+			status := model.EnquiryStatus(strings.ToUpper(value))
+			if !status.IsValid() {
+				return nil, fmt.Errorf("unknown status %q", value)
+			}
+			filter.Status = &status
+		case ok && strings.EqualFold(key, "source"):
+			source := model.WebsiteSource(strings.ToUpper(value))
+			if !source.IsValid() {
+				return nil, fmt.Errorf("unknown source %q", value)
+			}
+			filter.Source = &source
+		default:
+			search = append(search, word)
+		}
+	}
+	if len(search) > 0 {
+		joined := strings.Join(search, " ")
+		filter.Search = &joined
+	}
+	return filter, nil
+}
+
+// search lists the newest enquiries matching the filter
+func (h *CommandHandler) search(ctx context.Context, text string, filter *model.EnquiryFilter) (*commandReply, error) {
+	first := commandResultLimit
+	result, err := h.workflow.Enquiries(ctx, filter, &first, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	query := "`" + notify.EscapeSlack(text) + "`"
+	if result.TotalCount == 0 {
+		return textReply("No enquiries match " + query + "."), nil
+	}
+
+	summary := fmt.Sprintf("%d enquiries match %s", result.TotalCount, query)
+	if result.TotalCount == 1 {
+		summary = "1 enquiry matches " + query
+	}
+	blocks := []map[string]interface{}{mrkdwnSection(summary)}
+	for _, edge := range result.Edges {
+		blocks = append(blocks, mrkdwnSection(h.enquiryText(edge.Node)))
+	}
+	if result.PageInfo.HasNextPage {
+		blocks = append(blocks, contextBlock(fmt.Sprintf("Showing the %d newest, narrow the search to see others.", len(result.Edges))))
+	}
+
+	return &commandReply{
+		ResponseType: "ephemeral",
+		Text:         summary,
+		Blocks:       blocks,
+	}, nil
+}
+
+// enquiryText summarizes an enquiry in a few lines of mrkdwn
+func (h *CommandHandler) enquiryText(enquiry *model.Enquiry) string {
+	reference := "*" + enquiry.ReferenceNumber + "*"
+	if url := h.templates.EnquiryURL(enquiry.ID); url != "" {
+		reference = fmt.Sprintf("*<%s|%s>*", url, enquiry.ReferenceNumber)
+	}
+
+	customer := notify.EscapeSlack(enquiry.Name)
+	if enquiry.CompanyName != "" {
+		customer += " (" + notify.EscapeSlack(enquiry.CompanyName) + ")"
+	}
+
+	details := fmt.Sprintf("%s · %s · %s", enquiry.Source, enquiry.Status, enquiry.CreatedAt.UTC().Format("2 Jan 2006 15:04 UTC"))
+	if enquiry.Assignee != nil {
+		details += " · assigned to " + notify.EscapeSlack(*enquiry.Assignee)
+	}
+
+	return fmt.Sprintf("%s %s\n%s\n%s", reference, customer,
+		notify.EscapeSlack(utils.TruncateString(enquiry.Subject, commandSubjectLength)), details)
+}
+
+// stats counts the enquiries of a period by status and source
+func (h *CommandHandler) stats(ctx context.Context, command string, args []string) (*commandReply, error) {
+	name := "week"
+	if len(args) > 0 {
+		name = strings.ToLower(args[0])
+	}
+	period, ok := statsPeriods[name]
+	if !ok || len(args) > 1 {
+		return textReply(":warning: Stats cover `today`, `week` or `month`.\n\n" + fmt.Sprintf(commandUsage, command)), nil
+	}
+
+	since := period.since(h.now().UTC())
+	stats, err := h.workflow.EnquiryStats(ctx, &model.EnquiryFilter{CreatedFrom: &since})
+	if err != nil {
+		return nil, err
+	}
+
+	summary := fmt.Sprintf("%d enquiries %s", stats.Total, period.title)
+	statusFields := make([]map[string]interface{}, 0, len(stats.ByStatus))
+	for _, count := range stats.ByStatus {
+		statusFields = append(statusFields, mrkdwnText(fmt.Sprintf("*%s*\n%d", count.Status, count.Count)))
+	}
+	sourceFields := make([]map[string]interface{}, 0, len(stats.BySource))
+	for _, count := range stats.BySource {
+		sourceFields = append(sourceFields, mrkdwnText(fmt.Sprintf("*%s*\n%d", count.Source, count.Count)))
+	}
+
+	return &commandReply{
+		ResponseType: "ephemeral",
+		Text:         summary,
+		Blocks: []map[string]interface{}{
+			mrkdwnSection(fmt.Sprintf("*%s*, since %s", summary, since.Format("2 Jan 2006 15:04 UTC"))),
+			{"type": "section", "fields": statusFields},
+			{"type": "section", "fields": sourceFields},
+		},
+	}, nil
+}
+
+// textReply is an ephemeral reply without blocks
+func textReply(text string) *commandReply {
+	return &commandReply{
+		ResponseType: "ephemeral",
+		Text:         text,
+	}
+}
+
+// mrkdwnText is a Block Kit mrkdwn text object
+func mrkdwnText(text string) map[string]interface{} {
+	return map[string]interface{}{"type": "mrkdwn", "text": text}
+}
+
+// mrkdwnSection is a section block with mrkdwn text
+func mrkdwnSection(text string) map[string]interface{} {
+	return map[string]interface{}{"type": "section", "text": mrkdwnText(text)}
+}
+
+// contextBlock is a context block with a single mrkdwn text
+func contextBlock(text string) map[string]interface{} {
+	return map[string]interface{}{"type": "context", "elements": []interface{}{mrkdwnText(text)}}
+}
@@ -0,0 +1,216 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/notify"
+	"sct-backend-service/app/workflow"
+	"sct-backend-service/graph/model"
+)
+
+// fakeEnquiries answers enquiry searches and stats with fixed results and records the filters
+type fakeEnquiries struct {
+	workflow.WorkflowGraphQLService
+	connection *model.EnquiryConnection
+	stats      *model.EnquiryStats
+	err        error
+
+	filter *model.EnquiryFilter
+	first  int
+}
+
+func (f *fakeEnquiries) Enquiries(ctx context.Context, filter *model.EnquiryFilter, first *int, after *string) (*model.EnquiryConnection, error) {
+	f.filter, f.first = filter, *first
+	return f.connection, f.err
+}
+
+func (f *fakeEnquiries) EnquiryStats(ctx context.Context, filter *model.EnquiryFilter) (*model.EnquiryStats, error) {
+	f.filter = filter
+	return f.stats, f.err
+}
+
+// runCommand sends the command text to the handler and decodes the reply
+func runCommand(t *testing.T, h *CommandHandler, text string) commandReply {
+	t.Helper()
+	form := url.Values{"command": {"/enquiries"}, "text": {text}, "user_id": {"U1"}}
+	r := httptest.NewRequest(http.MethodPost, "/slack/commands", strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, r)
+
+	var reply commandReply
+	if err := json.NewDecoder(w.Body).Decode(&reply); err != nil {
+		t.Fatalf("decoding reply: %v", err)
+	}
+	if reply.ResponseType != "ephemeral" {
+		t.Errorf("response_type = %q, want ephemeral", reply.ResponseType)
+	}
+	return reply
+}
+
+// blockTexts returns the text of every block of the reply
+func blockTexts(reply commandReply) []string {
+	var texts []string
+	for _, block := range reply.Blocks {
+		if text, ok := block["text"].(map[string]interface{}); ok {
+			texts = append(texts, text["text"].(string))
+		}
+		if elements, ok := block["elements"].([]interface{}); ok {
+			for _, element := range elements {
+				texts = append(texts, element.(map[string]interface{})["text"].(string))
+			}
+		}
+	}
+	return texts
+}
+
+func newCommandHandler(t *testing.T, fake *fakeEnquiries) *CommandHandler {
+	t.Helper()
+	templates, err := notify.NewTemplates("", "https://admin.example.com/enquiries/{id}", nil)
+	if err != nil {
+		t.Fatalf("NewTemplates: %v", err)
+	}
+	return NewCommandHandler(fake, templates, zap.NewNop())
+}
+
+func TestParseSearch(t *testing.T) {
+	status, source, search, reference := model.EnquiryStatusNew, model.WebsiteSourceSctgulf, "acme corp", "ref:SCT-1001"
+	tests := []struct {
+		name    string
+		words   []string
+		want    *model.EnquiryFilter
+		wantErr string
+	}{
+		{"text", []string{"acme", "corp"}, &model.EnquiryFilter{Search: &search}, ""},
+		{"filters ignore case", []string{"Status:new", "SOURCE:sctgulf"}, &model.EnquiryFilter{Status: &status, Source: &source}, ""},
+		{"filters and text", []string{"acme", "status:NEW", "corp"}, &model.EnquiryFilter{Status: &status, Search: &search}, ""},
+		{"other keys are text", []string{"ref:SCT-1001"}, &model.EnquiryFilter{Search: &reference}, ""},
+		{"unknown status", []string{"status:closed"}, nil, `unknown status "closed"`},
+		{"unknown source", []string{"source:acme"}, nil, `unknown source "acme"`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, err := parseSearch(tt.words)
+			if tt.wantErr != "" {
+				if err == nil || err.Error() != tt.wantErr {
+					t.Errorf("parseSearch = %v, want error %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseSearch: %v", err)
+			}
+			if !reflect.DeepEqual(filter, tt.want) {
+				t.Errorf("filter = %+v, want %+v", filter, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandHandlerSearch(t *testing.T) {
+	assignee := "Priya"
+	created := time.Date(2026, 10, 18, 9, 30, 0, 0, time.UTC)
+	fake := &fakeEnquiries{connection: &model.EnquiryConnection{
+		TotalCount: 12,
+		PageInfo:   &model.PageInfo{HasNextPage: true},
+		Edges: []*model.EnquiryEdge{
+			{Node: &model.Enquiry{ID: "enquiry-2", ReferenceNumber: "SCT-1002", Source: model.WebsiteSourceSctgulf, Status: model.EnquiryStatusNew,
+				Name: "<!channel>", CompanyName: "Acme & Co", Subject: "RFQ pumps", CreatedAt: created}},
+			{Node: &model.Enquiry{ID: "enquiry-1", ReferenceNumber: "SCT-1001", Source: model.WebsiteSourceSctgulf, Status: model.EnquiryStatusNew,
+				Name: "Jane Doe", Subject: "Valves", Assignee: &assignee, CreatedAt: created}},
+		},
+	}}
+	reply := runCommand(t, newCommandHandler(t, fake), "status:new acme")
+
+	if fake.first != commandResultLimit || fake.filter.Status == nil || *fake.filter.Status != model.EnquiryStatusNew || *fake.filter.Search != "acme" {
+		t.Errorf("searched with %+v, first %d", fake.filter, fake.first)
+	}
+	if reply.Text != "12 enquiries match `status:new acme`" {
+		t.Errorf("text = %q", reply.Text)
+	}
+	want := []string{
+		"12 enquiries match `status:new acme`",
+		"*<https://admin.example.com/enquiries/enquiry-2|SCT-1002>* &lt;!channel&gt; (Acme &amp; Co)\nRFQ pumps\nSCTGULF · NEW · 18 Oct 2026 09:30 UTC",
+		"*<https://admin.example.com/enquiries/enquiry-1|SCT-1001>* Jane Doe\nValves\nSCTGULF · NEW · 18 Oct 2026 09:30 UTC · assigned to Priya",
+		"Showing the 2 newest, narrow the search to see others.",
+	}
+	if got := blockTexts(reply); !reflect.DeepEqual(got, want) {
+		t.Errorf("blocks =\n%q\nwant\n%q", got, want)
+	}
+}
+
+func TestCommandHandlerReplies(t *testing.T) {
+	usage := fmt.Sprintf(commandUsage, "/enquiries")
+	tests := []struct {
+		name string
+		text string
+		err  error
+		want string
+	}{
+		{"no text", "", nil, usage},
+		{"help", "HELP", nil, usage},
+		{"invalid filter", "status:closed", nil, ":warning: unknown status \"closed\"\n\n" + usage},
+		{"invalid stats period", "stats year", nil, ":warning: Stats cover `today`, `week` or `month`.\n\n" + usage},
+		{"no matches", "globex", nil, "No enquiries match `globex`."},
+		{"failure", "globex", errors.New("database down"), ":warning: Enquiries could not be loaded, please try again."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := &fakeEnquiries{connection: &model.EnquiryConnection{PageInfo: &model.PageInfo{}}, err: tt.err}
+			reply := runCommand(t, newCommandHandler(t, fake), tt.text)
+			if reply.Text != tt.want {
+				t.Errorf("text = %q, want %q", reply.Text, tt.want)
+			}
+		})
+	}
+}
+
+func TestCommandHandlerStats(t *testing.T) {
+	now := time.Date(2026, 10, 18, 15, 45, 0, 0, time.UTC)
+	tests := []struct {
+		text      string
+		wantSince time.Time
+		wantText  string
+	}{
+		{"stats", now.AddDate(0, 0, -7), "3 enquiries in the last 7 days"},
+		{"stats today", time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC), "3 enquiries today"},
+		{"stats Month", now.AddDate(0, 0, -30), "3 enquiries in the last 30 days"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.text, func(t *testing.T) {
+			fake := &fakeEnquiries{stats: &model.EnquiryStats{
+				Total:    3,
+				ByStatus: []*model.EnquiryStatusCount{{Status: model.EnquiryStatusNew, Count: 2}, {Status: model.EnquiryStatusSpam, Count: 1}},
+				BySource: []*model.EnquirySourceCount{{Source: model.WebsiteSourceSctgulf, Count: 3}},
+			}}
+			h := newCommandHandler(t, fake)
+			h.now = func() time.Time { return now }
+			reply := runCommand(t, h, tt.text)
+
+			if fake.filter.CreatedFrom == nil || !fake.filter.CreatedFrom.Equal(tt.wantSince) {
+				t.Errorf("counted from %v, want %v", fake.filter.CreatedFrom, tt.wantSince)
+			}
+			if reply.Text != tt.wantText {
+				t.Errorf("text = %q, want %q", reply.Text, tt.wantText)
+			}
+			if len(reply.Blocks) != 3 {
+				t.Fatalf("blocks = %v, want a summary, the statuses and the sources", reply.Blocks)
+			}
+			if fields := reply.Blocks[1]["fields"].([]interface{}); len(fields) != 2 || fields[0].(map[string]interface{})["text"] != "*NEW*\n2" {
+				t.Errorf("status fields = %v", fields)
+			}
+		})
+	}
+}
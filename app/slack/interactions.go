@@ -0,0 +1,236 @@
+package slack
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/workflow"
+	"sct-backend-service/graph/model"
+	"sct-backend-service/internal/middleware"
+)
+
+// Action IDs of the interactive elements of the enquiry message, see the slack.json.tmpl template
+const (
+	ActionClaim     = "enquiry_claim"
+	ActionContacted = "enquiry_contacted"
+	ActionSpam      = "enquiry_spam"
+	ActionAssign    = "enquiry_assign"
+)
+
+// enquiryActions are the actions the handler applies
+var enquiryActions = map[string]bool{
+	ActionClaim:     true,
+	ActionContacted: true,
+	ActionSpam:      true,
+	ActionAssign:    true,
+}
+
+// EnquiryBlockPrefix starts the block_id of the actions block, the enquiry ID follows it
+const EnquiryBlockPrefix = "enquiry:"
+
+// responseTimeout bounds the call to the response URL
+const responseTimeout = 3 * time.Second
+
+// interactionTimeout bounds applying an acknowledged interaction and replying to it
+const interactionTimeout = 15 * time.Second
+
+// UserDirectory looks up the names of Slack users
+type UserDirectory interface {
+	UserName(ctx context.Context, userID string) (string, error)
+}
+
+// interactionPayload holds the fields of a block_actions payload the handler uses
+type interactionPayload struct {
+	Type string `json:"type"`
+	User struct {
+		ID       string `json:"id"`
+		Username string `json:"username"`
+		Name     string `json:"name"`
+	} `json:"user"`
+	ResponseURL string        `json:"response_url"`
+	Actions     []blockAction `json:"actions"`
+}
+
+// blockAction is a button click or menu selection in a message
+type blockAction struct {
+	ActionID     string `json:"action_id"`
+	BlockID      string `json:"block_id"`
+	SelectedUser string `json:"selected_user"`
+}
+
+// InteractionHandler applies the buttons of enquiry messages: claim, mark contacted, mark spam and assign.
+// Changes go through the workflow like admin API calls and are made in the name of the Slack user who clicked.
+// The user gets an ephemeral reply saying what happened. Requests must be verified before they reach the handler.
+type InteractionHandler struct {
+	workflow workflow.WorkflowGraphQLService
+	users    UserDirectory
+	client   *http.Client
+	logger   *zap.Logger
+
+	// pending counts the interactions acknowledged but not yet applied
+	pending sync.WaitGroup
+}
+
+// NewInteractionHandler creates the handler. users may be nil, Slack users are then named by their username.
+func NewInteractionHandler(workflow workflow.WorkflowGraphQLService, users UserDirectory, client *http.Client, logger *zap.Logger) *InteractionHandler {
+	return &InteractionHandler{
+		workflow: workflow,
+		users:    users,
+		client:   client,
+		logger:   logger,
+	}
+}
+
+// ServeHTTP handles an interaction request. Slack needs a 200 within 3 seconds, so the request is acknowledged
+// at once and the action is applied in the background, the result is sent to the response URL.
+func (h *InteractionHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var payload interactionPayload
+	if err := json.Unmarshal([]byte(r.PostFormValue("payload")), &payload); err != nil {
+		http.Error(w, "invalid payload", http.StatusBadRequest)
+		return
+	}
+
+	// Link buttons such as "Reply to Customer" also send an interaction, they need no handling
+	if payload.Type != "block_actions" || len(payload.Actions) == 0 {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+	action := payload.Actions[0]
+	enquiryID, ok := strings.CutPrefix(action.BlockID, EnquiryBlockPrefix)
+	if !ok || !enquiryActions[action.ActionID] {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	h.logger.Info("Slack interaction received",
+		zap.String("action", action.ActionID),
+		zap.String("enquiry_id", enquiryID),
+		zap.String("slack_user", payload.User.ID),
+	)
+	w.WriteHeader(http.StatusOK)
+
+	// The work outlives the request, it keeps the request values but not its cancellation
+	ctx := context.WithoutCancel(r.Context())
+	h.pending.Add(1)
+	go func() {
+		defer h.pending.Done()
+		defer func() {
+			if r := recover(); r != nil {
+				h.logger.Error("Panic while applying Slack interaction", zap.String("enquiry_id", enquiryID), zap.Any("panic", r))
+			}
+		}()
+
+		ctx, cancel := context.WithTimeout(ctx, interactionTimeout)
+		defer cancel()
+		actor := h.userName(ctx, payload.User.ID, firstNonEmpty(payload.User.Name, payload.User.Username))
+		ctx = middleware.WithUserID(ctx, actor)
+		h.respond(ctx, payload.ResponseURL, h.apply(ctx, payload.User.ID, actor, action, enquiryID))
+	}()
+}
+
+// Wait blocks until the acknowledged interactions have been applied and answered
+func (h *InteractionHandler) Wait() {
+	h.pending.Wait()
+}
+
+// apply runs the action through the workflow and returns the reply for the user
+func (h *InteractionHandler) apply(ctx context.Context, userID, actor string, action blockAction, enquiryID string) string {
+	var result *model.Enquiry
+	var err error
+	var done string
+
+	switch action.ActionID {
+	case ActionClaim:
+		result, err = h.workflow.AssignEnquiry(ctx, model.AssignEnquiryInput{ID: enquiryID, Assignee: actor, SlackUserID: &userID})
+		done = "You claimed enquiry %s."
+	case ActionContacted:
+		result, err = h.workflow.UpdateEnquiryStatus(ctx, model.UpdateEnquiryStatusInput{ID: enquiryID, Status: model.EnquiryStatusContacted, Reason: "Marked as contacted in Slack"})
+		done = "Enquiry %s is marked as contacted."
+	case ActionSpam:
+		result, err = h.workflow.UpdateEnquiryStatus(ctx, model.UpdateEnquiryStatusInput{ID: enquiryID, Status: model.EnquiryStatusSpam, Reason: "Marked as spam in Slack"})
+		done = "Enquiry %s is marked as spam."
+	case ActionAssign:
+		assignee := h.userName(ctx, action.SelectedUser, action.SelectedUser)
+		result, err = h.workflow.AssignEnquiry(ctx, model.AssignEnquiryInput{ID: enquiryID, Assignee: assignee, SlackUserID: &action.SelectedUser})
+		done = "Enquiry %s is assigned to <@" + action.SelectedUser + ">."
+	}
+
+	if err != nil {
+		var transitionErr *workflow.StatusTransitionError
+		if errors.As(err, &transitionErr) {
+			return ":warning: The enquiry cannot be updated: " + transitionErr.Error() + "."
+		}
+		h.logger.Error("Error applying Slack interaction",
+			zap.String("action", action.ActionID),
+			zap.String("enquiry_id", enquiryID),
+			zap.Error(err),
+		)
+		return ":warning: The enquiry could not be updated, please try again."
+	}
+	return fmt.Sprintf(done, result.ReferenceNumber)
+}
+
+// respond posts an ephemeral message to the response URL of the interaction, only the clicking user sees it
+func (h *InteractionHandler) respond(ctx context.Context, responseURL, text string) {
+	if responseURL == "" {
+		return
+	}
+	body, err := json.Marshal(map[string]interface{}{
+		"response_type":    "ephemeral",
+		"replace_original": false,
+		"text":             text,
+	})
+	if err != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, responseTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, responseURL, bytes.NewReader(body))
+	if err != nil {
+		h.logger.Error("Error creating Slack response", zap.Error(err))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		h.logger.Error("Error sending Slack response", zap.Error(err))
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		h.logger.Error("Slack response was rejected", zap.Int("status_code", resp.StatusCode))
+	}
+}
+
+// userName looks the user up in the directory, falling back to the given name and then the user ID
+func (h *InteractionHandler) userName(ctx context.Context, userID, fallback string) string {
+	if h.users != nil && userID != "" {
+		name, err := h.users.UserName(ctx, userID)
+		if err == nil {
+			return name
+		}
+		h.logger.Warn("Error looking up Slack user", zap.String("slack_user", userID), zap.Error(err))
+	}
+	return firstNonEmpty(fallback, userID)
+}
+
+// firstNonEmpty returns the first value that is not empty
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,248 @@
+package slack
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/workflow"
+	"sct-backend-service/graph/model"
+	"sct-backend-service/internal/middleware"
+)
+
+// fakeWorkflow records the enquiry changes made through it, blocking each until proceed is closed
+type fakeWorkflow struct {
+	workflow.WorkflowGraphQLService
+	proceed chan struct{}
+	err     error
+
+	calls   chan string
+	actor   string
+	ctxErr  error
+	payload any
+}
+
+func newFakeWorkflow() *fakeWorkflow {
+	proceed := make(chan struct{})
+	close(proceed)
+	return &fakeWorkflow{proceed: proceed, calls: make(chan string, 1)}
+}
+
+func (f *fakeWorkflow) record(ctx context.Context, call string, input any) (*model.Enquiry, error) {
+	f.calls <- call
+	<-f.proceed
+	f.actor, _ = middleware.GetUserID(ctx)
+	f.ctxErr = ctx.Err()
+	f.payload = input
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &model.Enquiry{ID: "enquiry-1", ReferenceNumber: "SCT-1001"}, nil
+}
+
+func (f *fakeWorkflow) AssignEnquiry(ctx context.Context, input model.AssignEnquiryInput) (*model.Enquiry, error) {
+	return f.record(ctx, "assign", input)
+}
+
+func (f *fakeWorkflow) UpdateEnquiryStatus(ctx context.Context, input model.UpdateEnquiryStatusInput) (*model.Enquiry, error) {
+	return f.record(ctx, "status", input)
+}
+
+// fakeUsers names Slack users by ID
+type fakeUsers map[string]string
+
+func (u fakeUsers) UserName(ctx context.Context, userID string) (string, error) {
+	if name, ok := u[userID]; ok {
+		return name, nil
+	}
+	return "", errors.New("user_not_found")
+}
+
+// responseRecorder is a response URL that hands the posted replies to the test
+func responseRecorder(t *testing.T) (*httptest.Server, chan map[string]any) {
+	t.Helper()
+	replies := make(chan map[string]any, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var reply map[string]any
+		if err := json.NewDecoder(r.Body).Decode(&reply); err != nil {
+			t.Errorf("decoding reply: %v", err)
+		}
+		replies <- reply
+	}))
+	t.Cleanup(server.Close)
+	return server, replies
+}
+
+// interactionRequest is a block_actions request for the action on enquiry-1
+func interactionRequest(ctx context.Context, actionID, responseURL string) *http.Request {
+	payload := fmt.Sprintf(`{"type":"block_actions","user":{"id":"U1","username":"jane","name":"Jane"},"response_url":%q,`+
+		`"actions":[{"action_id":%q,"block_id":"enquiry:enquiry-1","selected_user":"U2"}]}`, responseURL, actionID)
+	r := httptest.NewRequestWithContext(ctx, http.MethodPost, "/slack/interactions", strings.NewReader(url.Values{"payload": {payload}}.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestInteractionHandlerAcknowledgesBeforeApplying(t *testing.T) {
+	server, replies := responseRecorder(t)
+	fake := newFakeWorkflow()
+	fake.proceed = make(chan struct{})
+	handler := NewInteractionHandler(fake, fakeUsers{"U1": "Jane Doe"}, server.Client(), zap.NewNop())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w := httptest.NewRecorder()
+	served := make(chan struct{})
+	go func() {
+		defer close(served)
+		handler.ServeHTTP(w, interactionRequest(ctx, ActionClaim, server.URL))
+	}()
+
+	// The request is answered while the claim is still in progress and then ends
+	if call := <-fake.calls; call != "assign" {
+		t.Fatalf("workflow call = %s, want assign", call)
+	}
+	select {
+	case <-served:
+	case <-time.After(time.Second):
+		close(fake.proceed)
+		t.Fatal("the request was not answered while the claim was in progress")
+	}
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, want 200", w.Code)
+	}
+	cancel()
+	close(fake.proceed)
+	handler.Wait()
+
+	if fake.ctxErr != nil {
+		t.Errorf("the claim ran with a context ended by the request: %v", fake.ctxErr)
+	}
+	if fake.actor != "Jane Doe" {
+		t.Errorf("claim made by %q, want the directory name Jane Doe", fake.actor)
+	}
+	select {
+	case reply := <-replies:
+		if reply["text"] != "You claimed enquiry SCT-1001." || reply["response_type"] != "ephemeral" {
+			t.Errorf("reply = %v", reply)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("no reply was posted to the response URL")
+	}
+}
+
+func TestInteractionHandlerActions(t *testing.T) {
+	tests := []struct {
+		name      string
+		actionID  string
+		err       error
+		wantInput any
+		wantText  string
+	}{
+		{
+			name:      "claim",
+			actionID:  ActionClaim,
+			wantInput: model.AssignEnquiryInput{ID: "enquiry-1", Assignee: "Jane", SlackUserID: ptr("U1")},
+			wantText:  "You claimed enquiry SCT-1001.",
+		},
+		{
+			name:      "contacted",
+			actionID:  ActionContacted,
+			wantInput: model.UpdateEnquiryStatusInput{ID: "enquiry-1", Status: model.EnquiryStatusContacted, Reason: "Marked as contacted in Slack"},
+			wantText:  "Enquiry SCT-1001 is marked as contacted.",
+		},
+		{
+			name:      "spam",
+			actionID:  ActionSpam,
+			wantInput: model.UpdateEnquiryStatusInput{ID: "enquiry-1", Status: model.EnquiryStatusSpam, Reason: "Marked as spam in Slack"},
+			wantText:  "Enquiry SCT-1001 is marked as spam.",
+		},
+		{
+			name:      "assign",
+			actionID:  ActionAssign,
+			wantInput: model.AssignEnquiryInput{ID: "enquiry-1", Assignee: "U2", SlackUserID: ptr("U2")},
+			wantText:  "Enquiry SCT-1001 is assigned to <@U2>.",
+		},
+		{
+			name:     "transition not allowed",
+			actionID: ActionContacted,
+			err:      &workflow.StatusTransitionError{From: model.EnquiryStatusSpam, To: model.EnquiryStatusContacted},
+			wantText: ":warning: The enquiry cannot be updated: cannot change enquiry status from SPAM to CONTACTED.",
+		},
+		{
+			name:     "failure",
+			actionID: ActionClaim,
+			err:      errors.New("database down"),
+			wantText: ":warning: The enquiry could not be updated, please try again.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, replies := responseRecorder(t)
+			fake := newFakeWorkflow()
+			fake.err = tt.err
+			handler := NewInteractionHandler(fake, nil, server.Client(), zap.NewNop())
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, interactionRequest(context.Background(), tt.actionID, server.URL))
+			handler.Wait()
+
+			if w.Code != http.StatusOK {
+				t.Errorf("status = %d, want 200", w.Code)
+			}
+			if tt.wantInput != nil {
+				got, _ := json.Marshal(fake.payload)
+				want, _ := json.Marshal(tt.wantInput)
+				if string(got) != string(want) {
+					t.Errorf("workflow input = %s, want %s", got, want)
+				}
+			}
+			if reply := <-replies; reply["text"] != tt.wantText {
+				t.Errorf("reply = %q, want %q", reply["text"], tt.wantText)
+			}
+		})
+	}
+}
+
+func TestInteractionHandlerIgnoresOtherPayloads(t *testing.T) {
+	tests := []struct {
+		name    string
+		payload string
+		want    int
+	}{
+		{"invalid payload", "{", http.StatusBadRequest},
+		{"other type", `{"type":"view_submission"}`, http.StatusOK},
+		{"link button", `{"type":"block_actions","actions":[{"action_id":"enquiry_reply","block_id":"enquiry:enquiry-1"}]}`, http.StatusOK},
+		{"other block", `{"type":"block_actions","actions":[{"action_id":"enquiry_claim","block_id":"survey:1"}]}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := newFakeWorkflow()
+			handler := NewInteractionHandler(fake, nil, http.DefaultClient, zap.NewNop())
+			r := httptest.NewRequest(http.MethodPost, "/slack/interactions", strings.NewReader(url.Values{"payload": {tt.payload}}.Encode()))
+			r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, r)
+			handler.Wait()
+
+			if w.Code != tt.want {
+				t.Errorf("status = %d, want %d", w.Code, tt.want)
+			}
+			if len(fake.calls) != 0 {
+				t.Error("the workflow was called")
+			}
+		})
+	}
+}
+
+func ptr(s string) *string {
+	return &s
+}
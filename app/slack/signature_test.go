@@ -0,0 +1,112 @@
+package slack
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// The signed request from Slack's "Verifying requests from Slack" guide
+const (
+	testSigningSecret = "8f742231b10e8888abcd99yyyzzz85a5"
+	testTimestamp     = "1531420618"
+	testSignature     = "v0=a2114d57b48eac39b9ad189dd8316235a7b4a8d21a10bd27519666489c69b503"
+	testBody          = "token=xyzz0WbapA4vBCDEFasx0q6G&team_id=T1DC2JH3J&team_domain=testteamnow&channel_id=G8PSS9T3V&channel_name=foobar&user_id=U2CERLKJA&user_name=roadrunner&command=%2Fwebhook-collect&text=&response_url=https%3A%2F%2Fhooks.slack.com%2Fcommands%2FT1DC2JH3J%2F397700885554%2F96rGlfmibIGlgcZRskXaIFfN&trigger_id=398738663015.47445629121.803a0bc887a14d10d2c447fce8b6703c"
+)
+
+// testVerifier verifies with secret at the given offset from the guide's request timestamp
+func testVerifier(secret string, offset time.Duration) *Verifier {
+	verifier := NewVerifier(secret)
+	verifier.now = func() time.Time { return time.Unix(1531420618, 0).Add(offset) }
+	return verifier
+}
+
+// signedHeader sets the headers Slack signs a request with
+func signedHeader(timestamp, signature string) http.Header {
+	header := http.Header{}
+	header.Set("X-Slack-Request-Timestamp", timestamp)
+	header.Set("X-Slack-Signature", signature)
+	return header
+}
+
+func TestVerifierVerify(t *testing.T) {
+	unprefixed := strings.TrimPrefix(testSignature, "v0=")
+	tampered := strings.Replace(testBody, "roadrunner", "coyote", 1)
+	tests := []struct {
+		name      string
+		secret    string
+		offset    time.Duration
+		timestamp string
+		signature string
+		body      string
+		want      error
+	}{
+		{"valid signature", testSigningSecret, 0, testTimestamp, testSignature, testBody, nil},
+		{"valid at the edge of the window", testSigningSecret, 5 * time.Minute, testTimestamp, testSignature, testBody, nil},
+		{"tampered body", testSigningSecret, 0, testTimestamp, testSignature, tampered, ErrInvalidSignature},
+		{"wrong secret", "not-the-signing-secret", 0, testTimestamp, testSignature, testBody, ErrInvalidSignature},
+		{"missing v0 prefix", testSigningSecret, 0, testTimestamp, unprefixed, testBody, ErrInvalidSignature},
+		{"other version", testSigningSecret, 0, testTimestamp, "v1=" + unprefixed, testBody, ErrInvalidSignature},
+		{"non-hex signature", testSigningSecret, 0, testTimestamp, "v0=not-hex-at-all", testBody, ErrInvalidSignature},
+		{"missing signature", testSigningSecret, 0, testTimestamp, "", testBody, ErrInvalidSignature},
+		{"missing timestamp", testSigningSecret, 0, "", testSignature, testBody, ErrInvalidSignature},
+		{"timestamp over 5 minutes in the past", testSigningSecret, 5*time.Minute + time.Second, testTimestamp, testSignature, testBody, ErrStaleRequest},
+		{"timestamp over 5 minutes in the future", testSigningSecret, -5*time.Minute - time.Second, testTimestamp, testSignature, testBody, ErrStaleRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := testVerifier(tt.secret, tt.offset).Verify(signedHeader(tt.timestamp, tt.signature), []byte(tt.body))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("Verify = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifierMiddleware(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		signature string
+		offset    time.Duration
+		want      int
+	}{
+		{name: "valid request", method: http.MethodPost, signature: testSignature, want: http.StatusOK},
+		{name: "invalid signature", method: http.MethodPost, signature: "v0=00", want: http.StatusUnauthorized},
+		{name: "stale request", method: http.MethodPost, signature: testSignature, offset: time.Hour, want: http.StatusUnauthorized},
+		{name: "not a POST", method: http.MethodGet, signature: testSignature, want: http.StatusMethodNotAllowed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			called := false
+			next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				called = true
+				body, err := io.ReadAll(r.Body)
+				if err != nil {
+					t.Errorf("reading body in next handler: %v", err)
+				}
+				got = string(body)
+			})
+
+			request := httptest.NewRequest(tt.method, "/slack/commands", strings.NewReader(testBody))
+			request.Header = signedHeader(testTimestamp, tt.signature)
+			recorder := httptest.NewRecorder()
+			testVerifier(testSigningSecret, tt.offset).Middleware(next).ServeHTTP(recorder, request)
+
+			if recorder.Code != tt.want {
+				t.Errorf("status = %d, want %d", recorder.Code, tt.want)
+			}
+			if called != (tt.want == http.StatusOK) {
+				t.Fatalf("next handler called = %v, want %v", called, tt.want == http.StatusOK)
+			}
+			if called && got != testBody {
+				t.Errorf("next handler read body %q, want the original body", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package spam
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Scores of the built-in checks. A single strong signal reaches the default threshold on its own,
+// weak signals only do in combination.
+const (
+	strongSignalScore = 10
+	linkScore         = 2
+	keywordScore      = 2
+	gibberishScore    = 3
+)
+
+// HoneypotCheck flags submissions that filled in the hidden honeypot field.
+// Real visitors never see the field, form-filling bots do.
+type HoneypotCheck struct{}
+
+// Name identifies the check
+func (HoneypotCheck) Name() string {
+	return "honeypot"
+}
+
+// Check flags a non-empty honeypot
+func (HoneypotCheck) Check(ctx context.Context, submission Submission) Signal {
+	if submission.Contact.Honeypot == nil || strings.TrimSpace(*submission.Contact.Honeypot) == "" {
+		return Signal{}
+	}
+	return Signal{Score: strongSignalScore, Reason: "honeypot field filled in"}
+}
+
+var linkRegex = regexp.MustCompile(`(?i)\b(?:https?://|www\.)\S+`)
+
+// LinkDensityCheck flags submissions with more links than a genuine enquiry would carry
+type LinkDensityCheck struct {
+	maxLinks int
+}
+
+// NewLinkDensityCheck allows up to maxLinks links in the message before scoring
+func NewLinkDensityCheck(maxLinks int) *LinkDensityCheck {
+	return &LinkDensityCheck{maxLinks: maxLinks}
+}
+
+// Name identifies the check
+func (c *LinkDensityCheck) Name() string {
+	return "link_density"
+}
+
+// Check scores every link above the allowance; links in the name or company never belong there
+func (c *LinkDensityCheck) Check(ctx context.Context, submission Submission) Signal {
+	contact := submission.Contact
+	if linkRegex.MatchString(contact.Name) || linkRegex.MatchString(contact.CompanyName) {
+		return Signal{Score: strongSignalScore, Reason: "link in name or company"}
+	}
+
+	links := len(linkRegex.FindAllString(contact.Subject+" "+contact.Message, -1))
+	if links <= c.maxLinks {
+		return Signal{}
+	}
+	return Signal{
+		Score:  float64(links-c.maxLinks) * linkScore,
+		Reason: fmt.Sprintf("%d links in message", links),
+	}
+}
+
+// KeywordCheck flags submissions mentioning typical spam phrases
+type KeywordCheck struct {
+	keywords []string
+}
+
+// NewKeywordCheck matches the keywords case-insensitively
+func NewKeywordCheck(keywords []string) *KeywordCheck {
+	lowered := make([]string, 0, len(keywords))
+	for _, keyword := range keywords {
+		if keyword = strings.ToLower(strings.TrimSpace(keyword)); keyword != "" {
+			lowered = append(lowered, keyword)
+		}
+	}
+	return &KeywordCheck{keywords: lowered}
+}
+
+// Name identifies the check
+func (c *KeywordCheck) Name() string {
+	return "keywords"
+}
+
+// Check scores every distinct keyword found in the subject or message
+func (c *KeywordCheck) Check(ctx context.Context, submission Submission) Signal {
+	text := strings.ToLower(submission.Contact.Subject + " " + submission.Contact.Message)
+	var found []string
+	for _, keyword := range c.keywords {
+		if strings.Contains(text, keyword) {
+			found = append(found, keyword)
+		}
+	}
+	if len(found) == 0 {
+		return Signal{}
+	}
+	return Signal{
+		Score:  float64(len(found)) * keywordScore,
+		Reason: "spam keywords: " + strings.Join(found, ", "),
+	}
+}
+
+// EmailDomainCheck flags email addresses at blocked domains, including their subdomains
+type EmailDomainCheck struct {
+	domains map[string]struct{}
+}
+
+// NewEmailDomainCheck blocks the given domains
+func NewEmailDomainCheck(domains []string) *EmailDomainCheck {
+	set := make(map[string]struct{}, len(domains))
+	for _, domain := range domains {
+		if domain = strings.ToLower(strings.TrimSpace(domain)); domain != "" {
+			set[domain] = struct{}{}
+		}
+	}
+	return &EmailDomainCheck{domains: set}
+}
+
+// Name identifies the check
+func (c *EmailDomainCheck) Name() string {
+	return "email_domain"
+}
+
+// Check looks the email domain and its parent domains up in the blocklist
+func (c *EmailDomainCheck) Check(ctx context.Context, submission Submission) Signal {
+	at := strings.LastIndex(submission.Contact.Email, "@")
+	if at < 0 {
+		return Signal{}
+	}
+	domain := strings.ToLower(strings.TrimSpace(submission.Contact.Email[at+1:]))
+	for candidate := domain; candidate != ""; {
+		if _, blocked := c.domains[candidate]; blocked {
+			return Signal{Score: strongSignalScore, Reason: "blocked email domain " + domain}
+		}
+		_, parent, ok := strings.Cut(candidate, ".")
+		if !ok {
+			break
+		}
+		candidate = parent
+	}
+	return Signal{}
+}
+
+// GibberishCheck flags names and messages made of random keyboard mashing.
+// It only looks at Latin words, other scripts are never flagged.
+type GibberishCheck struct{}
+
+// Name identifies the check
+func (GibberishCheck) Name() string {
+	return "gibberish"
+}
+
+// Check scores a gibberish name and a mostly gibberish message separately
+func (GibberishCheck) Check(ctx context.Context, submission Submission) Signal {
+	signal := Signal{}
+	var reasons []string
+	if gibberishRatio(submission.Contact.Name) >= 0.5 {
+		signal.Score += gibberishScore
+		reasons = append(reasons, "name")
+	}
+	if gibberishRatio(submission.Contact.Message) >= 0.5 {
+		signal.Score += gibberishScore
+		reasons = append(reasons, "message")
+	}
+	if signal.Score > 0 {
+		signal.Reason = "gibberish " + strings.Join(reasons, " and ")
+	}
+	return signal
+}
+
+// gibberishRatio returns the share of Latin words of six or more letters that look random
+func gibberishRatio(text string) float64 {
+	words, gibberish := 0, 0
+	for _, word := range strings.FieldsFunc(text, func(r rune) bool { return !unicode.IsLetter(r) }) {
+		if len(word) < 6 || !isASCIIWord(word) {
+			continue
+		}
+		words++
+		if looksRandom(word) {
+			gibberish++
+		}
+	}
+	if words == 0 {
+		return 0
+	}
+	return float64(gibberish) / float64(words)
+}
+
+func isASCIIWord(word string) bool {
+	for _, r := range word {
+		if r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+// looksRandom spots words with too few vowels, long consonant runs or random capitalisation
+func looksRandom(word string) bool {
+	vowels, run, longestRun, caseFlips := 0, 0, 0, 0
+	for i, r := range word {
+		if strings.ContainsRune("aeiouyAEIOUY", r) {
+			vowels++
+			run = 0
+		} else {
+			run++
+			longestRun = max(longestRun, run)
+		}
+		// A capital first letter is not a case change, a lower-case one followed by a capital is
+		if i > 0 && unicode.IsUpper(r) != unicode.IsUpper(rune(word[i-1])) && (i > 1 || unicode.IsUpper(r)) {
+			caseFlips++
+		}
+	}
+	return float64(vowels)/float64(len(word)) < 0.15 || longestRun >= 6 || caseFlips >= 3
+}
+
+// IPReputationCheck flags requests from listed addresses or networks
+type IPReputationCheck struct {
+	networks []*net.IPNet
+}
+
+// NewIPReputationCheck parses the entries as single IP addresses or CIDR networks
+func NewIPReputationCheck(entries []string) (*IPReputationCheck, error) {
+	check := &IPReputationCheck{}
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid blocked IP %q", entry)
+			}
+			bits := 8 * len(ip.To16())
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			check.networks = append(check.networks, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid blocked network %q: %w", entry, err)
+		}
+		check.networks = append(check.networks, network)
+	}
+	return check, nil
+}
+
+// Name identifies the check
+func (c *IPReputationCheck) Name() string {
+	return "ip_reputation"
+}
+
+// Check looks the client IP up in the list
+func (c *IPReputationCheck) Check(ctx context.Context, submission Submission) Signal {
+	ip := net.ParseIP(submission.ClientIP)
+	if ip == nil {
+		return Signal{}
+	}
+	for _, network := range c.networks {
+		if network.Contains(ip) {
+			return Signal{Score: strongSignalScore, Reason: "blocked IP " + submission.ClientIP}
+		}
+	}
+	return Signal{}
+}
@@ -0,0 +1,96 @@
+package spam
+
+import (
+	"context"
+	"testing"
+
+	"sct-backend-service/graph/model"
+)
+
+// submission wraps the contact as it arrives from the given client
+func submission(contact model.ContactInfoInput, clientIP string) Submission {
+	return Submission{Source: model.WebsiteSourceSctgulf, Contact: &contact, ClientIP: clientIP}
+}
+
+func TestChecks(t *testing.T) {
+	filled, blank := "http://bot.example", "  "
+	ipCheck, err := NewIPReputationCheck([]string{"203.0.113.7", "198.51.100.0/24", "2001:db8::/32", " "})
+	if err != nil {
+		t.Fatalf("NewIPReputationCheck: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		check      Check
+		submission Submission
+		wantScore  float64
+		wantReason string
+	}{
+		{"honeypot filled in", HoneypotCheck{}, submission(model.ContactInfoInput{Honeypot: &filled}, ""), strongSignalScore, "honeypot field filled in"},
+		{"honeypot blank", HoneypotCheck{}, submission(model.ContactInfoInput{Honeypot: &blank}, ""), 0, ""},
+		{"honeypot missing", HoneypotCheck{}, submission(model.ContactInfoInput{}, ""), 0, ""},
+
+		{"link in name", NewLinkDensityCheck(2), submission(model.ContactInfoInput{Name: "www.cheap-seo.example"}, ""), strongSignalScore, "link in name or company"},
+		{"links within the allowance", NewLinkDensityCheck(2), submission(model.ContactInfoInput{Message: "See https://a.example and www.b.example"}, ""), 0, ""},
+		{"links above the allowance", NewLinkDensityCheck(1), submission(model.ContactInfoInput{Subject: "https://a.example", Message: "http://b.example WWW.c.example"}, ""), 2 * linkScore, "3 links in message"},
+
+		{"keywords", NewKeywordCheck([]string{" Casino ", "SEO services", ""}), submission(model.ContactInfoInput{Subject: "Best casino", Message: "We offer seo services"}, ""), 2 * keywordScore, "spam keywords: casino, seo services"},
+		{"no keywords", NewKeywordCheck([]string{"casino"}), submission(model.ContactInfoInput{Message: "Please quote 10 pumps"}, ""), 0, ""},
+
+		{"blocked domain", NewEmailDomainCheck([]string{"Spam.Example"}), submission(model.ContactInfoInput{Email: "bot@spam.example"}, ""), strongSignalScore, "blocked email domain spam.example"},
+		{"blocked parent domain", NewEmailDomainCheck([]string{"spam.example"}), submission(model.ContactInfoInput{Email: "bot@mail.SPAM.example"}, ""), strongSignalScore, "blocked email domain mail.spam.example"},
+		{"similar domain", NewEmailDomainCheck([]string{"spam.example"}), submission(model.ContactInfoInput{Email: "jane@notspam.example"}, ""), 0, ""},
+		{"no domain", NewEmailDomainCheck([]string{"spam.example"}), submission(model.ContactInfoInput{Email: "spam.example"}, ""), 0, ""},
+
+		{"gibberish name and message", GibberishCheck{}, submission(model.ContactInfoInput{Name: "xkcdqwrt", Message: "hjkdfgs bvnmqwt"}, ""), 2 * gibberishScore, "gibberish name and message"},
+		{"gibberish message", GibberishCheck{}, submission(model.ContactInfoInput{Name: "Jane Doe", Message: "hELLOwORLD sdfghjkl"}, ""), gibberishScore, "gibberish message"},
+		{"real words", GibberishCheck{}, submission(model.ContactInfoInput{Name: "Ronald McDonald", Message: "Please quote centrifugal pumps for our JavaScript team"}, ""), 0, ""},
+		{"other scripts", GibberishCheck{}, submission(model.ContactInfoInput{Name: "محمد عبدالله", Message: "نرجو تزويدنا بعرض سعر"}, ""), 0, ""},
+
+		{"blocked IP", ipCheck, submission(model.ContactInfoInput{}, "203.0.113.7"), strongSignalScore, "blocked IP 203.0.113.7"},
+		{"blocked network", ipCheck, submission(model.ContactInfoInput{}, "198.51.100.42"), strongSignalScore, "blocked IP 198.51.100.42"},
+		{"blocked IPv6 network", ipCheck, submission(model.ContactInfoInput{}, "2001:db8::1"), strongSignalScore, "blocked IP 2001:db8::1"},
+		{"other IP", ipCheck, submission(model.ContactInfoInput{}, "203.0.113.8"), 0, ""},
+		{"unknown IP", ipCheck, submission(model.ContactInfoInput{}, ""), 0, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			signal := tt.check.Check(context.Background(), tt.submission)
+			if signal.Score != tt.wantScore || signal.Reason != tt.wantReason {
+				t.Errorf("%s check = %+v, want score %v and reason %q", tt.check.Name(), signal, tt.wantScore, tt.wantReason)
+			}
+		})
+	}
+}
+
+func TestNewIPReputationCheckRejectsInvalidEntries(t *testing.T) {
+	for _, entry := range []string{"203.0.113", "198.51.100.0/33", "localhost"} {
+		if _, err := NewIPReputationCheck([]string{entry}); err == nil {
+			t.Errorf("NewIPReputationCheck(%q) accepted the entry", entry)
+		}
+	}
+}
+
+func TestLooksRandom(t *testing.T) {
+	tests := []struct {
+		word string
+		want bool
+	}{
+		{"centrifugal", false},
+		{"Mohammed", false},
+		{"McDonald", false},
+		{"JavaScript", false},
+		{"iPhones", false},
+		{"QUOTATION", false},
+		{"xkcdqwrt", true},
+		{"asdfghjkl", true},
+		{"hELLOwORLD", true},
+		{"QwErTy", true},
+	}
+	for _, tt := range tests {
+		if got := looksRandom(tt.word); got != tt.want {
+			t.Errorf("looksRandom(%q) = %v, want %v", tt.word, got, tt.want)
+		}
+	}
+}
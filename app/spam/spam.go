@@ -0,0 +1,65 @@
+package spam
+
+import (
+	"context"
+
+	"sct-backend-service/graph/model"
+)
+
+// Submission is a single contact to score, together with what is known about the request
+type Submission struct {
+	Source   model.WebsiteSource
+	Contact  *model.ContactInfoInput
+	ClientIP string
+}
+
+// Signal is the outcome of one check; a zero score means the check found nothing
+type Signal struct {
+	Score  float64
+	Reason string
+}
+
+// Check scores one aspect of a submission
+type Check interface {
+	// Name identifies the check in logs
+	Name() string
+	// Check scores the submission
+	Check(ctx context.Context, submission Submission) Signal
+}
+
+// Verdict is the combined outcome of all checks
+type Verdict struct {
+	Score   float64
+	Reasons []string
+	// Spam is set when the score reached the threshold
+	Spam bool
+}
+
+// Scorer runs every check and adds up their scores
+type Scorer struct {
+	checks    []Check
+	threshold float64
+}
+
+// NewScorer creates a scorer that flags submissions scoring at least threshold
+func NewScorer(checks []Check, threshold float64) *Scorer {
+	return &Scorer{
+		checks:    checks,
+		threshold: threshold,
+	}
+}
+
+// Score runs the checks against the submission
+func (s *Scorer) Score(ctx context.Context, submission Submission) Verdict {
+	verdict := Verdict{}
+	for _, check := range s.checks {
+		signal := check.Check(ctx, submission)
+		if signal.Score <= 0 {
+			continue
+		}
+		verdict.Score += signal.Score
+		verdict.Reasons = append(verdict.Reasons, signal.Reason)
+	}
+	verdict.Spam = len(s.checks) > 0 && verdict.Score >= s.threshold
+	return verdict
+}
@@ -0,0 +1,82 @@
+package spam
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"sct-backend-service/graph/model"
+)
+
+// fixedCheck returns the same signal for every submission
+type fixedCheck struct {
+	signal Signal
+}
+
+func (c fixedCheck) Name() string {
+	return "fixed"
+}
+
+func (c fixedCheck) Check(ctx context.Context, submission Submission) Signal {
+	return c.signal
+}
+
+func TestScorer(t *testing.T) {
+	tests := []struct {
+		name        string
+		checks      []Check
+		threshold   float64
+		wantScore   float64
+		wantReasons []string
+		wantSpam    bool
+	}{
+		{
+			name:      "no checks",
+			threshold: 0,
+		},
+		{
+			name:      "nothing found",
+			checks:    []Check{fixedCheck{}, fixedCheck{}},
+			threshold: 5,
+		},
+		{
+			name:        "weak signals add up to the threshold",
+			checks:      []Check{fixedCheck{Signal{Score: 2, Reason: "links"}}, fixedCheck{}, fixedCheck{Signal{Score: 3, Reason: "gibberish"}}},
+			threshold:   5,
+			wantScore:   5,
+			wantReasons: []string{"links", "gibberish"},
+			wantSpam:    true,
+		},
+		{
+			name:        "below the threshold",
+			checks:      []Check{fixedCheck{Signal{Score: 2, Reason: "links"}}},
+			threshold:   5,
+			wantScore:   2,
+			wantReasons: []string{"links"},
+		},
+		{
+			name:        "negative scores are ignored",
+			checks:      []Check{fixedCheck{Signal{Score: -3, Reason: "trusted"}}, fixedCheck{Signal{Score: 2, Reason: "links"}}},
+			threshold:   2,
+			wantScore:   2,
+			wantReasons: []string{"links"},
+			wantSpam:    true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			submission := Submission{Source: model.WebsiteSourceSctgulf, Contact: &model.ContactInfoInput{}}
+			verdict := NewScorer(tt.checks, tt.threshold).Score(context.Background(), submission)
+			if verdict.Score != tt.wantScore {
+				t.Errorf("Score = %v, want %v", verdict.Score, tt.wantScore)
+			}
+			if !slices.Equal(verdict.Reasons, tt.wantReasons) {
+				t.Errorf("Reasons = %q, want %q", verdict.Reasons, tt.wantReasons)
+			}
+			if verdict.Spam != tt.wantSpam {
+				t.Errorf("Spam = %v, want %v", verdict.Spam, tt.wantSpam)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package utils
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"unicode"
+)
+
+// EnquiryFingerprint identifies the same person sending the same message.
+// Email case, phone formatting and whitespace or case changes in the message do not change it.
+func EnquiryFingerprint(email, phoneNumber, message string) string {
+	normalizedPhone := strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, phoneNumber)
+	normalizedMessage := strings.Join(strings.Fields(strings.ToLower(message)), " ")
+
+	sum := sha256.Sum256([]byte(strings.ToLower(strings.TrimSpace(email)) + "\x00" + normalizedPhone + "\x00" + normalizedMessage))
+	return hex.EncodeToString(sum[:])
+}
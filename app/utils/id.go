@@ -2,16 +2,30 @@ package utils
 
 import (
 	"fmt"
+	"math/rand/v2"
 	"time"
+
+	"github.com/google/uuid"
 )
 
-// GenerateID generates a unique ID (simple implementation for demo)
-// In production, use UUID or database auto-increment
+// GenerateID generates a unique ID
 func GenerateID() string {
-	return fmt.Sprintf("%d", time.Now().UnixNano())
+	return uuid.NewString()
 }
 
 // IsValidID checks if an ID is valid
 func IsValidID(id string) bool {
 	return id != ""
 }
+
+// referenceAlphabet is Crockford's base32 alphabet, it leaves out letters that are easily confused
+const referenceAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// GenerateReferenceNumber generates a short human-friendly reference such as SCTGULF-261018-7F3K9Q
+func GenerateReferenceNumber(prefix string, t time.Time) string {
+	suffix := make([]byte, 6)
+	for i := range suffix {
+		suffix[i] = referenceAlphabet[rand.IntN(len(referenceAlphabet))]
+	}
+	return fmt.Sprintf("%s-%s-%s", prefix, t.UTC().Format("060102"), suffix)
+}
@@ -0,0 +1,25 @@
+package utils
+
+import (
+	"regexp"
+	"testing"
+	"time"
+)
+
+func TestGenerateReferenceNumber(t *testing.T) {
+	// Early on the 19th in Dubai is still the 18th in UTC, references use the UTC date
+	at := time.Date(2026, 10, 19, 1, 30, 0, 0, time.FixedZone("GST", 4*60*60))
+	pattern := regexp.MustCompile(`^SCTGULF-261018-[0-9A-HJKMNP-TV-Z]{6}$`)
+
+	seen := make(map[string]bool)
+	for range 1000 {
+		reference := GenerateReferenceNumber("SCTGULF", at)
+		if !pattern.MatchString(reference) {
+			t.Fatalf("reference %q does not match %s", reference, pattern)
+		}
+		seen[reference] = true
+	}
+	if len(seen) < 990 {
+		t.Errorf("%d distinct references in 1000, the suffix is not random enough", len(seen))
+	}
+}
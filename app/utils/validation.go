@@ -2,6 +2,7 @@ package utils
 
 import (
 	"regexp"
+	"unicode/utf8"
 )
 
 var (
@@ -21,10 +22,18 @@ func IsValidString(s string) bool {
 	return len(s) > 0
 }
 
-// TruncateString truncates a string to a maximum length
+// TruncateString truncates a string to at most maxLen characters.
+// It counts runes, so multi-byte text such as Arabic is never cut in the middle of a character.
 func TruncateString(s string, maxLen int) string {
-	if len(s) <= maxLen {
+	if utf8.RuneCountInString(s) <= maxLen {
 		return s
 	}
-	return s[:maxLen]
+	count := 0
+	for i := range s {
+		if count == maxLen {
+			return s[:i]
+		}
+		count++
+	}
+	return s
 }
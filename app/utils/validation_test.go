@@ -0,0 +1,37 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateString(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  string
+		maxLen int
+		want   string
+	}{
+		{"shorter than the limit", "hello", 10, "hello"},
+		{"exactly the limit", "hello", 5, "hello"},
+		{"ASCII", "hello world", 5, "hello"},
+		{"zero", "hello", 0, ""},
+		{"Arabic", "مرحبا بكم", 5, "مرحبا"},
+		{"Arabic at the limit", "مرحبا", 5, "مرحبا"},
+		{"emoji", "🙂🙃🙂", 2, "🙂🙃"},
+		{"mixed scripts", "RFQ طلب عرض", 6, "RFQ طل"},
+		{"long Arabic", strings.Repeat("ع", 10000), 3000, strings.Repeat("ع", 3000)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateString(tt.input, tt.maxLen)
+			if got != tt.want {
+				t.Errorf("TruncateString(%q, %d) = %q, want %q", tt.input, tt.maxLen, got, tt.want)
+			}
+			if !utf8.ValidString(got) {
+				t.Errorf("TruncateString(%q, %d) split a character: %q", tt.input, tt.maxLen, got)
+			}
+		})
+	}
+}
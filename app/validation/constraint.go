@@ -0,0 +1,44 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+)
+
+// Formats are the named formats a Constraint can require
+var Formats = map[string]Rule{
+	"email":   Email(),
+	"phone":   Phone(),
+	"country": Matches(countryRegex, "must be a two letter ISO country code"),
+}
+
+// Constraint mirrors the arguments of the @constraint schema directive, zero values are not checked
+type Constraint struct {
+	MinLength int
+	MaxLength int
+	Format    string
+	Pattern   string
+}
+
+// Rules returns the rules that enforce the constraint
+func (c Constraint) Rules() ([]Rule, error) {
+	var rules []Rule
+	if c.MinLength > 0 || c.MaxLength > 0 {
+		rules = append(rules, Length(c.MinLength, c.MaxLength))
+	}
+	if c.Format != "" {
+		rule, ok := Formats[c.Format]
+		if !ok {
+			return nil, fmt.Errorf("unknown format %q", c.Format)
+		}
+		rules = append(rules, rule)
+	}
+	if c.Pattern != "" {
+		pattern, err := regexp.Compile(`^(?:` + c.Pattern + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", c.Pattern, err)
+		}
+		rules = append(rules, Matches(pattern, "must match "+c.Pattern))
+	}
+	return rules, nil
+}
@@ -0,0 +1,147 @@
+package validation
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"sct-backend-service/app/i18n"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/app/utils"
+)
+
+// Violation describes why a value failed a rule
+type Violation struct {
+	Code string
+	// Message is the English text with fmt verbs for Args, it is the key of its translations, see i18n.Sprintf
+	Message string
+	Args    []interface{}
+}
+
+// Text formats the message in the locale
+func (v *Violation) Text(locale string) string {
+	return i18n.Sprintf(locale, v.Message, v.Args...)
+}
+
+// Rule checks a single value and returns nil when it passes.
+// Every rule except Required passes empty values, so optional fields simply leave Required out.
+type Rule interface {
+	Check(value string) *Violation
+}
+
+// RuleFunc adapts a function to Rule
+type RuleFunc func(value string) *Violation
+
+// Check calls f
+func (f RuleFunc) Check(value string) *Violation {
+	return f(value)
+}
+
+// Required rejects empty and blank values
+func Required() Rule {
+	return RuleFunc(func(value string) *Violation {
+		if !utils.IsValidString(strings.TrimSpace(value)) {
+			return &Violation{Code: keys.ErrCodeRequired, Message: "is required"}
+		}
+		return nil
+	})
+}
+
+// Length bounds the number of characters of a value, a bound of zero is not checked
+func Length(min, max int) Rule {
+	return RuleFunc(func(value string) *Violation {
+		if value == "" {
+			return nil
+		}
+		n := utf8.RuneCountInString(strings.TrimSpace(value))
+		if min > 0 && n < min {
+			return &Violation{Code: keys.ErrCodeTooShort, Message: "must be at least %d characters", Args: []interface{}{min}}
+		}
+		if max > 0 && n > max {
+			return &Violation{Code: keys.ErrCodeTooLong, Message: "must be at most %d characters", Args: []interface{}{max}}
+		}
+		return nil
+	})
+}
+
+// Email requires a valid email address
+func Email() Rule {
+	return RuleFunc(func(value string) *Violation {
+		if value == "" {
+			return nil
+		}
+		if !utils.IsValidEmail(strings.TrimSpace(value)) {
+			return &Violation{Code: keys.ErrCodeInvalidEmail, Message: "must be a valid email address"}
+		}
+		return nil
+	})
+}
+
+var (
+	phoneCharsRegex = regexp.MustCompile(`^\+?[0-9 ().\-]+$`)
+)
+
+// Phone requires a phone number of 7 to 15 digits, written with an optional leading + and
+// the usual separators (spaces, dots, dashes and parentheses)
+func Phone() Rule {
+	return RuleFunc(func(value string) *Violation {
+		value = strings.TrimSpace(value)
+		if value == "" {
+			return nil
+		}
+		digits := 0
+		for _, r := range value {
+			if r >= '0' && r <= '9' {
+				digits++
+			}
+		}
+		if !phoneCharsRegex.MatchString(value) || digits < 7 || digits > 15 {
+			return &Violation{Code: keys.ErrCodeInvalidPhone, Message: "must be a valid phone number"}
+		}
+		return nil
+	})
+}
+
+// AllowedChars requires every character to match pattern, described in the message as allowed
+func AllowedChars(pattern *regexp.Regexp, allowed string) Rule {
+	return RuleFunc(func(value string) *Violation {
+		if value == "" {
+			return nil
+		}
+		if !pattern.MatchString(value) {
+			return &Violation{Code: keys.ErrCodeInvalidCharacters, Message: "may only contain " + allowed}
+		}
+		return nil
+	})
+}
+
+// Matches requires the whole value to match pattern, message describes the expected format
+func Matches(pattern *regexp.Regexp, message string) Rule {
+	return RuleFunc(func(value string) *Violation {
+		if value == "" {
+			return nil
+		}
+		if !pattern.MatchString(value) {
+			return &Violation{Code: keys.ErrCodeInvalidFormat, Message: message}
+		}
+		return nil
+	})
+}
+
+// PlainText rejects control characters, line breaks and tabs are only allowed when multiline is set
+func PlainText(multiline bool) Rule {
+	return RuleFunc(func(value string) *Violation {
+		for _, r := range value {
+			if r == '\n' || r == '\r' || r == '\t' {
+				if multiline {
+					continue
+				}
+			} else if !unicode.IsControl(r) && r != utf8.RuneError {
+				continue
+			}
+			return &Violation{Code: keys.ErrCodeInvalidCharacters, Message: "contains characters that are not allowed"}
+		}
+		return nil
+	})
+}
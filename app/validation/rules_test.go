@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"testing"
+
+	"sct-backend-service/app/keys"
+)
+
+func TestRules(t *testing.T) {
+	tests := []struct {
+		name     string
+		rule     Rule
+		value    string
+		wantCode string
+	}{
+		{"required", Required(), "Jane", ""},
+		{"required empty", Required(), "", keys.ErrCodeRequired},
+		{"required blank", Required(), " \t\n", keys.ErrCodeRequired},
+
+		{"length within", Length(2, 5), "Jane", ""},
+		{"length empty", Length(2, 5), "", ""},
+		{"length too short", Length(2, 5), "J", keys.ErrCodeTooShort},
+		{"length too short after trimming", Length(2, 5), " J ", keys.ErrCodeTooShort},
+		{"length too long", Length(2, 5), "Janet Doe", keys.ErrCodeTooLong},
+		{"length counts characters", Length(0, 4), "جميل", ""},
+		{"length without bounds", Length(0, 0), "anything", ""},
+
+		{"email", Email(), "jane@example.com", ""},
+		{"email empty", Email(), "", ""},
+		{"email invalid", Email(), "jane@", keys.ErrCodeInvalidEmail},
+
+		{"phone international", Phone(), "+971 50 123 4567", ""},
+		{"phone with separators", Phone(), "(050) 123-4567", ""},
+		{"phone empty", Phone(), "", ""},
+		{"phone too short", Phone(), "123 45", keys.ErrCodeInvalidPhone},
+		{"phone too long", Phone(), "+1234567890123456", keys.ErrCodeInvalidPhone},
+		{"phone with letters", Phone(), "050 CALL NOW", keys.ErrCodeInvalidPhone},
+
+		{"allowed characters", AllowedChars(nameRegex, "letters"), "Zoë O'Brien-Smith", ""},
+		{"allowed characters in Arabic", AllowedChars(nameRegex, "letters"), "محمد عبدالله", ""},
+		{"characters not allowed", AllowedChars(nameRegex, "letters"), "Jane <script>", keys.ErrCodeInvalidCharacters},
+
+		{"matches", Matches(countryRegex, "must be a country"), "AE", ""},
+		{"does not match", Matches(countryRegex, "must be a country"), "UAE", keys.ErrCodeInvalidFormat},
+
+		{"plain text", PlainText(false), "RFQ pumps", ""},
+		{"line break in single line", PlainText(false), "RFQ\npumps", keys.ErrCodeInvalidCharacters},
+		{"line break in multiline", PlainText(true), "Hello,\r\n\tplease quote", ""},
+		{"control character", PlainText(true), "RFQ\x00pumps", keys.ErrCodeInvalidCharacters},
+		{"invalid UTF-8", PlainText(true), "RFQ \xff", keys.ErrCodeInvalidCharacters},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			violation := tt.rule.Check(tt.value)
+			switch {
+			case tt.wantCode == "" && violation != nil:
+				t.Errorf("Check(%q) = %s, want it to pass", tt.value, violation.Code)
+			case tt.wantCode != "" && (violation == nil || violation.Code != tt.wantCode):
+				t.Errorf("Check(%q) = %v, want %s", tt.value, violation, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestViolationText(t *testing.T) {
+	violation := Length(0, 5).Check("Janet Doe")
+	if text := violation.Text("en"); text != "must be at most 5 characters" {
+		t.Errorf("Text = %q", text)
+	}
+}
+
+func TestConstraintRules(t *testing.T) {
+	tests := []struct {
+		name       string
+		constraint Constraint
+		value      string
+		wantCode   string
+		wantErr    bool
+	}{
+		{"no constraint", Constraint{}, "anything", "", false},
+		{"length", Constraint{MinLength: 2, MaxLength: 3}, "abcd", keys.ErrCodeTooLong, false},
+		{"format", Constraint{Format: "email"}, "jane", keys.ErrCodeInvalidEmail, false},
+		{"pattern matches the whole value", Constraint{Pattern: "[UW][A-Z0-9]+"}, "xU123", keys.ErrCodeInvalidFormat, false},
+		{"pattern", Constraint{Pattern: "[UW][A-Z0-9]+"}, "U123ABC", "", false},
+		{"alternatives are anchored together", Constraint{Pattern: "a|b"}, "ab", keys.ErrCodeInvalidFormat, false},
+		{"unknown format", Constraint{Format: "iban"}, "", "", true},
+		{"invalid pattern", Constraint{Pattern: "("}, "", "", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rules, err := tt.constraint.Rules()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Rules() error = %v, want error %v", err, tt.wantErr)
+			}
+			code := ""
+			for _, rule := range rules {
+				if violation := rule.Check(tt.value); violation != nil {
+					code = violation.Code
+					break
+				}
+			}
+			if code != tt.wantCode {
+				t.Errorf("%q violates %q, want %q", tt.value, code, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestFormats(t *testing.T) {
+	for name := range Formats {
+		if _, err := (Constraint{Format: name}).Rules(); err != nil {
+			t.Errorf("format %s: %v", name, err)
+		}
+	}
+	if violation := Formats["country"].Check("ae"); violation != nil {
+		t.Errorf("country format rejects lower case codes: %s", violation.Code)
+	}
+	if violation := Formats["country"].Check("A."); violation == nil {
+		t.Error("country format accepts A.")
+	}
+}
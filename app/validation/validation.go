@@ -0,0 +1,121 @@
+package validation
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"sct-backend-service/app/i18n"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/graph/model"
+)
+
+// FieldError reports a rule violation at an input path such as contactInfo[2].email
+type FieldError struct {
+	Path string
+	Code string
+	// Message and Args are the English text of the violation, see Violation
+	Message string
+	Args    []interface{}
+}
+
+func (e *FieldError) Error() string {
+	return e.Text(i18n.English)
+}
+
+// Text describes the error in the locale
+func (e *FieldError) Text(locale string) string {
+	return i18n.FieldMessage(locale, e.Path, i18n.Sprintf(locale, e.Message, e.Args...))
+}
+
+// Errors holds every violation found in an input, in input order
+type Errors []*FieldError
+
+func (e Errors) Error() string {
+	messages := make([]string, len(e))
+	for i, err := range e {
+		messages[i] = err.Error()
+	}
+	return strings.Join(messages, "; ")
+}
+
+// ContactField declares the rules of one ContactInfoInput field
+type ContactField struct {
+	// Name is the field name in the schema
+	Name  string
+	Value func(contact *model.ContactInfoInput) string
+	Rules []Rule
+}
+
+var (
+	nameRegex    = regexp.MustCompile(`^[\p{L}\p{M}\s'’.\-]+$`)
+	countryRegex = regexp.MustCompile(`^[A-Za-z]{2}$`)
+)
+
+// ContactFields are the rules applied to every ContactInfoInput in addition to the lengths and formats
+// declared with @constraint in the schema. Only the first violation of each field is reported.
+var ContactFields = []ContactField{
+	{
+		Name:  "name",
+		Value: func(c *model.ContactInfoInput) string { return c.Name },
+		Rules: []Rule{Required(), AllowedChars(nameRegex, "letters, spaces, apostrophes, periods and hyphens")},
+	},
+	{
+		Name:  "email",
+		Value: func(c *model.ContactInfoInput) string { return c.Email },
+		Rules: []Rule{Required()},
+	},
+	{
+		Name:  "phoneNumber",
+		Value: func(c *model.ContactInfoInput) string { return c.PhoneNumber },
+		Rules: []Rule{Required()},
+	},
+	{
+		Name:  "companyName",
+		Value: func(c *model.ContactInfoInput) string { return c.CompanyName },
+		Rules: []Rule{Required(), PlainText(false)},
+	},
+	{
+		Name:  "subject",
+		Value: func(c *model.ContactInfoInput) string { return c.Subject },
+		Rules: []Rule{Required(), PlainText(false)},
+	},
+	{
+		Name:  "message",
+		Value: func(c *model.ContactInfoInput) string { return c.Message },
+		Rules: []Rule{Required(), PlainText(true)},
+	},
+}
+
+// ValidateSendContactInfo checks every contact of a request against ContactFields
+func ValidateSendContactInfo(input model.SendContactInfoRequest) Errors {
+	if len(input.ContactInfo) == 0 {
+		return Errors{{Path: "contactInfo", Code: keys.ErrCodeRequired, Message: "must contain at least one contact"}}
+	}
+
+	var errs Errors
+	for i, contact := range input.ContactInfo {
+		errs = append(errs, ValidateContact(fmt.Sprintf("contactInfo[%d]", i), contact)...)
+	}
+	return errs
+}
+
+// ValidateContact checks a single contact, prefix is its input path
+func ValidateContact(prefix string, contact *model.ContactInfoInput) Errors {
+	var errs Errors
+	for _, field := range ContactFields {
+		value := field.Value(contact)
+		for _, rule := range field.Rules {
+			if violation := rule.Check(value); violation != nil {
+				errs = append(errs, &FieldError{
+					Path:    prefix + "." + field.Name,
+					Code:    violation.Code,
+					Message: violation.Message,
+					Args:    violation.Args,
+				})
+				break
+			}
+		}
+	}
+	return errs
+}
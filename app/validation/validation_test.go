@@ -0,0 +1,76 @@
+package validation
+
+import (
+	"strings"
+	"testing"
+
+	"sct-backend-service/app/keys"
+	"sct-backend-service/graph/model"
+)
+
+func validContact() *model.ContactInfoInput {
+	return &model.ContactInfoInput{
+		Name:        "Jane Doe",
+		Email:       "jane@example.com",
+		PhoneNumber: "+971 50 123 4567",
+		CompanyName: "Acme",
+		Subject:     "RFQ pumps",
+		Message:     "Please quote 10 pumps.\nThanks",
+	}
+}
+
+func TestValidateSendContactInfo(t *testing.T) {
+	invalid := validContact()
+	invalid.Name = "Jane <script>"
+	invalid.Email = " "
+	invalid.Subject = "RFQ\npumps"
+
+	errs := ValidateSendContactInfo(model.SendContactInfoRequest{
+		Source:      model.WebsiteSourceSctgulf,
+		ContactInfo: []*model.ContactInfoInput{validContact(), invalid},
+	})
+
+	want := []struct{ path, code string }{
+		{"contactInfo[1].name", keys.ErrCodeInvalidCharacters},
+		{"contactInfo[1].email", keys.ErrCodeRequired},
+		{"contactInfo[1].subject", keys.ErrCodeInvalidCharacters},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("errors = %v, want %d", errs, len(want))
+	}
+	for i, w := range want {
+		if errs[i].Path != w.path || errs[i].Code != w.code {
+			t.Errorf("error %d = %s %s, want %s %s", i, errs[i].Path, errs[i].Code, w.path, w.code)
+		}
+	}
+}
+
+func TestValidateSendContactInfoWithoutContacts(t *testing.T) {
+	errs := ValidateSendContactInfo(model.SendContactInfoRequest{Source: model.WebsiteSourceSctgulf})
+	if len(errs) != 1 || errs[0].Path != "contactInfo" || errs[0].Code != keys.ErrCodeRequired {
+		t.Errorf("errors = %v, want contactInfo required", errs)
+	}
+}
+
+func TestValidateContactReportsFirstViolationPerField(t *testing.T) {
+	contact := validContact()
+	// Blank is both missing and free of disallowed characters, only the first rule is reported
+	contact.Name = "   "
+	errs := ValidateContact("contactInfo[0]", contact)
+	if len(errs) != 1 || errs[0].Code != keys.ErrCodeRequired {
+		t.Errorf("errors = %v, want only REQUIRED", errs)
+	}
+}
+
+func TestErrorsText(t *testing.T) {
+	errs := Errors{
+		{Path: "contactInfo[0].email", Code: keys.ErrCodeRequired, Message: "is required"},
+		{Path: "contactInfo[1].name", Code: keys.ErrCodeTooShort, Message: "must be at least %d characters", Args: []interface{}{2}},
+	}
+	if got := errs.Error(); got != "contactInfo[0].email is required; contactInfo[1].name must be at least 2 characters" {
+		t.Errorf("Error() = %q", got)
+	}
+	if text := errs[0].Text("ar"); strings.Contains(text, "contactInfo") {
+		t.Errorf("Arabic text names the input path: %q", text)
+	}
+}
@@ -0,0 +1,43 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/captcha"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/graph/model"
+	"sct-backend-service/internal/middleware"
+)
+
+// verifyCaptcha checks the captcha token of sources that require one
+func (impl *workflowGraphQLServiceDepsImpl) verifyCaptcha(ctx context.Context, input model.SendContactInfoRequest) error {
+	token := ""
+	if input.CaptchaToken != nil {
+		token = strings.TrimSpace(*input.CaptchaToken)
+	}
+	clientIP, _ := middleware.GetClientIP(ctx)
+
+	err := impl.deps.Captcha.Verify(ctx, input.Source.String(), token, clientIP)
+	if err == nil {
+		return nil
+	}
+
+	impl.deps.Logger.Warn("Captcha verification failed",
+		zap.String("source", input.Source.String()),
+		zap.Error(err),
+	)
+	switch {
+	case errors.Is(err, captcha.ErrMissingToken):
+		return middleware.NewLocalizedError(ctx, keys.ErrCodeCaptchaFailed, "a captcha token is required")
+	case errors.Is(err, captcha.ErrRejected):
+		return middleware.NewLocalizedError(ctx, keys.ErrCodeCaptchaFailed, "captcha verification failed, please try again")
+	case errors.Is(err, captcha.ErrUnavailable):
+		return middleware.NewLocalizedError(ctx, keys.ErrCodeCaptchaUnavailable, "captcha verification is temporarily unavailable, please try again later")
+	default:
+		return err
+	}
+}
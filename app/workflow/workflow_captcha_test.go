@@ -0,0 +1,54 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/captcha"
+	"sct-backend-service/app/data"
+	"sct-backend-service/app/idempotency"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/graph/model"
+)
+
+func TestSendContactInfoChecksCaptchaFirst(t *testing.T) {
+	idempotencyKeys := data.NewMemoryIdempotencyRepository()
+	service := CreateWorkflowGraphQLService(WorkflowGraphQLServiceDeps{
+		Logger:      zap.NewNop(),
+		Captcha:     captcha.NewRegistry(map[string]captcha.Verifier{"SCTGULF": captcha.NewFakeVerifier("pass")}),
+		Idempotency: idempotency.NewStore(idempotency.Config{Window: time.Hour, LockTimeout: time.Minute}, idempotencyKeys, zap.NewNop()),
+	})
+
+	key, token := "key-1", "guess"
+	tests := []struct {
+		name  string
+		token *string
+	}{
+		{"missing token", nil},
+		{"wrong token", &token},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// The contact is invalid too, the captcha error must win
+			_, err := service.SendContactInfo(context.Background(), model.SendContactInfoRequest{
+				Source:         model.WebsiteSourceSctgulf,
+				ContactInfo:    []*model.ContactInfoInput{{Name: "x"}},
+				IdempotencyKey: &key,
+				CaptchaToken:   tt.token,
+			})
+
+			var gqlErr *gqlerror.Error
+			if !errors.As(err, &gqlErr) || gqlErr.Extensions["code"] != keys.ErrCodeCaptchaFailed {
+				t.Fatalf("SendContactInfo = %v, want a %s error", err, keys.ErrCodeCaptchaFailed)
+			}
+			if _, err := idempotencyKeys.Get(context.Background(), model.WebsiteSourceSctgulf.String()+":"+key); !errors.Is(err, data.ErrIdempotencyKeyNotFound) {
+				t.Errorf("idempotency key was reserved before the captcha was checked: %v", err)
+			}
+		})
+	}
+}
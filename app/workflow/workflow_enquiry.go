@@ -0,0 +1,163 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/graph/model"
+)
+
+// enquiryStatusTransitions lists the statuses each status may move to: NEW → CONTACTED → QUALIFIED → WON.
+// An open enquiry may be marked LOST or SPAM at every step. WON, LOST and SPAM are final,
+// only releaseEnquiry moves a SPAM enquiry back to NEW.
+var enquiryStatusTransitions = map[model.EnquiryStatus][]model.EnquiryStatus{
+	model.EnquiryStatusNew:       {model.EnquiryStatusContacted, model.EnquiryStatusLost, model.EnquiryStatusSpam},
+	model.EnquiryStatusContacted: {model.EnquiryStatusQualified, model.EnquiryStatusLost, model.EnquiryStatusSpam},
+	model.EnquiryStatusQualified: {model.EnquiryStatusWon, model.EnquiryStatusLost, model.EnquiryStatusSpam},
+}
+
+// StatusTransitionError is returned when a status change is not allowed
+type StatusTransitionError struct {
+	From model.EnquiryStatus
+	To   model.EnquiryStatus
+}
+
+func (e *StatusTransitionError) Error() string {
+	return fmt.Sprintf("cannot change enquiry status from %s to %s", e.From, e.To)
+}
+
+// CanTransitionEnquiryStatus reports whether an enquiry may move from one status to another
+func CanTransitionEnquiryStatus(from, to model.EnquiryStatus) bool {
+	return slices.Contains(enquiryStatusTransitions[from], to)
+}
+
+func (impl *workflowGraphQLServiceDepsImpl) UpdateEnquiryStatus(ctx context.Context, input model.UpdateEnquiryStatusInput) (*model.Enquiry, error) {
+	impl.deps.Logger.Info("UpdateEnquiryStatus workflow started",
+		zap.String("enquiry_id", input.ID),
+		zap.String("status", input.Status.String()),
+	)
+
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("a reason is required to change the enquiry status")
+	}
+
+	enquiry, err := impl.deps.Controller.Enquiry(ctx, input.ID)
+	if err != nil {
+		impl.deps.Logger.Error("UpdateEnquiryStatus workflow failed",
+			zap.String("enquiry_id", input.ID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("workflow error: %w", err)
+	}
+	if enquiry == nil {
+		return nil, fmt.Errorf("enquiry %s not found", input.ID)
+	}
+
+	if !CanTransitionEnquiryStatus(enquiry.Status, input.Status) {
+		return nil, &StatusTransitionError{From: enquiry.Status, To: input.Status}
+	}
+
+	// The controller only applies the change if the status is still the one checked above
+	result, err := impl.deps.Controller.TransitionEnquiryStatus(ctx, input.ID, enquiry.Status, input.Status, reason)
+	if err != nil {
+		impl.deps.Logger.Error("UpdateEnquiryStatus workflow failed",
+			zap.String("enquiry_id", input.ID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("workflow error: %w", err)
+	}
+
+	impl.deps.Logger.Info("UpdateEnquiryStatus workflow completed",
+		zap.String("enquiry_id", input.ID),
+		zap.String("status", result.Status.String()),
+	)
+
+	return result, nil
+}
+
+func (impl *workflowGraphQLServiceDepsImpl) AddEnquiryNote(ctx context.Context, input model.AddEnquiryNoteInput) (*model.Enquiry, error) {
+	impl.deps.Logger.Info("AddEnquiryNote workflow started",
+		zap.String("enquiry_id", input.ID),
+	)
+
+	text := strings.TrimSpace(input.Text)
+	if text == "" {
+		return nil, fmt.Errorf("a note cannot be empty")
+	}
+
+	enquiry, err := impl.deps.Controller.Enquiry(ctx, input.ID)
+	if err != nil {
+		impl.deps.Logger.Error("AddEnquiryNote workflow failed",
+			zap.String("enquiry_id", input.ID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("workflow error: %w", err)
+	}
+	if enquiry == nil {
+		return nil, fmt.Errorf("enquiry %s not found", input.ID)
+	}
+
+	result, err := impl.deps.Controller.AddEnquiryNote(ctx, input.ID, text)
+	if err != nil {
+		impl.deps.Logger.Error("AddEnquiryNote workflow failed",
+			zap.String("enquiry_id", input.ID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("workflow error: %w", err)
+	}
+
+	impl.deps.Logger.Info("AddEnquiryNote workflow completed",
+		zap.String("enquiry_id", input.ID),
+		zap.Int("notes", len(result.Notes)),
+	)
+
+	return result, nil
+}
+
+func (impl *workflowGraphQLServiceDepsImpl) AssignEnquiry(ctx context.Context, input model.AssignEnquiryInput) (*model.Enquiry, error) {
+	impl.deps.Logger.Info("AssignEnquiry workflow started",
+		zap.String("enquiry_id", input.ID),
+	)
+
+	assignee := strings.TrimSpace(input.Assignee)
+	if assignee == "" {
+		return nil, fmt.Errorf("an assignee is required")
+	}
+	var slackUserID string
+	if input.SlackUserID != nil {
+		slackUserID = *input.SlackUserID
+	}
+
+	enquiry, err := impl.deps.Controller.Enquiry(ctx, input.ID)
+	if err != nil {
+		impl.deps.Logger.Error("AssignEnquiry workflow failed",
+			zap.String("enquiry_id", input.ID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("workflow error: %w", err)
+	}
+	if enquiry == nil {
+		return nil, fmt.Errorf("enquiry %s not found", input.ID)
+	}
+
+	result, err := impl.deps.Controller.AssignEnquiry(ctx, input.ID, assignee, slackUserID)
+	if err != nil {
+		impl.deps.Logger.Error("AssignEnquiry workflow failed",
+			zap.String("enquiry_id", input.ID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("workflow error: %w", err)
+	}
+
+	impl.deps.Logger.Info("AssignEnquiry workflow completed",
+		zap.String("enquiry_id", input.ID),
+		zap.String("assignee", assignee),
+	)
+
+	return result, nil
+}
@@ -0,0 +1,38 @@
+package workflow
+
+import (
+	"testing"
+
+	"sct-backend-service/graph/model"
+)
+
+func TestCanTransitionEnquiryStatus(t *testing.T) {
+	tests := []struct {
+		from model.EnquiryStatus
+		to   model.EnquiryStatus
+		want bool
+	}{
+		{model.EnquiryStatusNew, model.EnquiryStatusContacted, true},
+		{model.EnquiryStatusNew, model.EnquiryStatusQualified, false},
+		{model.EnquiryStatusNew, model.EnquiryStatusWon, false},
+		{model.EnquiryStatusNew, model.EnquiryStatusLost, true},
+		{model.EnquiryStatusNew, model.EnquiryStatusSpam, true},
+		{model.EnquiryStatusContacted, model.EnquiryStatusQualified, true},
+		{model.EnquiryStatusContacted, model.EnquiryStatusWon, false},
+		{model.EnquiryStatusContacted, model.EnquiryStatusLost, true},
+		{model.EnquiryStatusContacted, model.EnquiryStatusSpam, true},
+		{model.EnquiryStatusContacted, model.EnquiryStatusNew, false},
+		{model.EnquiryStatusQualified, model.EnquiryStatusWon, true},
+		{model.EnquiryStatusQualified, model.EnquiryStatusLost, true},
+		{model.EnquiryStatusQualified, model.EnquiryStatusSpam, true},
+		{model.EnquiryStatusQualified, model.EnquiryStatusContacted, false},
+		{model.EnquiryStatusWon, model.EnquiryStatusLost, false},
+		{model.EnquiryStatusLost, model.EnquiryStatusContacted, false},
+		{model.EnquiryStatusSpam, model.EnquiryStatusNew, false},
+	}
+	for _, tt := range tests {
+		if got := CanTransitionEnquiryStatus(tt.from, tt.to); got != tt.want {
+			t.Errorf("CanTransitionEnquiryStatus(%s, %s) = %v, want %v", tt.from, tt.to, got, tt.want)
+		}
+	}
+}
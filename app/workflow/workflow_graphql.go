@@ -7,19 +7,34 @@ import (
 	"go.uber.org/fx"
 	"go.uber.org/zap"
 
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/captcha"
 	"sct-backend-service/app/controllers"
+	"sct-backend-service/app/idempotency"
+	"sct-backend-service/app/phone"
+	"sct-backend-service/app/spam"
 	"sct-backend-service/graph/model"
 	"sct-backend-service/types"
 )
 
 type WorkflowGraphQLService interface {
 	types.GraphQLService
+	UpdateEnquiryStatus(ctx context.Context, input model.UpdateEnquiryStatusInput) (*model.Enquiry, error)
+	ReleaseEnquiry(ctx context.Context, input model.ReleaseEnquiryInput) (*model.Enquiry, error)
+	AddEnquiryNote(ctx context.Context, input model.AddEnquiryNoteInput) (*model.Enquiry, error)
+	AssignEnquiry(ctx context.Context, input model.AssignEnquiryInput) (*model.Enquiry, error)
+	PreviewNotification(ctx context.Context, source model.WebsiteSource, channel model.NotificationChannel, input model.ContactInfoInput) (*model.NotificationPreview, error)
 }
 
 type WorkflowGraphQLServiceDeps struct {
 	fx.In
-	Logger     *zap.Logger
-	Controller controllers.GraphQLController
+	Logger      *zap.Logger
+	Controller  controllers.GraphQLController
+	Idempotency *idempotency.Store
+	SpamScorer  *spam.Scorer
+	Captcha     *captcha.Registry
+	PhoneParser *phone.Parser
+	Attachments attachment.Limits
 }
 
 type workflowGraphQLServiceDepsImpl struct {
@@ -42,8 +57,22 @@ func (impl *workflowGraphQLServiceDepsImpl) SendContactInfo(ctx context.Context,
 	// ctx, span := impl.deps.Tracer.Start(ctx, "SendContactInfo/workflow")
 	// defer span.End()
 
-	// Delegate to controller
-	result, err := impl.deps.Controller.SendContactInfo(ctx, input)
+	// The captcha is checked first, so bots get no validation feedback and cannot hold idempotency keys
+	if err := impl.verifyCaptcha(ctx, input); err != nil {
+		return nil, err
+	}
+
+	if err := impl.validateContactInfo(ctx, input); err != nil {
+		return nil, err
+	}
+
+	var result *model.SendContactInfoResponse
+	var err error
+	if key := idempotencyKey(ctx, input); key != "" {
+		result, err = impl.sendContactInfoOnce(ctx, key, input)
+	} else {
+		result, err = impl.sendContactInfo(ctx, input)
+	}
 	if err != nil {
 		impl.deps.Logger.Error("SendContactInfo workflow failed",
 			zap.Error(err),
@@ -51,9 +80,62 @@ func (impl *workflowGraphQLServiceDepsImpl) SendContactInfo(ctx context.Context,
 		return nil, fmt.Errorf("workflow error: %w", err)
 	}
 
+	accepted := 0
+	for _, item := range result.Results {
+		if item.Status == model.SubmissionStatusAccepted {
+			accepted++
+		}
+	}
 	impl.deps.Logger.Info("SendContactInfo workflow completed",
 		zap.Bool("success", result.IsSuccess),
+		zap.Int("accepted_count", accepted),
+		zap.Int("failed_count", len(result.Results)-accepted),
 	)
 
 	return result, nil
 }
+
+// sendContactInfo runs the submission steps that follow the request checks
+func (impl *workflowGraphQLServiceDepsImpl) sendContactInfo(ctx context.Context, input model.SendContactInfoRequest) (*model.SendContactInfoResponse, error) {
+	verdicts := impl.scoreContacts(ctx, input)
+
+	// Delegate to controller
+	return impl.deps.Controller.SubmitContactInfo(ctx, input, verdicts)
+}
+
+func (impl *workflowGraphQLServiceDepsImpl) Enquiries(ctx context.Context, filter *model.EnquiryFilter, first *int, after *string) (*model.EnquiryConnection, error) {
+	result, err := impl.deps.Controller.Enquiries(ctx, filter, first, after)
+	if err != nil {
+		impl.deps.Logger.Error("Enquiries workflow failed",
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("workflow error: %w", err)
+	}
+
+	return result, nil
+}
+
+func (impl *workflowGraphQLServiceDepsImpl) EnquiryStats(ctx context.Context, filter *model.EnquiryFilter) (*model.EnquiryStats, error) {
+	result, err := impl.deps.Controller.EnquiryStats(ctx, filter)
+	if err != nil {
+		impl.deps.Logger.Error("EnquiryStats workflow failed",
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("workflow error: %w", err)
+	}
+
+	return result, nil
+}
+
+func (impl *workflowGraphQLServiceDepsImpl) Enquiry(ctx context.Context, id string) (*model.Enquiry, error) {
+	result, err := impl.deps.Controller.Enquiry(ctx, id)
+	if err != nil {
+		impl.deps.Logger.Error("Enquiry workflow failed",
+			zap.String("enquiry_id", id),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("workflow error: %w", err)
+	}
+
+	return result, nil
+}
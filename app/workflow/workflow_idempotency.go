@@ -0,0 +1,140 @@
+package workflow
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/99designs/gqlgen/graphql"
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/idempotency"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/graph/model"
+	"sct-backend-service/internal/middleware"
+)
+
+// maxIdempotencyKeyLength bounds the keys clients may send
+const maxIdempotencyKeyLength = 255
+
+// idempotencyKey returns the key from the input, falling back to the Idempotency-Key header
+func idempotencyKey(ctx context.Context, input model.SendContactInfoRequest) string {
+	if input.IdempotencyKey != nil {
+		return strings.TrimSpace(*input.IdempotencyKey)
+	}
+	key, _ := middleware.GetIdempotencyKey(ctx)
+	return key
+}
+
+// sendContactInfoOnce runs the submission at most once per idempotency key
+// and replays the stored response to repeats
+func (impl *workflowGraphQLServiceDepsImpl) sendContactInfoOnce(ctx context.Context, key string, input model.SendContactInfoRequest) (*model.SendContactInfoResponse, error) {
+	if len(key) > maxIdempotencyKeyLength {
+		return nil, middleware.NewGraphQLError(keys.ErrCodeInvalidIdempotencyKey,
+			fmt.Sprintf("idempotency key must be at most %d characters", maxIdempotencyKeyLength))
+	}
+
+	requestHash, err := sendContactInfoHash(input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Keys are scoped per site so two sites cannot collide on a client generated key
+	scopedKey := input.Source.String() + ":" + key
+	response, replayed, err := impl.deps.Idempotency.Do(ctx, scopedKey, requestHash, func(ctx context.Context) ([]byte, error) {
+		result, err := impl.sendContactInfo(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(result)
+	})
+	switch {
+	case errors.Is(err, idempotency.ErrKeyReused):
+		return nil, middleware.NewGraphQLError(keys.ErrCodeIdempotencyKeyReused, err.Error())
+	case errors.Is(err, idempotency.ErrInProgress):
+		return nil, middleware.NewGraphQLError(keys.ErrCodeRequestInProgress, err.Error())
+	case err != nil:
+		return nil, err
+	}
+
+	var result model.SendContactInfoResponse
+	if err := json.Unmarshal(response, &result); err != nil {
+		return nil, fmt.Errorf("error decoding stored response: %w", err)
+	}
+
+	if replayed {
+		impl.deps.Logger.Info("SendContactInfo replayed for idempotency key",
+			zap.String("source", input.Source.String()),
+			zap.String("idempotency_key", key),
+		)
+	}
+	return &result, nil
+}
+
+// hashedUpload identifies an uploaded file in the request hash by its content rather than the multipart stream
+type hashedUpload struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// hashedContact is a contact with its files replaced by their digests
+type hashedContact struct {
+	model.ContactInfoInput
+	Attachments []hashedUpload `json:"attachments,omitempty"`
+}
+
+// sendContactInfoHash fingerprints the request, ignoring the idempotency key itself.
+// Captcha tokens are single use, so a retry may carry a fresh one; they are ignored as well.
+// Files are hashed by name, size and content, and rewound for the submission to read them.
+func sendContactInfoHash(input model.SendContactInfoRequest) (string, error) {
+	contacts := make([]*hashedContact, len(input.ContactInfo))
+	for i, contact := range input.ContactInfo {
+		if contact == nil {
+			continue
+		}
+		contacts[i] = &hashedContact{ContactInfoInput: *contact}
+		contacts[i].ContactInfoInput.Attachments = nil
+		for _, upload := range contact.Attachments {
+			if upload == nil {
+				continue
+			}
+			digest, err := uploadDigest(upload)
+			if err != nil {
+				return "", fmt.Errorf("error hashing %s: %w", upload.Filename, err)
+			}
+			contacts[i].Attachments = append(contacts[i].Attachments, hashedUpload{Name: upload.Filename, Size: upload.Size, SHA256: digest})
+		}
+	}
+
+	body, err := json.Marshal(struct {
+		Source      model.WebsiteSource `json:"source"`
+		ContactInfo []*hashedContact    `json:"contactInfo"`
+		Locale      *string             `json:"locale,omitempty"`
+	}{input.Source, contacts, input.Locale})
+	if err != nil {
+		return "", fmt.Errorf("error hashing request: %w", err)
+	}
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// uploadDigest returns the SHA-256 of the file content and rewinds the file
+func uploadDigest(upload *graphql.Upload) (string, error) {
+	if upload.File == nil {
+		return "", nil
+	}
+	hash := sha256.New()
+	if _, err := io.Copy(hash, upload.File); err != nil {
+		return "", err
+	}
+	if _, err := upload.File.Seek(0, io.SeekStart); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
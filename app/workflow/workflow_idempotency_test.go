@@ -0,0 +1,84 @@
+package workflow
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"sct-backend-service/graph/model"
+)
+
+// requestWithFile is a submission carrying one file with the given name and content
+func requestWithFile(name, content string) model.SendContactInfoRequest {
+	return model.SendContactInfoRequest{
+		Source: model.WebsiteSourceSctgulf,
+		ContactInfo: []*model.ContactInfoInput{{
+			Name:    "Jane Doe",
+			Email:   "jane@example.com",
+			Message: "Please quote 10 pumps",
+			Attachments: []*graphql.Upload{{
+				File:        strings.NewReader(content),
+				Filename:    name,
+				Size:        int64(len(content)),
+				ContentType: "application/pdf",
+			}},
+		}},
+	}
+}
+
+func TestSendContactInfoHashFiles(t *testing.T) {
+	hash := func(input model.SendContactInfoRequest) string {
+		t.Helper()
+		sum, err := sendContactInfoHash(input)
+		if err != nil {
+			t.Fatalf("sendContactInfoHash: %v", err)
+		}
+		return sum
+	}
+
+	original := hash(requestWithFile("drawing.pdf", "%PDF-1.4 rev 1"))
+	tests := []struct {
+		name  string
+		input model.SendContactInfoRequest
+		same  bool
+	}{
+		{"same file", requestWithFile("drawing.pdf", "%PDF-1.4 rev 1"), true},
+		{"other name", requestWithFile("drawing-2.pdf", "%PDF-1.4 rev 1"), false},
+		{"other content of the same size", requestWithFile("drawing.pdf", "%PDF-1.4 rev 2"), false},
+		{"other size", requestWithFile("drawing.pdf", "%PDF-1.4 rev 10"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := hash(tt.input); (got == original) != tt.same {
+				t.Errorf("hash equal = %v, want %v", got == original, tt.same)
+			}
+		})
+	}
+
+	// The files are rewound so the submission still reads them whole
+	input := requestWithFile("drawing.pdf", "%PDF-1.4 rev 1")
+	hash(input)
+	content, err := io.ReadAll(input.ContactInfo[0].Attachments[0].File)
+	if err != nil || string(content) != "%PDF-1.4 rev 1" {
+		t.Errorf("file after hashing = %q, %v", content, err)
+	}
+}
+
+func TestSendContactInfoHashIgnoresKeyAndCaptcha(t *testing.T) {
+	key, token := "key-1", "token-1"
+	first, err := sendContactInfoHash(requestWithFile("drawing.pdf", "%PDF"))
+	if err != nil {
+		t.Fatalf("sendContactInfoHash: %v", err)
+	}
+	retry := requestWithFile("drawing.pdf", "%PDF")
+	retry.IdempotencyKey, retry.CaptchaToken = &key, &token
+	second, err := sendContactInfoHash(retry)
+	if err != nil {
+		t.Fatalf("sendContactInfoHash: %v", err)
+	}
+	if first != second {
+		t.Error("the idempotency key or captcha token changed the hash")
+	}
+}
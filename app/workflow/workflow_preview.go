@@ -0,0 +1,34 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/graph/model"
+)
+
+func (impl *workflowGraphQLServiceDepsImpl) PreviewNotification(ctx context.Context, source model.WebsiteSource, channel model.NotificationChannel, input model.ContactInfoInput) (*model.NotificationPreview, error) {
+	impl.deps.Logger.Info("PreviewNotification workflow started",
+		zap.String("source", source.String()),
+		zap.String("channel", channel.String()),
+	)
+
+	result, err := impl.deps.Controller.PreviewNotification(ctx, source, channel, &input)
+	if err != nil {
+		impl.deps.Logger.Error("PreviewNotification workflow failed",
+			zap.String("source", source.String()),
+			zap.String("channel", channel.String()),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("workflow error: %w", err)
+	}
+
+	impl.deps.Logger.Info("PreviewNotification workflow completed",
+		zap.String("source", source.String()),
+		zap.String("channel", channel.String()),
+	)
+
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package workflow
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/spam"
+	"sct-backend-service/graph/model"
+	"sct-backend-service/internal/middleware"
+)
+
+// scoreContacts runs the spam checks on every contact, the verdicts are indexed like input.ContactInfo
+func (impl *workflowGraphQLServiceDepsImpl) scoreContacts(ctx context.Context, input model.SendContactInfoRequest) []spam.Verdict {
+	clientIP, _ := middleware.GetClientIP(ctx)
+
+	verdicts := make([]spam.Verdict, len(input.ContactInfo))
+	for index, contact := range input.ContactInfo {
+		verdicts[index] = impl.deps.SpamScorer.Score(ctx, spam.Submission{
+			Source:   input.Source,
+			Contact:  contact,
+			ClientIP: clientIP,
+		})
+		if verdicts[index].Spam {
+			impl.deps.Logger.Info("Contact flagged as spam",
+				zap.Int("index", index),
+				zap.String("source", input.Source.String()),
+				zap.Float64("spam_score", verdicts[index].Score),
+				zap.Strings("spam_reasons", verdicts[index].Reasons),
+			)
+		}
+	}
+	return verdicts
+}
+
+func (impl *workflowGraphQLServiceDepsImpl) ReleaseEnquiry(ctx context.Context, input model.ReleaseEnquiryInput) (*model.Enquiry, error) {
+	impl.deps.Logger.Info("ReleaseEnquiry workflow started",
+		zap.String("enquiry_id", input.ID),
+	)
+
+	reason := strings.TrimSpace(input.Reason)
+	if reason == "" {
+		return nil, fmt.Errorf("a reason is required to release an enquiry")
+	}
+
+	enquiry, err := impl.deps.Controller.Enquiry(ctx, input.ID)
+	if err != nil {
+		impl.deps.Logger.Error("ReleaseEnquiry workflow failed",
+			zap.String("enquiry_id", input.ID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("workflow error: %w", err)
+	}
+	if enquiry == nil {
+		return nil, fmt.Errorf("enquiry %s not found", input.ID)
+	}
+	if enquiry.Status != model.EnquiryStatusSpam {
+		return nil, fmt.Errorf("only SPAM enquiries can be released, enquiry %s is %s", input.ID, enquiry.Status)
+	}
+
+	result, err := impl.deps.Controller.ReleaseEnquiry(ctx, input.ID, reason)
+	if err != nil {
+		impl.deps.Logger.Error("ReleaseEnquiry workflow failed",
+			zap.String("enquiry_id", input.ID),
+			zap.Error(err),
+		)
+		return nil, fmt.Errorf("workflow error: %w", err)
+	}
+
+	impl.deps.Logger.Info("ReleaseEnquiry workflow completed",
+		zap.String("enquiry_id", input.ID),
+	)
+
+	return result, nil
+}
@@ -0,0 +1,99 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/keys"
+	"sct-backend-service/app/phone"
+	"sct-backend-service/app/validation"
+	"sct-backend-service/graph/model"
+	"sct-backend-service/internal/middleware"
+)
+
+// validateContactInfo checks the request against the declared field rules and reports
+// one GraphQL error per offending field
+func (impl *workflowGraphQLServiceDepsImpl) validateContactInfo(ctx context.Context, input model.SendContactInfoRequest) error {
+	errs := validation.ValidateSendContactInfo(input)
+	errs = append(errs, impl.validatePhoneNumbers(input, errs)...)
+	errs = append(errs, impl.validateAttachments(input)...)
+	if len(errs) == 0 {
+		return nil
+	}
+
+	impl.deps.Logger.Info("SendContactInfo rejected by validation",
+		zap.String("source", input.Source.String()),
+		zap.Int("error_count", len(errs)),
+	)
+	gqlErrs := make([]*gqlerror.Error, len(errs))
+	for i, err := range errs {
+		gqlErrs[i] = middleware.NewLocalizedFieldError(ctx, err.Code, err.Path, err.Message, err.Args...)
+	}
+	return middleware.ReportGraphQLErrors(ctx, gqlErrs)
+}
+
+// validatePhoneNumbers parses the phone numbers with the default region of the source.
+// Numbers that already failed a rule are skipped.
+func (impl *workflowGraphQLServiceDepsImpl) validatePhoneNumbers(input model.SendContactInfoRequest, ruleErrs validation.Errors) validation.Errors {
+	failed := make(map[string]bool, len(ruleErrs))
+	for _, err := range ruleErrs {
+		failed[err.Path] = true
+	}
+
+	var errs validation.Errors
+	for index, contact := range input.ContactInfo {
+		path := fmt.Sprintf("contactInfo[%d].phoneNumber", index)
+		if failed[path] {
+			continue
+		}
+		if _, err := impl.deps.PhoneParser.Parse(input.Source.String(), contact.PhoneNumber); err != nil {
+			fieldErr := &validation.FieldError{Path: path, Code: keys.ErrCodeInvalidPhone, Message: "must be a valid phone number"}
+			if errors.Is(err, phone.ErrUnknownRegion) {
+				fieldErr.Message = "must include the country code, e.g. %s"
+				fieldErr.Args = []interface{}{"+971 50 123 4567"}
+			}
+			errs = append(errs, fieldErr)
+		}
+	}
+	return errs
+}
+
+// validateAttachments checks the number of files of each contact, the size and type of every file
+// and the total size of the files of all contacts
+func (impl *workflowGraphQLServiceDepsImpl) validateAttachments(input model.SendContactInfoRequest) validation.Errors {
+	limits := impl.deps.Attachments
+
+	var errs validation.Errors
+	var total int64
+	for index, contact := range input.ContactInfo {
+		if len(contact.Attachments) == 0 {
+			continue
+		}
+		path := fmt.Sprintf("contactInfo[%d].attachments", index)
+		if violation := limits.CheckCount(len(contact.Attachments)); violation != nil {
+			errs = append(errs, fieldError(path, violation))
+			continue
+		}
+		for fileIndex, upload := range contact.Attachments {
+			total += upload.Size
+			if violation := limits.CheckFile(upload); violation != nil {
+				errs = append(errs, fieldError(fmt.Sprintf("%s[%d]", path, fileIndex), violation))
+			}
+		}
+	}
+	if len(errs) == 0 && total > 0 {
+		if violation := limits.CheckTotal(total); violation != nil {
+			errs = append(errs, fieldError("contactInfo", violation))
+		}
+	}
+	return errs
+}
+
+// fieldError reports the violation at the input path
+func fieldError(path string, violation *validation.Violation) *validation.FieldError {
+	return &validation.FieldError{Path: path, Code: violation.Code, Message: violation.Message, Args: violation.Args}
+}
@@ -0,0 +1,114 @@
+package workflow
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/attachment"
+	"sct-backend-service/app/captcha"
+	"sct-backend-service/app/keys"
+	"sct-backend-service/app/phone"
+	"sct-backend-service/graph/model"
+)
+
+func validContact() *model.ContactInfoInput {
+	return &model.ContactInfoInput{
+		Name:        "Jane Doe",
+		Email:       "jane@example.com",
+		PhoneNumber: "+971 50 123 4567",
+		CompanyName: "Acme",
+		Subject:     "RFQ pumps",
+		Message:     "Please quote 10 pumps.",
+	}
+}
+
+func pdfUpload(size int64) *graphql.Upload {
+	return &graphql.Upload{File: strings.NewReader("%PDF-1.7\n"), Filename: "drawing.pdf", Size: size, ContentType: "application/pdf"}
+}
+
+// newValidatingService accepts one PDF of up to 1 KB per contact. It has no Controller,
+// so the test panics if an invalid request gets past validation.
+func newValidatingService(t *testing.T, maxTotalSize int64) WorkflowGraphQLService {
+	t.Helper()
+	parser, err := phone.NewParser(nil)
+	if err != nil {
+		t.Fatalf("NewParser: %v", err)
+	}
+	return CreateWorkflowGraphQLService(WorkflowGraphQLServiceDeps{
+		Logger:      zap.NewNop(),
+		Captcha:     captcha.NewRegistry(nil),
+		PhoneParser: parser,
+		Attachments: attachment.Limits{MaxFiles: 1, MaxFileSize: 1024, MaxTotalSize: maxTotalSize, AllowedTypes: []string{"application/pdf"}},
+	})
+}
+
+func TestSendContactInfoReportsEveryValidationError(t *testing.T) {
+	service := newValidatingService(t, 4096)
+
+	blankEmail := validContact()
+	blankEmail.Email = " "
+	// A national number cannot be read without a default region for the source
+	nationalPhone := validContact()
+	nationalPhone.PhoneNumber = "050 123 4567"
+	tooMany := validContact()
+	tooMany.Attachments = []*graphql.Upload{pdfUpload(10), pdfUpload(10)}
+	tooLarge := validContact()
+	tooLarge.Attachments = []*graphql.Upload{pdfUpload(2048)}
+
+	ctx := graphql.WithResponseContext(context.Background(), graphql.DefaultErrorPresenter, graphql.DefaultRecover)
+	_, err := service.SendContactInfo(ctx, model.SendContactInfoRequest{
+		Source:      model.WebsiteSourceSctgulf,
+		ContactInfo: []*model.ContactInfoInput{validContact(), blankEmail, nationalPhone, tooMany, tooLarge},
+	})
+
+	var last *gqlerror.Error
+	if !errors.As(err, &last) {
+		t.Fatalf("SendContactInfo = %v, want a GraphQL error", err)
+	}
+	reported := append(graphql.GetErrors(ctx), last)
+
+	want := []struct{ field, code, message string }{
+		{"contactInfo[1].email", keys.ErrCodeRequired, "contactInfo[1].email is required"},
+		{"contactInfo[2].phoneNumber", keys.ErrCodeInvalidPhone, "contactInfo[2].phoneNumber must include the country code, e.g. +971 50 123 4567"},
+		{"contactInfo[3].attachments", keys.ErrCodeTooManyAttachments, "contactInfo[3].attachments may contain at most 1 files"},
+		{"contactInfo[4].attachments[0]", keys.ErrCodeAttachmentTooLarge, "contactInfo[4].attachments[0] must be at most 1 KB"},
+	}
+	if len(reported) != len(want) {
+		t.Fatalf("reported errors = %v, want %d", reported, len(want))
+	}
+	for i, w := range want {
+		got := reported[i]
+		if got.Extensions["field"] != w.field || got.Extensions["code"] != w.code || got.Message != w.message {
+			t.Errorf("error %d = %s %v %q, want %s %s %q", i, got.Extensions["field"], got.Extensions["code"], got.Message, w.field, w.code, w.message)
+		}
+	}
+}
+
+func TestSendContactInfoChecksTotalAttachmentSize(t *testing.T) {
+	service := newValidatingService(t, 1500)
+
+	// Each file is within the limit, together they are not
+	first, second := validContact(), validContact()
+	first.Attachments = []*graphql.Upload{pdfUpload(1000)}
+	second.Attachments = []*graphql.Upload{pdfUpload(1000)}
+
+	ctx := graphql.WithResponseContext(context.Background(), graphql.DefaultErrorPresenter, graphql.DefaultRecover)
+	_, err := service.SendContactInfo(ctx, model.SendContactInfoRequest{
+		Source:      model.WebsiteSourceSctgulf,
+		ContactInfo: []*model.ContactInfoInput{first, second},
+	})
+
+	var gqlErr *gqlerror.Error
+	if !errors.As(err, &gqlErr) || gqlErr.Extensions["field"] != "contactInfo" || gqlErr.Extensions["code"] != keys.ErrCodeAttachmentTooLarge {
+		t.Errorf("SendContactInfo = %v, want a contactInfo %s error", err, keys.ErrCodeAttachmentTooLarge)
+	}
+	if errs := graphql.GetErrors(ctx); len(errs) != 0 {
+		t.Errorf("more errors were reported: %v", errs)
+	}
+}
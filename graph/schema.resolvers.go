@@ -18,7 +18,83 @@ func (r *mutationResolver) SendContactInfo(ctx context.Context, input model.Send
 	return r.Workflow.SendContactInfo(ctx, input)
 }
 
+// UpdateEnquiryStatus is the resolver for the updateEnquiryStatus field.
+func (r *mutationResolver) UpdateEnquiryStatus(ctx context.Context, input model.UpdateEnquiryStatusInput) (*model.Enquiry, error) {
+	ctx = middleware.UpdateContext(ctx)
+	if err := middleware.RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return r.Workflow.UpdateEnquiryStatus(ctx, input)
+}
+
+// ReleaseEnquiry is the resolver for the releaseEnquiry field.
+func (r *mutationResolver) ReleaseEnquiry(ctx context.Context, input model.ReleaseEnquiryInput) (*model.Enquiry, error) {
+	ctx = middleware.UpdateContext(ctx)
+	if err := middleware.RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return r.Workflow.ReleaseEnquiry(ctx, input)
+}
+
+// AddEnquiryNote is the resolver for the addEnquiryNote field.
+func (r *mutationResolver) AddEnquiryNote(ctx context.Context, input model.AddEnquiryNoteInput) (*model.Enquiry, error) {
+	ctx = middleware.UpdateContext(ctx)
+	if err := middleware.RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return r.Workflow.AddEnquiryNote(ctx, input)
+}
+
+// AssignEnquiry is the resolver for the assignEnquiry field.
+func (r *mutationResolver) AssignEnquiry(ctx context.Context, input model.AssignEnquiryInput) (*model.Enquiry, error) {
+	ctx = middleware.UpdateContext(ctx)
+	if err := middleware.RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return r.Workflow.AssignEnquiry(ctx, input)
+}
+
+// Enquiries is the resolver for the enquiries field.
+func (r *queryResolver) Enquiries(ctx context.Context, filter *model.EnquiryFilter, first *int, after *string) (*model.EnquiryConnection, error) {
+	ctx = middleware.UpdateContext(ctx)
+	if err := middleware.RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return r.Workflow.Enquiries(ctx, filter, first, after)
+}
+
+// Enquiry is the resolver for the enquiry field.
+func (r *queryResolver) Enquiry(ctx context.Context, id string) (*model.Enquiry, error) {
+	ctx = middleware.UpdateContext(ctx)
+	if err := middleware.RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return r.Workflow.Enquiry(ctx, id)
+}
+
+// EnquiryStats is the resolver for the enquiryStats field.
+func (r *queryResolver) EnquiryStats(ctx context.Context, filter *model.EnquiryFilter) (*model.EnquiryStats, error) {
+	ctx = middleware.UpdateContext(ctx)
+	if err := middleware.RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return r.Workflow.EnquiryStats(ctx, filter)
+}
+
+// PreviewNotification is the resolver for the previewNotification field.
+func (r *queryResolver) PreviewNotification(ctx context.Context, source model.WebsiteSource, channel model.NotificationChannel, input model.ContactInfoInput) (*model.NotificationPreview, error) {
+	ctx = middleware.UpdateContext(ctx)
+	if err := middleware.RequireAuth(ctx); err != nil {
+		return nil, err
+	}
+	return r.Workflow.PreviewNotification(ctx, source, channel, input)
+}
+
 // Mutation returns generated.MutationResolver implementation.
 func (r *Resolver) Mutation() generated.MutationResolver { return &mutationResolver{r} }
 
+// Query returns generated.QueryResolver implementation.
+func (r *Resolver) Query() generated.QueryResolver { return &queryResolver{r} }
+
 type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
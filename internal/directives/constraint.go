@@ -0,0 +1,92 @@
+package directives
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"sct-backend-service/app/validation"
+	"sct-backend-service/internal/middleware"
+)
+
+// ConstraintFunc is the signature gqlgen expects for @constraint
+type ConstraintFunc func(ctx context.Context, obj any, next graphql.Resolver, minLength *int, maxLength *int, format *string, pattern *string) (any, error)
+
+// Constraint implements @constraint. It runs while the input is decoded, so a violation fails
+// the field before its resolver runs, with the input path of the value in the field extension.
+func Constraint() ConstraintFunc {
+	var cache sync.Map // validation.Constraint -> []validation.Rule
+
+	return func(ctx context.Context, obj any, next graphql.Resolver, minLength *int, maxLength *int, format *string, pattern *string) (any, error) {
+		value, err := next(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		var s string
+		switch v := value.(type) {
+		case string:
+			s = v
+		case *string:
+			if v == nil {
+				return value, nil
+			}
+			s = *v
+		default:
+			return nil, fmt.Errorf("@constraint only supports strings, got %T", value)
+		}
+
+		constraint := validation.Constraint{
+			MinLength: intValue(minLength),
+			MaxLength: intValue(maxLength),
+			Format:    stringValue(format),
+			Pattern:   stringValue(pattern),
+		}
+		rules, ok := cache.Load(constraint)
+		if !ok {
+			compiled, err := constraint.Rules()
+			if err != nil {
+				return nil, fmt.Errorf("invalid @constraint: %w", err)
+			}
+			rules, _ = cache.LoadOrStore(constraint, compiled)
+		}
+
+		for _, rule := range rules.([]validation.Rule) {
+			if violation := rule.Check(s); violation != nil {
+				path := inputPath(ctx)
+				return nil, middleware.NewLocalizedFieldError(ctx, violation.Code, path, violation.Message, violation.Args...)
+			}
+		}
+		return value, nil
+	}
+}
+
+// inputPath returns the path of the value inside the field arguments, e.g. contactInfo[2].email.
+// The argument name is left out when the value is nested in it.
+func inputPath(ctx context.Context) string {
+	path := graphql.GetPath(ctx)
+	if fc := graphql.GetFieldContext(ctx); fc != nil && len(path) > len(fc.Path()) {
+		path = path[len(fc.Path()):]
+	}
+	if len(path) > 1 {
+		path = path[1:]
+	}
+	return ast.Path(path).String()
+}
+
+func intValue(i *int) int {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
+
+func stringValue(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,166 @@
+package directives
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"sct-backend-service/app/keys"
+	"sct-backend-service/app/workflow"
+	"sct-backend-service/graph"
+	"sct-backend-service/graph/generated"
+	"sct-backend-service/graph/model"
+)
+
+// acceptingWorkflow answers sendContactInfo with success, other operations are not used
+type acceptingWorkflow struct {
+	workflow.WorkflowGraphQLService
+	called bool
+}
+
+func (w *acceptingWorkflow) SendContactInfo(ctx context.Context, input model.SendContactInfoRequest) (*model.SendContactInfoResponse, error) {
+	w.called = true
+	return &model.SendContactInfoResponse{IsSuccess: true, Results: []*model.ContactInfoResult{}}, nil
+}
+
+// execute runs the query against the schema with @constraint and without rate limits
+func execute(t *testing.T, service workflow.WorkflowGraphQLService, query string, variables map[string]any) graphqlResponse {
+	t.Helper()
+	schema := generated.NewExecutableSchema(generated.Config{
+		Resolvers: &graph.Resolver{Workflow: service},
+		Directives: generated.DirectiveRoot{
+			Constraint: Constraint(),
+			RateLimit: func(ctx context.Context, obj any, next graphql.Resolver, limit int, window string, key model.RateLimitKey) (any, error) {
+				return next(ctx)
+			},
+		},
+	})
+	server := handler.New(schema)
+	server.AddTransport(transport.POST{})
+
+	body, err := json.Marshal(map[string]any{"query": query, "variables": variables})
+	if err != nil {
+		t.Fatalf("Marshal: %v", err)
+	}
+	request := httptest.NewRequest(http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+
+	var response graphqlResponse
+	if err := json.Unmarshal(recorder.Body.Bytes(), &response); err != nil {
+		t.Fatalf("Unmarshal %s: %v", recorder.Body.String(), err)
+	}
+	return response
+}
+
+type graphqlResponse struct {
+	Errors []struct {
+		Message    string         `json:"message"`
+		Extensions map[string]any `json:"extensions"`
+	} `json:"errors"`
+}
+
+const sendContactInfoMutation = `mutation($input: SendContactInfoRequest!) { sendContactInfo(input: $input) { isSuccess } }`
+
+// contactVariables builds the input of sendContactInfoMutation with the given contacts
+func contactVariables(contacts ...map[string]any) map[string]any {
+	return map[string]any{"input": map[string]any{"source": "SCTGULF", "contactInfo": contacts}}
+}
+
+func validContact() map[string]any {
+	return map[string]any{
+		"name":        "Jane Doe",
+		"email":       "jane@example.com",
+		"phoneNumber": "+971 50 123 4567",
+		"companyName": "Acme",
+		"subject":     "RFQ pumps",
+		"message":     "Please quote 10 pumps",
+	}
+}
+
+func TestConstraintReportsInputPath(t *testing.T) {
+	tests := []struct {
+		name      string
+		field     string
+		value     any
+		wantCode  string
+		wantField string
+	}{
+		{"invalid email of the second contact", "email", "not-an-email", keys.ErrCodeInvalidEmail, "contactInfo[1].email"},
+		{"short name", "name", "J", keys.ErrCodeTooShort, "contactInfo[1].name"},
+		{"invalid phone", "phoneNumber", "call me", keys.ErrCodeInvalidPhone, "contactInfo[1].phoneNumber"},
+		{"long subject", "subject", strings.Repeat("a", 201), keys.ErrCodeTooLong, "contactInfo[1].subject"},
+		{"invalid country", "country", "UAE", keys.ErrCodeInvalidFormat, "contactInfo[1].country"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			invalid := validContact()
+			invalid[tt.field] = tt.value
+			service := &acceptingWorkflow{}
+			response := execute(t, service, sendContactInfoMutation, contactVariables(validContact(), invalid))
+
+			if service.called {
+				t.Error("the resolver ran despite the violation")
+			}
+			if len(response.Errors) != 1 {
+				t.Fatalf("errors = %+v, want one", response.Errors)
+			}
+			extensions := response.Errors[0].Extensions
+			if extensions["code"] != tt.wantCode || extensions["field"] != tt.wantField {
+				t.Errorf("extensions = %v, want code %s at %s", extensions, tt.wantCode, tt.wantField)
+			}
+		})
+	}
+}
+
+func TestConstraintAcceptsValidInput(t *testing.T) {
+	country := validContact()
+	country["country"] = "AE"
+	service := &acceptingWorkflow{}
+	response := execute(t, service, sendContactInfoMutation, contactVariables(validContact(), country))
+	if len(response.Errors) != 0 || !service.called {
+		t.Errorf("errors = %+v, want the request to reach the resolver", response.Errors)
+	}
+}
+
+func TestInputPath(t *testing.T) {
+	field := graphql.WithFieldContext(context.Background(), &graphql.FieldContext{
+		Field: graphql.CollectedField{Field: &ast.Field{Alias: "sendContactInfo"}},
+	})
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		path []any
+		want string
+	}{
+		{"argument", field, []any{"text"}, "text"},
+		{"field of the argument", field, []any{"input", "locale"}, "locale"},
+		{"nested in the argument", field, []any{"input", "contactInfo", 2, "email"}, "contactInfo[2].email"},
+		{"without field context", context.Background(), []any{"input", "contactInfo", 0, "name"}, "contactInfo[0].name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := tt.ctx
+			for _, element := range tt.path {
+				if index, ok := element.(int); ok {
+					ctx = graphql.WithPathContext(ctx, graphql.NewPathWithIndex(index))
+				} else {
+					ctx = graphql.WithPathContext(ctx, graphql.NewPathWithField(element.(string)))
+				}
+			}
+			if got := inputPath(ctx); got != tt.want {
+				t.Errorf("inputPath = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
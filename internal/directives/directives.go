@@ -1,12 +1,20 @@
 package directives
 
-// GraphQL directives can be implemented here
-// For example: @auth, @hasRole, @rateLimit, etc.
+import (
+	"sct-backend-service/app/ratelimit"
+	"sct-backend-service/graph/generated"
+)
 
-// TODO: Implement GraphQL directives as needed
-// Examples:
-// - Auth directive for protected fields
-// - Rate limiting directive
-// - Cache directive
-// - Deprecation directive
+// Deps holds the services the directives need
+type Deps struct {
+	Limiter *ratelimit.Limiter
+}
 
+// NewDirectiveRoot creates the implementations of the schema directives.
+// Both the standalone server and the Vercel handler must use it, gqlgen fails fields whose directive is missing.
+func NewDirectiveRoot(deps Deps) generated.DirectiveRoot {
+	return generated.DirectiveRoot{
+		Constraint: Constraint(),
+		RateLimit:  RateLimit(deps.Limiter),
+	}
+}
@@ -0,0 +1,118 @@
+package directives
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"sct-backend-service/app/keys"
+	"sct-backend-service/app/ratelimit"
+	"sct-backend-service/graph/model"
+	"sct-backend-service/internal/middleware"
+)
+
+// RateLimitFunc is the signature gqlgen expects for @rateLimit
+type RateLimitFunc func(ctx context.Context, obj any, next graphql.Resolver, limit int, window string, key model.RateLimitKey) (any, error)
+
+// RateLimit implements @rateLimit. The bucket key combines the field with the value of the chosen key,
+// EMAIL takes a token for every distinct email in the arguments, all of them or none. Calls without a value for the key are not limited.
+func RateLimit(limiter *ratelimit.Limiter) RateLimitFunc {
+	return func(ctx context.Context, obj any, next graphql.Resolver, limit int, window string, key model.RateLimitKey) (any, error) {
+		duration, err := time.ParseDuration(window)
+		if err != nil {
+			return nil, fmt.Errorf("invalid @rateLimit window %q: %w", window, err)
+		}
+
+		fc := graphql.GetFieldContext(ctx)
+		values := rateLimitValues(ctx, fc.Args, key)
+		if len(values) == 0 {
+			return next(ctx)
+		}
+
+		// The buckets are taken together, a call rejected by one must not use up the others
+		bucketKeys := make([]string, len(values))
+		for i, value := range values {
+			bucketKeys[i] = fmt.Sprintf("%s.%s:%s:%s", fc.Object, fc.Field.Name, strings.ToLower(string(key)), value)
+		}
+		result, err := limiter.Allow(ctx, bucketKeys, limit, duration)
+		if err != nil {
+			return nil, fmt.Errorf("invalid @rateLimit on %s: %w", fc.Field.Name, err)
+		}
+		if !result.Allowed {
+			return nil, rateLimitedError(ctx, result.RetryAfter)
+		}
+
+		return next(ctx)
+	}
+}
+
+// rateLimitValues returns the values that identify the caller for key
+func rateLimitValues(ctx context.Context, args map[string]any, key model.RateLimitKey) []string {
+	switch key {
+	case model.RateLimitKeyIP:
+		if ip, ok := middleware.GetClientIP(ctx); ok {
+			return []string{ip}
+		}
+		return nil
+	case model.RateLimitKeyEmail:
+		return argumentValues(args, "email", strings.ToLower)
+	case model.RateLimitKeySource:
+		return argumentValues(args, "source", strings.ToUpper)
+	default:
+		return nil
+	}
+}
+
+// argumentValues collects the distinct non-empty string values of every argument field named name,
+// at any depth. Arguments are walked in their JSON form so the directive works on any input type.
+func argumentValues(args map[string]any, name string, normalize func(string) string) []string {
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return nil
+	}
+	var decoded any
+	if err := json.Unmarshal(encoded, &decoded); err != nil {
+		return nil
+	}
+
+	seen := make(map[string]bool)
+	var values []string
+	var walk func(v any)
+	walk = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			for field, child := range v {
+				if s, ok := child.(string); ok && field == name {
+					if s = normalize(strings.TrimSpace(s)); s != "" && !seen[s] {
+						seen[s] = true
+						values = append(values, s)
+					}
+					continue
+				}
+				walk(child)
+			}
+		case []any:
+			for _, child := range v {
+				walk(child)
+			}
+		}
+	}
+	walk(decoded)
+	return values
+}
+
+// rateLimitedError tells the client how many seconds to wait before retrying
+func rateLimitedError(ctx context.Context, retryAfter time.Duration) error {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	if seconds < 1 {
+		seconds = 1
+	}
+	err := middleware.NewLocalizedError(ctx, keys.ErrCodeRateLimited, "too many requests, retry in %d seconds", seconds)
+	err.Extensions["retryAfter"] = seconds
+	return err
+}
@@ -0,0 +1,173 @@
+package directives
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+	"go.uber.org/zap"
+
+	"sct-backend-service/app/keys"
+	"sct-backend-service/app/ratelimit"
+	"sct-backend-service/graph/model"
+)
+
+// recordingStore remembers the bucket keys taken from
+type recordingStore struct {
+	*ratelimit.MemoryStore
+	keys []string
+}
+
+func (s *recordingStore) Take(ctx context.Context, keys []string, limit int, window time.Duration, now time.Time) (ratelimit.Result, error) {
+	s.keys = append(s.keys, keys...)
+	return s.MemoryStore.Take(ctx, keys, limit, window, now)
+}
+
+// sendContactInfoContext is the field context of a sendContactInfo call with a contact per email
+func sendContactInfoContext(emails ...string) context.Context {
+	request := &model.SendContactInfoRequest{Source: model.WebsiteSourceSctgulf}
+	for _, email := range emails {
+		request.ContactInfo = append(request.ContactInfo, &model.ContactInfoInput{Name: "Jane", Email: email})
+	}
+	return graphql.WithFieldContext(context.Background(), &graphql.FieldContext{
+		Object: "Mutation",
+		Field:  graphql.CollectedField{Field: &ast.Field{Name: "sendContactInfo"}},
+		Args:   map[string]any{"input": request},
+	})
+}
+
+// emailRateLimit returns a call of sendContactInfo limited to 5 per hour and email
+func emailRateLimit(store ratelimit.Store) func(emails ...string) error {
+	rateLimit := RateLimit(ratelimit.NewLimiter(store, zap.NewNop()))
+	return func(emails ...string) error {
+		next := func(ctx context.Context) (any, error) { return true, nil }
+		_, err := rateLimit(sendContactInfoContext(emails...), nil, next, 5, "1h", model.RateLimitKeyEmail)
+		return err
+	}
+}
+
+func TestRateLimitEmailLimitsEveryEmailSeparately(t *testing.T) {
+	store := &recordingStore{MemoryStore: ratelimit.NewMemoryStore()}
+	call := emailRateLimit(store)
+
+	emails := make([]string, 6)
+	for i := range emails {
+		emails[i] = fmt.Sprintf("buyer%d@example.com", i+1)
+	}
+	// A 7th contact repeats the first email in other case, it shares its bucket
+	if err := call(append(emails, " BUYER1@Example.com ")...); err != nil {
+		t.Fatalf("first call: %v", err)
+	}
+	want := make([]string, len(emails))
+	for i, email := range emails {
+		want[i] = "Mutation.sendContactInfo:email:" + email
+	}
+	sort.Strings(store.keys)
+	if !reflect.DeepEqual(store.keys, want) {
+		t.Fatalf("bucket keys = %v, want one per distinct email %v", store.keys, want)
+	}
+
+	// Each email has its own 5 calls per hour, 6 emails in one request do not use up a shared bucket
+	for i := 2; i <= 5; i++ {
+		if err := call(emails...); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+	err := call(emails[5])
+	var gqlErr *gqlerror.Error
+	if !errors.As(err, &gqlErr) || gqlErr.Extensions["code"] != keys.ErrCodeRateLimited {
+		t.Fatalf("6th call for %s = %v, want %s", emails[5], err, keys.ErrCodeRateLimited)
+	}
+	// 5 tokens per hour refill one every 12 minutes
+	if got := gqlErr.Extensions["retryAfter"]; got != 720 {
+		t.Errorf("retryAfter = %v, want 720", got)
+	}
+
+	if err := call("someone.else@example.com"); err != nil {
+		t.Errorf("an email that was not used is limited: %v", err)
+	}
+}
+
+func TestRateLimitEmailRejectionTakesNoTokens(t *testing.T) {
+	call := emailRateLimit(ratelimit.NewMemoryStore())
+	for i := 1; i <= 5; i++ {
+		if err := call("busy@example.com"); err != nil {
+			t.Fatalf("call %d: %v", i, err)
+		}
+	}
+
+	// Rejected for the busy email, the other email of the request keeps its tokens
+	for i := 1; i <= 3; i++ {
+		if err := call("fresh@example.com", "busy@example.com"); err == nil {
+			t.Fatalf("call %d with an exhausted email was allowed", i)
+		}
+	}
+	for i := 1; i <= 5; i++ {
+		if err := call("fresh@example.com"); err != nil {
+			t.Fatalf("call %d for fresh@example.com: %v, want the rejected calls to have taken nothing", i, err)
+		}
+	}
+}
+
+func TestRateLimitSkipsCallsWithoutKeyValue(t *testing.T) {
+	store := &recordingStore{MemoryStore: ratelimit.NewMemoryStore()}
+	next := func(ctx context.Context) (any, error) { return true, nil }
+	// No client IP in the context
+	if _, err := RateLimit(ratelimit.NewLimiter(store, zap.NewNop()))(sendContactInfoContext("jane@example.com"), nil, next, 1, "1m", model.RateLimitKeyIP); err != nil {
+		t.Fatalf("RateLimit: %v", err)
+	}
+	if len(store.keys) != 0 {
+		t.Errorf("took from %v, want no bucket", store.keys)
+	}
+}
+
+func TestRateLimitedErrorRoundsUp(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       int
+	}{
+		{0, 1},
+		{time.Millisecond, 1},
+		{time.Second, 1},
+		{time.Second + time.Millisecond, 2},
+		{2333334 * time.Microsecond, 3},
+		{12 * time.Minute, 720},
+	}
+	for _, tt := range tests {
+		err := rateLimitedError(context.Background(), tt.retryAfter)
+		if got := err.(*gqlerror.Error).Extensions["retryAfter"]; got != tt.want {
+			t.Errorf("retryAfter for %s = %v, want %d", tt.retryAfter, got, tt.want)
+		}
+	}
+}
+
+func TestArgumentValues(t *testing.T) {
+	args := map[string]any{
+		"input": map[string]any{
+			"source": "sctgulf",
+			"contactInfo": []any{
+				map[string]any{"email": "Jane@Example.com"},
+				map[string]any{"email": " jane@example.com "},
+				map[string]any{"email": ""},
+				map[string]any{"email": "sam@example.com", "nested": map[string]any{"email": "ops@example.com"}},
+			},
+		},
+	}
+	got := argumentValues(args, "email", strings.ToLower)
+	sort.Strings(got)
+	want := []string{"jane@example.com", "ops@example.com", "sam@example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("argumentValues = %v, want %v", got, want)
+	}
+	if got := argumentValues(args, "source", strings.ToUpper); !reflect.DeepEqual(got, []string{"SCTGULF"}) {
+		t.Errorf("source values = %v, want [SCTGULF]", got)
+	}
+}
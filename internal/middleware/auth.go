@@ -2,24 +2,49 @@ package middleware
 
 import (
 	"context"
+	"crypto/subtle"
 	"net/http"
+	"os"
+	"strings"
+
+	"sct-backend-service/app/keys"
 )
 
 // AuthMiddleware handles authentication
+// Requests carrying the admin bearer token get the admin user ID added to their context.
+// Other requests pass through unauthenticated, public operations do not need a token.
 func AuthMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		// TODO: Implement authentication logic
-		// Extract token, validate, add user info to context
 		ctx := r.Context()
 
-		// For now, just pass through
+		if isAdminToken(bearerToken(r)) {
+			ctx = WithUserID(ctx, keys.AdminUserID)
+		}
+
 		next.ServeHTTP(w, r.WithContext(ctx))
 	})
 }
 
+// bearerToken extracts the token from the Authorization header
+func bearerToken(r *http.Request) string {
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return ""
+	}
+	return strings.TrimSpace(token)
+}
+
+// isAdminToken compares the token with the configured admin token in constant time
+func isAdminToken(token string) bool {
+	adminToken := os.Getenv(keys.AdminAPITokenEnvKey)
+	if adminToken == "" || token == "" {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
+
 // RequireAuth ensures the request is authenticated
 func RequireAuth(ctx context.Context) error {
-	// TODO: Check if user is authenticated
 	_, ok := GetUserID(ctx)
 	if !ok {
 		return ErrUnauthenticated
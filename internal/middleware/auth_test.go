@@ -0,0 +1,70 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"sct-backend-service/app/keys"
+)
+
+func TestAuthMiddleware(t *testing.T) {
+	t.Setenv(keys.AdminAPITokenEnvKey, "s3cret")
+	tests := []struct {
+		name          string
+		authorization string
+		wantAdmin     bool
+	}{
+		{"admin token", "Bearer s3cret", true},
+		{"scheme ignores case", "bearer s3cret", true},
+		{"wrong token", "Bearer guess", false},
+		{"token prefix", "Bearer s3c", false},
+		{"other scheme", "Basic s3cret", false},
+		{"no scheme", "s3cret", false},
+		{"no header", "", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var authenticated bool
+			handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				authenticated = RequireAuth(r.Context()) == nil
+			}))
+			r := httptest.NewRequest(http.MethodPost, "/query", nil)
+			if tt.authorization != "" {
+				r.Header.Set("Authorization", tt.authorization)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if authenticated != tt.wantAdmin {
+				t.Errorf("authenticated = %v, want %v", authenticated, tt.wantAdmin)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareWithoutAdminToken(t *testing.T) {
+	// Without a configured token no request is an admin, not even one with an empty bearer token
+	t.Setenv(keys.AdminAPITokenEnvKey, "")
+	var err error
+	handler := AuthMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		err = RequireAuth(r.Context())
+	}))
+	r := httptest.NewRequest(http.MethodPost, "/query", nil)
+	r.Header.Set("Authorization", "Bearer ")
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("RequireAuth = %v, want ErrUnauthenticated", err)
+	}
+}
+
+func TestRequireAuth(t *testing.T) {
+	if err := RequireAuth(context.Background()); !errors.Is(err, ErrUnauthenticated) {
+		t.Errorf("without a user: %v, want ErrUnauthenticated", err)
+	}
+	if err := RequireAuth(WithUserID(context.Background(), keys.AdminUserID)); err != nil {
+		t.Errorf("with a user: %v", err)
+	}
+}
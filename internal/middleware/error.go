@@ -1,9 +1,15 @@
 package middleware
 
 import (
+	"context"
 	"encoding/json"
 	"log"
 	"net/http"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/gqlerror"
+
+	"sct-backend-service/app/i18n"
 )
 
 // ErrorHandler handles errors in HTTP requests
@@ -51,3 +57,49 @@ func WriteGraphQLError(w http.ResponseWriter, errors []error) {
 	}
 }
 
+
+// NewGraphQLError creates a GraphQL error carrying a machine-readable code in its extensions
+func NewGraphQLError(code, message string) *gqlerror.Error {
+	return &gqlerror.Error{
+		Message: message,
+		Extensions: map[string]interface{}{
+			"code": code,
+		},
+	}
+}
+
+// NewFieldError creates a GraphQL error for a single input field, the field extension holds its input path
+func NewFieldError(code, field, message string) *gqlerror.Error {
+	err := NewGraphQLError(code, message)
+	err.Extensions["field"] = field
+	return err
+}
+
+// NewLocalizedError creates a GraphQL error whose message is translated into the request's locale,
+// the locale extension names the language of the message
+func NewLocalizedError(ctx context.Context, code, format string, args ...interface{}) *gqlerror.Error {
+	locale := Locale(ctx)
+	err := NewGraphQLError(code, i18n.Sprintf(locale, format, args...))
+	err.Extensions["locale"] = locale
+	return err
+}
+
+// NewLocalizedFieldError creates a GraphQL error for a single input field in the request's locale, see NewLocalizedError
+func NewLocalizedFieldError(ctx context.Context, code, field, format string, args ...interface{}) *gqlerror.Error {
+	locale := Locale(ctx)
+	err := NewFieldError(code, field, i18n.FieldMessage(locale, field, i18n.Sprintf(locale, format, args...)))
+	err.Extensions["locale"] = locale
+	return err
+}
+
+// ReportGraphQLErrors sends every error to the client. A resolver can only return one error,
+// so all but the last are added to the response and the last is returned for the resolver to fail with.
+func ReportGraphQLErrors(ctx context.Context, errs []*gqlerror.Error) error {
+	if len(errs) == 0 {
+		return nil
+	}
+	for _, err := range errs[:len(errs)-1] {
+		graphql.AddError(ctx, err)
+	}
+	return errs[len(errs)-1]
+}
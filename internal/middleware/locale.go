@@ -0,0 +1,35 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/99designs/gqlgen/graphql"
+
+	"sct-backend-service/app/i18n"
+)
+
+// localeField is the input field in which a client names its locale explicitly
+const localeField = "locale"
+
+// Locale returns the locale of the request: the locale field of the current GraphQL field's input
+// when the client set one, otherwise the best match for the Accept-Language header.
+// The input is read from the raw arguments, so errors found while decoding them are localized too.
+func Locale(ctx context.Context) string {
+	if tag := inputLocale(ctx); tag != "" {
+		return i18n.Negotiate(tag)
+	}
+	language, _ := GetAcceptLanguage(ctx)
+	return i18n.Negotiate(language)
+}
+
+// inputLocale returns the locale field of the input argument of the field being resolved, if any
+func inputLocale(ctx context.Context) string {
+	fc := graphql.GetFieldContext(ctx)
+	if fc == nil || fc.Field.Field == nil || !graphql.HasOperationContext(ctx) {
+		return ""
+	}
+	args := fc.Field.ArgumentMap(graphql.GetOperationContext(ctx).Variables)
+	input, _ := args["input"].(map[string]interface{})
+	tag, _ := input[localeField].(string)
+	return tag
+}
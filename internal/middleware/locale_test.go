@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/vektah/gqlparser/v2/ast"
+
+	"sct-backend-service/app/i18n"
+	"sct-backend-service/app/keys"
+)
+
+// resolverContext is the context of a resolver whose input variable holds the values
+func resolverContext(ctx context.Context, input map[string]interface{}) context.Context {
+	ctx = graphql.WithOperationContext(ctx, &graphql.OperationContext{
+		Variables: map[string]interface{}{"input": input},
+	})
+	return graphql.WithFieldContext(ctx, &graphql.FieldContext{
+		Field: graphql.CollectedField{Field: &ast.Field{
+			Arguments:  ast.ArgumentList{{Name: "input", Value: &ast.Value{Kind: ast.Variable, Raw: "input"}}},
+			Definition: &ast.FieldDefinition{Arguments: ast.ArgumentDefinitionList{{Name: "input"}}},
+		}},
+	})
+}
+
+func TestLocale(t *testing.T) {
+	tests := []struct {
+		name           string
+		acceptLanguage string
+		input          map[string]interface{}
+		want           string
+	}{
+		{"default", "", nil, i18n.DefaultLocale},
+		{"accept language", "ar-AE,en;q=0.5", nil, i18n.Arabic},
+		{"input locale wins", "en-US", map[string]interface{}{"locale": "ar"}, i18n.Arabic},
+		{"input locale is negotiated", "ar", map[string]interface{}{"locale": "fr"}, i18n.DefaultLocale},
+		{"input without locale", "ar", map[string]interface{}{"name": "Jane"}, i18n.Arabic},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			handler := RequestMetadataMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				ctx := r.Context()
+				if tt.input != nil {
+					ctx = resolverContext(ctx, tt.input)
+				}
+				got = Locale(ctx)
+			}))
+			r := httptest.NewRequest(http.MethodPost, "/query", nil)
+			if tt.acceptLanguage != "" {
+				r.Header.Set("Accept-Language", tt.acceptLanguage)
+			}
+			handler.ServeHTTP(httptest.NewRecorder(), r)
+
+			if got != tt.want {
+				t.Errorf("Locale = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewLocalizedFieldError(t *testing.T) {
+	ctx := resolverContext(context.Background(), map[string]interface{}{"locale": "ar"})
+	err := NewLocalizedFieldError(ctx, keys.ErrCodeTooShort, "contactInfo[0].name", "must be at least %d characters", 2)
+
+	if err.Message != "الاسم يجب ألا يقل عن 2 أحرف" {
+		t.Errorf("message = %q", err.Message)
+	}
+	if err.Extensions["code"] != keys.ErrCodeTooShort || err.Extensions["field"] != "contactInfo[0].name" || err.Extensions["locale"] != i18n.Arabic {
+		t.Errorf("extensions = %v", err.Extensions)
+	}
+}
@@ -0,0 +1,80 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	"sct-backend-service/app/keys"
+)
+
+// IdempotencyKeyHeader is the request header that carries an idempotency key
+const IdempotencyKeyHeader = "Idempotency-Key"
+
+type requestContextKey string
+
+const (
+	idempotencyKeyContextKey requestContextKey = "idempotency_key"
+	clientIPContextKey       requestContextKey = "client_ip"
+	acceptLanguageContextKey requestContextKey = "accept_language"
+)
+
+// RequestMetadataMiddleware copies request details the resolvers need into the context
+func RequestMetadataMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		if key := strings.TrimSpace(r.Header.Get(IdempotencyKeyHeader)); key != "" {
+			ctx = context.WithValue(ctx, idempotencyKeyContextKey, key)
+		}
+		if ip := clientIP(r); ip != "" {
+			ctx = context.WithValue(ctx, clientIPContextKey, ip)
+		}
+		if language := r.Header.Get("Accept-Language"); language != "" {
+			ctx = context.WithValue(ctx, acceptLanguageContextKey, language)
+		}
+
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// GetIdempotencyKey retrieves the Idempotency-Key header value from context
+func GetIdempotencyKey(ctx context.Context) (string, bool) {
+	key, ok := ctx.Value(idempotencyKeyContextKey).(string)
+	return key, ok
+}
+
+// GetClientIP retrieves the client IP address from context
+func GetClientIP(ctx context.Context) (string, bool) {
+	ip, ok := ctx.Value(clientIPContextKey).(string)
+	return ip, ok
+}
+
+// GetAcceptLanguage retrieves the Accept-Language header value from context
+func GetAcceptLanguage(ctx context.Context) (string, bool) {
+	language, ok := ctx.Value(acceptLanguageContextKey).(string)
+	return language, ok
+}
+
+// clientIP returns the address of the client. The X-Forwarded-For header is only
+// trusted when TRUST_PROXY_HEADERS is set, otherwise clients could pick their own address.
+func clientIP(r *http.Request) string {
+	if trusted, _ := strconv.ParseBool(os.Getenv(keys.TrustProxyHeadersEnvKey)); trusted {
+		first, _, _ := strings.Cut(r.Header.Get("X-Forwarded-For"), ",")
+		if ip := net.ParseIP(strings.TrimSpace(first)); ip != nil {
+			return ip.String()
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if ip := net.ParseIP(host); ip != nil {
+		return ip.String()
+	}
+	return ""
+}
@@ -12,6 +12,7 @@ import (
 
 	"sct-backend-service/graph"
 	"sct-backend-service/graph/generated"
+	"sct-backend-service/internal/middleware"
 )
 
 // Server represents the GraphQL server
@@ -32,6 +33,18 @@ type Config struct {
 	PlaygroundPath  string
 	GraphQLPath     string
 	Resolvers       *graph.Resolver
+	Directives      generated.DirectiveRoot
+	// SlackInteractionsPath serves SlackInteractions, the endpoint is left out when the handler is nil
+	SlackInteractionsPath string
+	SlackInteractions     http.Handler
+	// SlackCommandsPath serves SlackCommands, the endpoint is left out when the handler is nil
+	SlackCommandsPath string
+	SlackCommands     http.Handler
+	// AttachmentsPath serves Attachments, the endpoint is left out when the handler is nil
+	AttachmentsPath string
+	Attachments     http.Handler
+	// MaxUploadSize bounds the body of multipart GraphQL requests, zero keeps the gqlgen default
+	MaxUploadSize int64
 }
 
 // ServerBuilder implements the builder pattern for server configuration
@@ -43,14 +56,17 @@ type ServerBuilder struct {
 func NewServerBuilder() *ServerBuilder {
 	return &ServerBuilder{
 		config: &Config{
-			Port:              8080,
-			Host:              "0.0.0.0",
-			ReadTimeout:       15 * time.Second,
-			WriteTimeout:      15 * time.Second,
-			IdleTimeout:       60 * time.Second,
-			PlaygroundEnabled: true,
-			PlaygroundPath:    "/",
-			GraphQLPath:       "/query",
+			Port:                  8080,
+			Host:                  "0.0.0.0",
+			ReadTimeout:           15 * time.Second,
+			WriteTimeout:          15 * time.Second,
+			IdleTimeout:           60 * time.Second,
+			PlaygroundEnabled:     true,
+			PlaygroundPath:        "/",
+			GraphQLPath:           "/query",
+			SlackInteractionsPath: "/slack/interactions",
+			SlackCommandsPath:     "/slack/commands",
+			AttachmentsPath:       "/attachments/",
 		},
 	}
 }
@@ -109,6 +125,54 @@ func (b *ServerBuilder) WithResolvers(resolvers *graph.Resolver) *ServerBuilder
 	return b
 }
 
+// WithDirectives sets the implementations of the schema directives
+func (b *ServerBuilder) WithDirectives(directives generated.DirectiveRoot) *ServerBuilder {
+	b.config.Directives = directives
+	return b
+}
+
+// WithSlackInteractions sets the handler for Slack button clicks, it must verify the requests itself
+func (b *ServerBuilder) WithSlackInteractions(handler http.Handler) *ServerBuilder {
+	b.config.SlackInteractions = handler
+	return b
+}
+
+// WithSlackInteractionsPath sets the Slack interactivity endpoint path
+func (b *ServerBuilder) WithSlackInteractionsPath(path string) *ServerBuilder {
+	b.config.SlackInteractionsPath = path
+	return b
+}
+
+// WithSlackCommands sets the handler for the Slack slash command, it must verify the requests itself
+func (b *ServerBuilder) WithSlackCommands(handler http.Handler) *ServerBuilder {
+	b.config.SlackCommands = handler
+	return b
+}
+
+// WithSlackCommandsPath sets the Slack slash command endpoint path
+func (b *ServerBuilder) WithSlackCommandsPath(path string) *ServerBuilder {
+	b.config.SlackCommandsPath = path
+	return b
+}
+
+// WithAttachments sets the handler for attachment downloads, it must verify the links itself
+func (b *ServerBuilder) WithAttachments(handler http.Handler) *ServerBuilder {
+	b.config.Attachments = handler
+	return b
+}
+
+// WithAttachmentsPath sets the path prefix of attachment downloads
+func (b *ServerBuilder) WithAttachmentsPath(path string) *ServerBuilder {
+	b.config.AttachmentsPath = path
+	return b
+}
+
+// WithMaxUploadSize sets the largest accepted multipart GraphQL request in bytes
+func (b *ServerBuilder) WithMaxUploadSize(size int64) *ServerBuilder {
+	b.config.MaxUploadSize = size
+	return b
+}
+
 // Build creates and configures the server
 func (b *ServerBuilder) Build() (*Server, error) {
 	if b.config.Resolvers == nil {
@@ -121,13 +185,13 @@ func (b *ServerBuilder) Build() (*Server, error) {
 	}
 
 	// Add any custom directives here
-	config.Directives = generated.DirectiveRoot{}
+	config.Directives = b.config.Directives
 
 	// Create the executable schema
 	executableSchema := generated.NewExecutableSchema(config)
 
 	// Create GraphQL handler
-	h := handler.NewDefaultServer(executableSchema)
+	h := NewGraphQLHandler(executableSchema, b.config.MaxUploadSize)
 
 	// Optional: customize error handling
 	// You can set a custom error presenter here if needed
@@ -136,7 +200,22 @@ func (b *ServerBuilder) Build() (*Server, error) {
 	mux := http.NewServeMux()
 
 	// Add GraphQL endpoint
-	mux.Handle(b.config.GraphQLPath, h)
+	mux.Handle(b.config.GraphQLPath, middleware.RequestMetadataMiddleware(middleware.AuthMiddleware(h)))
+
+	// Add the Slack interactivity endpoint if configured
+	if b.config.SlackInteractions != nil {
+		mux.Handle(b.config.SlackInteractionsPath, b.config.SlackInteractions)
+	}
+
+	// Add the Slack slash command endpoint if configured
+	if b.config.SlackCommands != nil {
+		mux.Handle(b.config.SlackCommandsPath, b.config.SlackCommands)
+	}
+
+	// Add the attachment download endpoint if configured
+	if b.config.Attachments != nil {
+		mux.Handle(b.config.AttachmentsPath, b.config.Attachments)
+	}
 
 	// Add playground if enabled
 	if b.config.PlaygroundEnabled {
@@ -168,6 +247,15 @@ func (s *Server) Start() error {
 	if s.config.PlaygroundEnabled {
 		log.Printf("🎮 Playground available at: http://%s%s", addr, s.config.PlaygroundPath)
 	}
+	if s.config.SlackInteractions != nil {
+		log.Printf("💬 Slack interactions: http://%s%s", addr, s.config.SlackInteractionsPath)
+	}
+	if s.config.SlackCommands != nil {
+		log.Printf("🔎 Slack commands: http://%s%s", addr, s.config.SlackCommandsPath)
+	}
+	if s.config.Attachments != nil {
+		log.Printf("📎 Attachment downloads: http://%s%s", addr, s.config.AttachmentsPath)
+	}
 	return s.httpServer.ListenAndServe()
 }
 
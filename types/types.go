@@ -7,4 +7,7 @@ import (
 
 type GraphQLService interface {
 	SendContactInfo(ctx context.Context, input model.SendContactInfoRequest) (*model.SendContactInfoResponse, error)
+	Enquiries(ctx context.Context, filter *model.EnquiryFilter, first *int, after *string) (*model.EnquiryConnection, error)
+	Enquiry(ctx context.Context, id string) (*model.Enquiry, error)
+	EnquiryStats(ctx context.Context, filter *model.EnquiryFilter) (*model.EnquiryStats, error)
 }